@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"AlgorithmsOnlineLibrary/analysis"
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// analyzer оценивает код при создании и изменении алгоритма
+var analyzer analysis.Analyzer = analysis.NewHeuristic()
+
+// analyzeCode возвращает оценку кода или nil, если анализатор не справился: без оценки
+// алгоритм всё равно сохраняется
+func analyzeCode(ctx context.Context, code, language string) *storage.Analysis {
+	result, err := analyzer.Analyze(ctx, code, language)
+	if err != nil {
+		log.Printf("Error analyzing code: %v", err)
+		return nil
+	}
+	return &result
+}
+
+// acceptAnalysis подставляет предложения анализатора в метаданные, которых нет в запросе,
+// если автор попросил об этом полем accept_analysis. Указанные явно значения важнее.
+func (change *algorithmChange) acceptAnalysis(suggested *storage.Analysis) {
+	if !change.AcceptAnalysis || suggested == nil {
+		return
+	}
+	if change.Difficulty == nil {
+		change.Difficulty = &suggested.Difficulty
+	}
+	if change.TimeComplexity == nil {
+		change.TimeComplexity = &suggested.TimeComplexity
+	}
+	if change.SpaceComplexity == nil {
+		change.SpaceComplexity = &suggested.SpaceComplexity
+	}
+}
+
+// AnalyzeCode оценивает код без сохранения, чтобы автор увидел предложение до отправки алгоритма
+func AnalyzeCode(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Code                string `json:"code"`
+		ProgrammingLanguage string `json:"programming_language"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(body.Code) == "" {
+		http.Error(w, "Code is required", http.StatusBadRequest)
+		return
+	}
+
+	result, err := analyzer.Analyze(r.Context(), body.Code, body.ProgrammingLanguage)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
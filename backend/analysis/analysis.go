@@ -0,0 +1,17 @@
+// Package analysis оценивает код алгоритма без обращения к внешним сервисам: считает метрики
+// (вложенность циклов, рекурсию, цикломатическую сложность, строки кода, структуры данных)
+// и по ним предлагает сложность и оценки времени и памяти. Автор может принять предложение
+// или указать свои значения.
+package analysis
+
+import (
+	"context"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// Analyzer оценивает код на языке language. Реализация по умолчанию — Heuristic; модель
+// поумнее подключается реализацией этого интерфейса.
+type Analyzer interface {
+	Analyze(ctx context.Context, code, language string) (storage.Analysis, error)
+}
@@ -0,0 +1,624 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"AlgorithmsOnlineLibrary/complexity"
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// HeuristicName — имя эвристического анализатора в storage.Analysis.Analyzer
+const HeuristicName = "heuristic"
+
+// Heuristic оценивает код по синтаксису, не выполняя его. Оценки приблизительные: циклы
+// считаются проходами по всему входу, а деление пополам в цикле или рекурсии — логарифмом.
+type Heuristic struct{}
+
+func NewHeuristic() *Heuristic {
+	return &Heuristic{}
+}
+
+func (h *Heuristic) Analyze(ctx context.Context, code, language string) (storage.Analysis, error) {
+	if strings.TrimSpace(code) == "" {
+		return storage.Analysis{}, fmt.Errorf("nothing to analyze")
+	}
+	p := profileFor(language)
+	src := p.strip(code)
+
+	analysis := storage.Analysis{
+		Analyzer:       HeuristicName,
+		Lines:          countLines(src),
+		LoopDepth:      p.loopDepth(src),
+		Cyclomatic:     p.cyclomatic(src),
+		DataStructures: dataStructures(src),
+		AnalyzedAt:     time.Now().UTC(),
+	}
+	rec := p.recursion(src)
+	analysis.Recursive = rec.calls > 0
+
+	m := metrics{
+		loopDepth: analysis.LoopDepth,
+		rec:       rec,
+		halving:   halvingPattern.MatchString(src),
+		memoized:  memoPattern.MatchString(src),
+		sorts:     sortPattern.MatchString(src),
+		traversal: contains(analysis.DataStructures, "graph") && analysis.LoopDepth <= 2 &&
+			(rec.calls > 0 || contains(analysis.DataStructures, "queue") || contains(analysis.DataStructures, "stack")),
+	}
+	analysis.TimeComplexity = m.time()
+	analysis.SpaceComplexity = m.space(src)
+	analysis.Difficulty = difficulty(analysis, rec.calls)
+	return analysis, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// Деление пополам: бинарный поиск, «разделяй и властвуй», удвоение шага
+	halvingPattern = regexp.MustCompile(`/\s*2\b|>>=?\s*1\b|\*=\s*2\b|\bdiv\s+2\b|\(\s*/\s+\S+\s+2\s*\)|\b(?:mid|middle|half|pivot|partition)\b`)
+	memoPattern    = regexp.MustCompile(`\b(?:memo\w*|cache|lru_cache|dp)\b`)
+	sortPattern    = regexp.MustCompile(`(?i)\bsort(?:ed|_by|by)?\b\s*[.(!{]|\bqsort\s*\(`)
+	// Выделение памяти, растущей со входом
+	allocationPattern = regexp.MustCompile(`\bmake\s*\(|\bnew\s+\w|\bappend\s*\(|\.append\s*\(|\.push(?:_back)?\s*\(|\.add\s*\(|\bvec!|::new\s*\(|\b(?:list|dict|set)\s*\(|=\s*\[\s*\]|=\s*\{\s*\}|\[\s*0\s*\]\s*\*|\barray\s*\(`)
+	matrixPattern     = regexp.MustCompile(`\[\]\s*\[\]|\]\s*\[\s*\]|vector\s*<\s*vector|\[\s*\[|\w\[[^\]\n]+\]\[|\b(?:matrix|grid)\b`)
+	// Выделение таблицы n×m: make([][]int, n), new int[n][m], vector<vector<int>> t(n, ...), [[0] * m for ...]
+	tablePattern = regexp.MustCompile(`make\s*\(\s*\[\]\s*\[\]|new\s+\w+\s*\[[^\]]+\]\s*\[|vector\s*<\s*vector[^;]*\(|\[\s*\[[^\]]*\]\s*\*|\[\s*\[.*\bfor\b`)
+	// Рекурсивные вызовы с уменьшением на константу (fib(n-1) + fib(n-2)) и вызовы для потомков узла
+	decrementPattern = regexp.MustCompile(`[-+]\s*\d\b`)
+	childPattern     = regexp.MustCompile(`(?i)(?:\.|->)(?:left|right|children|child|next)\b`)
+)
+
+// Структуры данных и признаки их использования в коде
+var structurePatterns = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{"array", regexp.MustCompile(`\[\s*\]|\w\[\w|\bvector\s*<|ArrayList|\bArray\b|\bVec\s*<|\bvec!|\blist\b`)},
+	{"hash map", regexp.MustCompile(`\bmap\s*\[|\bmap\s*<|unordered_map|HashMap|TreeMap|\bdict\b|Dictionary\s*<|\bMap\s*<|new\s+Map\b|%\{|Hash\.new|defaultdict|Counter\s*\(|=\s*\{\s*\}`)},
+	{"set", regexp.MustCompile(`\bset\s*[(<]|unordered_set|HashSet|TreeSet|\bSet\s*<|new\s+Set\b|MapSet|map\[\w+\](?:bool|struct\{\})`)},
+	{"stack", regexp.MustCompile(`(?i)\bstack\b|\bstk\b`)},
+	{"queue", regexp.MustCompile(`(?i)\bqueue\b|\bdeque\b|ArrayDeque|VecDeque|popleft|list\.New\s*\(`)},
+	{"heap", regexp.MustCompile(`(?i)\bheap\w*|PriorityQueue|priority_queue|BinaryHeap`)},
+	{"linked list", regexp.MustCompile(`(?i)(?:\.|->)next\b|LinkedList|ListNode`)},
+	{"tree", regexp.MustCompile(`(?i)(?:\.|->)left\b|TreeNode|BTreeMap|\btree\b`)},
+	{"graph", regexp.MustCompile(`(?i)\badj\w*|\bgraph\b|\bedges?\b|\bneighbou?rs?\b|\bvertices\b`)},
+	{"matrix", matrixPattern},
+}
+
+func dataStructures(src string) []string {
+	found := []string{}
+	for _, s := range structurePatterns {
+		if s.pattern.MatchString(src) {
+			found = append(found, s.name)
+		}
+	}
+	sort.Strings(found)
+	return found
+}
+
+func countLines(src string) int {
+	lines := 0
+	for _, line := range strings.Split(src, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines++
+		}
+	}
+	return lines
+}
+
+// cyclomatic — цикломатическая сложность по Маккейбу: единица плюс число точек ветвления
+func (p profile) cyclomatic(src string) int {
+	count := 1
+	for _, branch := range p.branches {
+		var pattern *regexp.Regexp
+		switch {
+		case branch == "|":
+			// Охранные выражения Haskell начинаются строкой с «|»
+			pattern = regexp.MustCompile(`(?m)^\s*\|[^|]`)
+		case regexp.MustCompile(`^\w+$`).MatchString(branch):
+			pattern = regexp.MustCompile(`\b` + branch + `\b`)
+		default:
+			pattern = regexp.MustCompile(regexp.QuoteMeta(branch))
+		}
+		count += len(pattern.FindAllStringIndex(src, -1))
+	}
+	return count
+}
+
+var wordPattern = regexp.MustCompile(`[A-Za-z_][\w']*\*?|[{}();\n]`)
+
+// loopDepth — наибольшая вложенность циклов
+func (p profile) loopDepth(src string) int {
+	switch p.style {
+	case indentation:
+		return p.indentedLoopDepth(src)
+	case keywords:
+		return p.keywordLoopDepth(src)
+	case parens:
+		return p.lispLoopDepth(src)
+	case equations:
+		return p.haskellLoopDepth(src)
+	}
+
+	// Ключевое слово цикла ждёт своей «{». Если после заголовка в скобках идёт не блок, а одна
+	// инструкция, цикл длится до её «;» или до конца объемлющего блока.
+	var stack []bool
+	var inline []int
+	pending, header, parenDepth, depth, max := false, false, 0, 0, 0
+	enter := func() {
+		depth++
+		if depth > max {
+			max = depth
+		}
+	}
+	closeInline := func() {
+		for len(inline) > 0 && inline[len(inline)-1] == len(stack) {
+			inline = inline[:len(inline)-1]
+			depth--
+		}
+	}
+	for _, token := range wordPattern.FindAllString(src, -1) {
+		if header && parenDepth == 0 && token != "{" && token != "\n" {
+			header, pending = false, false
+			if token != ";" {
+				inline = append(inline, len(stack))
+				enter()
+			}
+		}
+		switch token {
+		case "(":
+			parenDepth++
+		case ")":
+			parenDepth--
+			if pending && parenDepth == 0 && !p.bareLoopHeaders {
+				header = true
+			}
+		case ";":
+			if parenDepth <= 0 {
+				closeInline()
+				if !p.bareLoopHeaders {
+					pending = false
+				}
+			}
+		case "{":
+			stack = append(stack, pending)
+			if pending {
+				enter()
+			}
+			pending, header = false, false
+		case "}":
+			closeInline()
+			if n := len(stack); n > 0 {
+				if stack[n-1] {
+					depth--
+				}
+				stack = stack[:n-1]
+			}
+			closeInline()
+		case "\n":
+		default:
+			if p.isLoop(token) {
+				pending = true
+			}
+		}
+	}
+	return max
+}
+
+var (
+	forPattern        = regexp.MustCompile(`\bfor\b`)
+	pythonLoopPattern = regexp.MustCompile(`^(?:async\s+)?(?:for|while)\b.*:$`)
+)
+
+func (p profile) indentedLoopDepth(src string) int {
+	var stack []int
+	max := 0
+	for _, line := range strings.Split(src, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		indent := indentOf(line)
+		for len(stack) > 0 && stack[len(stack)-1] >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		// Генераторы списков вроде [x for row in m for x in row] — циклы внутри строки
+		inline := len(forPattern.FindAllString(trimmed, -1))
+		if pythonLoopPattern.MatchString(trimmed) {
+			stack = append(stack, indent)
+			if strings.HasPrefix(trimmed, "for") || strings.HasPrefix(trimmed, "async") {
+				inline--
+			}
+			inline = int(math.Max(0, float64(inline)))
+		}
+		if depth := len(stack) + inline; depth > max {
+			max = depth
+		}
+	}
+	return max
+}
+
+var (
+	endPattern      = regexp.MustCompile(`^end\b|^until\b`)
+	openerPattern   = regexp.MustCompile(`^(?:def|defp|defmodule|class|module|if|unless|case|cond|begin|while|until|for|function|local\s+function|repeat)\b|\bdo\b(?:\s*\|[^|]*\|)?$|\bfn\b.*->$|\bfunction\b.*\)$`)
+	oneLinerPattern = regexp.MustCompile(`\bend\b[)\]}.,]*$|\bdo:`)
+)
+
+func (p profile) keywordLoopDepth(src string) int {
+	var stack []bool
+	depth, max := 0, 0
+	for _, line := range strings.Split(src, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if endPattern.MatchString(trimmed) {
+			if n := len(stack); n > 0 {
+				if stack[n-1] {
+					depth--
+				}
+				stack = stack[:n-1]
+			}
+			continue
+		}
+		if !openerPattern.MatchString(trimmed) {
+			continue
+		}
+		loop := false
+		for _, word := range regexp.MustCompile(`\w+`).FindAllString(trimmed, -1) {
+			if p.isLoop(word) {
+				loop = true
+				break
+			}
+		}
+		if loop && depth+1 > max {
+			max = depth + 1
+		}
+		if oneLinerPattern.MatchString(trimmed) {
+			continue
+		}
+		stack = append(stack, loop)
+		if loop {
+			depth++
+		}
+	}
+	return max
+}
+
+func (p profile) lispLoopDepth(src string) int {
+	var stack []bool
+	depth, max := 0, 0
+	for i := 0; i < len(src); i++ {
+		switch src[i] {
+		case '(':
+			word := strings.TrimLeft(src[i+1:], " \t\n")
+			if end := strings.IndexAny(word, " \t\n()"); end >= 0 {
+				word = word[:end]
+			}
+			loop := p.isLoop(word)
+			stack = append(stack, loop)
+			if loop {
+				depth++
+				if depth > max {
+					max = depth
+				}
+			}
+		case ')':
+			if n := len(stack); n > 0 {
+				if stack[n-1] {
+					depth--
+				}
+				stack = stack[:n-1]
+			}
+		}
+	}
+	return max
+}
+
+var generatorPattern = regexp.MustCompile(`<-`)
+
+func (p profile) haskellLoopDepth(src string) int {
+	max := 0
+	for _, line := range strings.Split(src, "\n") {
+		depth := 0
+		if strings.Contains(line, "[") && strings.Contains(line, "|") {
+			depth = len(generatorPattern.FindAllString(line, -1))
+		}
+		for _, word := range regexp.MustCompile(`[\w']+`).FindAllString(line, -1) {
+			if p.isLoop(word) && depth < 1 {
+				depth = 1
+			}
+		}
+		if depth > max {
+			max = depth
+		}
+	}
+	return max
+}
+
+func indentOf(line string) int {
+	indent := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			indent++
+		case '\t':
+			indent += 4
+		default:
+			return indent
+		}
+	}
+	return indent
+}
+
+var notFunctionNames = map[string]bool{"if": true, "for": true, "while": true, "switch": true, "return": true, "catch": true, "else": true, "new": true, "sizeof": true}
+
+// recursion — самая рекурсивная функция кода: число вызовов самой себя, её тело и текст
+// после каждого вызова до конца строки, где стоят аргументы
+type recursion struct {
+	calls int
+	body  string
+	args  string
+	// Вложенность циклов в теле
+	depth int
+}
+
+func (p profile) recursion(src string) recursion {
+	var best recursion
+	for _, pattern := range p.functions {
+		for _, match := range pattern.FindAllStringSubmatchIndex(src, -1) {
+			name := src[match[2]:match[3]]
+			if notFunctionNames[name] {
+				continue
+			}
+			body := p.body(src, name, match[0], match[1])
+			calls := p.callPattern(name).FindAllStringIndex(body, -1)
+			if len(calls) <= best.calls {
+				continue
+			}
+			var args []string
+			for _, call := range calls {
+				rest := body[call[1]:]
+				if end := strings.IndexByte(rest, '\n'); end >= 0 {
+					rest = rest[:end]
+				}
+				args = append(args, rest)
+			}
+			best = recursion{calls: len(calls), body: body, args: strings.Join(args, "\n"), depth: p.loopDepth(body)}
+		}
+	}
+	return best
+}
+
+func (p profile) callPattern(name string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(name)
+	switch p.style {
+	case parens:
+		return regexp.MustCompile(`\(\s*` + quoted + `[\s)]`)
+	case keywords, equations:
+		return regexp.MustCompile(`(?:^|[^\w'.:])` + quoted + `(?:[^\w']|$)`)
+	}
+	return regexp.MustCompile(`\b` + quoted + `\s*\(`)
+}
+
+// body возвращает тело функции, объявленной в src[start:end], без самого объявления
+func (p profile) body(src, name string, start, end int) string {
+	switch p.style {
+	case braces:
+		open := strings.IndexByte(src[start:], '{')
+		if open < 0 {
+			return ""
+		}
+		return matching(src, start+open, '{', '}')
+	case parens:
+		return matching(src, start, '(', ')')[len(src[start:end]):]
+	case equations:
+		return haskellBody(src, name)
+	}
+
+	// Python, Ruby, Lua, Elixir: строки с отступом больше, чем у объявления
+	lineStart := strings.LastIndexByte(src[:start], '\n') + 1
+	indent := indentOf(src[lineStart:])
+	lineEnd := strings.IndexByte(src[end:], '\n')
+	if lineEnd < 0 {
+		return ""
+	}
+	var body []string
+	for _, line := range strings.Split(src[end+lineEnd+1:], "\n") {
+		if strings.TrimSpace(line) != "" && indentOf(line) <= indent {
+			break
+		}
+		body = append(body, line)
+	}
+	return strings.Join(body, "\n")
+}
+
+// matching возвращает текст между скобкой src[open] и парной ей
+func matching(src string, open int, opening, closing byte) string {
+	depth := 0
+	for i := open; i < len(src); i++ {
+		switch src[i] {
+		case opening:
+			depth++
+		case closing:
+			depth--
+			if depth == 0 {
+				return src[open+1 : i]
+			}
+		}
+	}
+	return src[open+1:]
+}
+
+// haskellBody собирает правые части уравнений функции name вместе со строками продолжения
+func haskellBody(src, name string) string {
+	var body []string
+	inside := false
+	for _, line := range strings.Split(src, "\n") {
+		switch {
+		case strings.HasPrefix(line, name+" ") && !strings.HasPrefix(strings.TrimPrefix(line, name+" "), "::"):
+			inside = true
+			if i := strings.IndexAny(line, "=|"); i >= 0 {
+				body = append(body, line[i:])
+			}
+		case inside && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")):
+			body = append(body, line)
+		case strings.TrimSpace(line) != "":
+			inside = false
+		}
+	}
+	return strings.Join(body, "\n")
+}
+
+// metrics — признаки, по которым угадываются оценки сложности
+type metrics struct {
+	loopDepth int
+	rec       recursion
+	halving   bool
+	memoized  bool
+	sorts     bool
+	// Обход графа очередью, стеком или рекурсией
+	traversal bool
+}
+
+// time предлагает оценку времени — наибольшую из оценок циклов, рекурсии и сортировки. Циклы
+// дают n в степени вложенности, деление пополам — логарифм; рекурсия оценивается по числу
+// ветвей и тому, как уменьшается вход.
+func (m metrics) time() string {
+	poly, logs := m.loopDepth, 0
+	if m.halving && poly > 0 && m.rec.calls == 0 {
+		// Цикл, делящий диапазон пополам, проходит log n шагов
+		poly, logs = poly-1, 1
+	}
+	candidates := []string{format(poly, logs)}
+	if m.traversal {
+		candidates = []string{"O(V + E)"}
+	}
+	if m.sorts {
+		candidates = append(candidates, "O(n log n)")
+	}
+	if m.rec.calls > 0 && !m.traversal {
+		candidates = append(candidates, m.recursionTime())
+	}
+
+	best, bestRank := "O(1)", -1.0
+	for _, candidate := range candidates {
+		normalized, rank, err := complexity.Parse(candidate)
+		if err == nil && rank > bestRank {
+			best, bestRank = normalized, rank
+		}
+	}
+	return best
+}
+
+func (m metrics) recursionTime() string {
+	depth := m.rec.depth
+	switch {
+	case m.rec.calls >= 2 && m.memoized:
+		// С запоминанием каждое состояние считается один раз
+		return format(maxInt(depth, 1), 0)
+	case m.rec.calls >= 2 && childPattern.MatchString(m.rec.args):
+		// Обход дерева: каждый узел посещается один раз
+		return format(depth+1, 0)
+	case m.rec.calls >= 2 && decrementPattern.MatchString(m.rec.args):
+		return "O(2^n)"
+	case m.rec.calls >= 2:
+		// «Разделяй и властвуй» с линейным слиянием: T(n) = 2T(n/2) + n
+		if depth <= 1 {
+			return "O(n log n)"
+		}
+		return format(depth, 0)
+	case halvingPattern.MatchString(m.rec.body):
+		if depth == 0 {
+			return "O(log n)"
+		}
+		return format(depth, 0)
+	}
+	return format(depth+1, 0)
+}
+
+// space предлагает оценку памяти: таблица — n^2, растущие структуры и глубокая рекурсия — n,
+// рекурсия с делением входа — log n
+func (m metrics) space(src string) string {
+	switch {
+	case tablePattern.MatchString(src):
+		return "O(n^2)"
+	case m.traversal:
+		return "O(V)"
+	case allocationPattern.MatchString(src):
+		return "O(n)"
+	case m.rec.calls == 1 && halvingPattern.MatchString(m.rec.body),
+		m.rec.calls >= 2 && !decrementPattern.MatchString(m.rec.args) && !childPattern.MatchString(m.rec.args):
+		return "O(log n)"
+	case m.rec.calls > 0:
+		return "O(n)"
+	}
+	return "O(1)"
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func format(poly, logs int) string {
+	var factors []string
+	switch {
+	case poly == 1:
+		factors = append(factors, "n")
+	case poly > 1:
+		factors = append(factors, fmt.Sprintf("n^%d", poly))
+	}
+	switch {
+	case logs == 1:
+		factors = append(factors, "log n")
+	case logs > 1:
+		factors = append(factors, fmt.Sprintf("log^%d n", logs))
+	}
+	if len(factors) == 0 {
+		return "O(1)"
+	}
+	return "O(" + strings.Join(factors, " ") + ")"
+}
+
+// difficulty сводит метрики в сложность от 1 до 10: объём кода, ветвления, вложенность
+// циклов, рекурсия и число структур данных добавляют баллы
+func difficulty(a storage.Analysis, selfCalls int) storage.Difficulty {
+	score := 1.0
+	score += math.Min(float64(a.Lines), 150) / 50
+	score += math.Min(float64(a.Cyclomatic-1), 15) / 5
+	if a.LoopDepth >= 2 {
+		score++
+	}
+	if a.LoopDepth >= 3 {
+		score++
+	}
+	if selfCalls == 1 {
+		score++
+	}
+	if selfCalls >= 2 {
+		score += 1.5
+	}
+	score += math.Min(float64(len(a.DataStructures)), 4) / 2
+
+	d := int(math.Round(score))
+	if d < storage.MinDifficulty {
+		d = storage.MinDifficulty
+	}
+	if d > storage.MaxDifficulty {
+		d = storage.MaxDifficulty
+	}
+	return storage.Difficulty(d)
+}
@@ -0,0 +1,215 @@
+package analysis
+
+import (
+	"regexp"
+	"strings"
+)
+
+// blockStyle — как в языке размечаются блоки, от этого зависит подсчёт вложенности и поиск тела функции
+type blockStyle int
+
+const (
+	// Фигурные скобки: Go, C, Java и т. п.
+	braces blockStyle = iota
+	// Отступы: Python
+	indentation
+	// Ключевые слова с закрывающим end: Ruby, Lua, Elixir
+	keywords
+	// S-выражения: Lisp
+	parens
+	// Уравнения с отступами: Haskell
+	equations
+)
+
+// profile описывает синтаксис языка в той мере, в какой он нужен эвристикам
+type profile struct {
+	style         blockStyle
+	lineComments  []string
+	blockComments [][2]string
+	// Символы, открывающие и закрывающие строковые литералы
+	quotes string
+	// Ключевые слова циклов и точек ветвления для цикломатической сложности
+	loops    []string
+	branches []string
+	// Выражения, первая группа которых — имя объявленной функции
+	functions []*regexp.Regexp
+	// Циклы без скобок вокруг заголовка: «;» в заголовке не завершает его (for i := 0; i < n; i++ {)
+	bareLoopHeaders bool
+}
+
+var (
+	keywordFunction = regexp.MustCompile(`\b(?:func|fn|fun|def|defp|function|sub)\s+(?:\([^)]*\)\s*)?(?:[\w.:]+[.:])?(\w+)\s*[(<\[]?`)
+	typedFunction   = regexp.MustCompile(`(?m)^[ \t]*(?:[\w:<>\[\]*&,]+[ \t]+)+[*&]*(\w+)[ \t]*\([^;{}()]*(?:\([^;{}()]*\)[^;{}()]*)*\)[ \t]*(?:const[ \t]*)?(?:throws[ \t]+[\w, .]+)?\s*\{`)
+	arrowFunction   = regexp.MustCompile(`\b(?:const|let|var)\s+(\w+)\s*=\s*(?:async\s*)?(?:function\b|\([^)]*\)\s*=>|\w+\s*=>)`)
+	rFunction       = regexp.MustCompile(`(\w+)\s*(?:<-|=)\s*function\b`)
+	lispFunction    = regexp.MustCompile(`\(defun\s+([^\s()]+)`)
+	haskellFunction = regexp.MustCompile(`(?m)^([a-z_][\w']*)\s*::`)
+)
+
+var cLike = profile{
+	style:         braces,
+	lineComments:  []string{"//"},
+	blockComments: [][2]string{{"/*", "*/"}},
+	quotes:        "\"'`",
+	loops:         []string{"for", "while", "do", "loop", "repeat", "foreach", "forEach", "for_each"},
+	branches:      []string{"if", "for", "while", "case", "catch", "&&", "||"},
+	functions:     []*regexp.Regexp{keywordFunction, typedFunction, arrowFunction},
+}
+
+var profiles = map[string]profile{
+	"go": with(cLike, func(p *profile) { p.bareLoopHeaders = true }),
+	// В Rust и Swift ' — это время жизни и символ, а не строка; в Rust одиночные символы в коде алгоритмов редки
+	"rust":        with(cLike, func(p *profile) { p.quotes = "\"" }),
+	"c":           cLike,
+	"c++":         cLike,
+	"c#":          cLike,
+	"java":        cLike,
+	"javascript":  cLike,
+	"typescript":  cLike,
+	"kotlin":      cLike,
+	"swift":       cLike,
+	"scala":       cLike,
+	"dart":        cLike,
+	"objective-c": cLike,
+	"php":         with(cLike, func(p *profile) { p.lineComments = []string{"//", "#"} }),
+	"r": with(cLike, func(p *profile) {
+		p.lineComments, p.blockComments = []string{"#"}, nil
+		p.functions = []*regexp.Regexp{rFunction}
+	}),
+	"python": {
+		style:        indentation,
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+		loops:        []string{"for", "while"},
+		branches:     []string{"if", "elif", "for", "while", "except", "and", "or"},
+		functions:    []*regexp.Regexp{keywordFunction},
+	},
+	"ruby": {
+		style:         keywords,
+		lineComments:  []string{"#"},
+		blockComments: [][2]string{{"=begin", "=end"}},
+		quotes:        "\"'",
+		loops:         []string{"while", "until", "for", "loop", "each", "times", "upto", "downto", "step", "each_with_index"},
+		branches:      []string{"if", "elsif", "unless", "while", "until", "for", "when", "rescue", "&&", "||", "and", "or"},
+		functions:     []*regexp.Regexp{keywordFunction},
+	},
+	"lua": {
+		style:         keywords,
+		lineComments:  []string{"--"},
+		blockComments: [][2]string{{"--[[", "]]"}},
+		quotes:        "\"'",
+		loops:         []string{"while", "for", "repeat"},
+		branches:      []string{"if", "elseif", "while", "for", "until", "and", "or"},
+		functions:     []*regexp.Regexp{keywordFunction},
+	},
+	"elixir": {
+		style:        keywords,
+		lineComments: []string{"#"},
+		quotes:       "\"",
+		loops:        []string{"for", "each", "map", "reduce", "flat_map", "filter"},
+		branches:     []string{"if", "unless", "case", "cond", "when", "rescue", "and", "or", "&&", "||"},
+		functions:    []*regexp.Regexp{keywordFunction},
+	},
+	"lisp": {
+		style:         parens,
+		lineComments:  []string{";"},
+		blockComments: [][2]string{{"#|", "|#"}},
+		quotes:        "\"",
+		loops:         []string{"loop", "dotimes", "dolist", "do", "do*", "mapcar", "mapc", "maphash"},
+		branches:      []string{"if", "when", "unless", "cond", "case", "and", "or"},
+		functions:     []*regexp.Regexp{lispFunction},
+	},
+	"haskell": {
+		style:         equations,
+		lineComments:  []string{"--"},
+		blockComments: [][2]string{{"{-", "-}"}},
+		quotes:        "\"",
+		loops:         []string{"map", "foldl", "foldl'", "foldr", "mapM_", "forM_", "mapM", "forM", "zipWith", "iterate", "replicateM"},
+		branches:      []string{"if", "case", "|", "&&", "||"},
+		functions:     []*regexp.Regexp{haskellFunction},
+	},
+}
+
+func with(base profile, change func(*profile)) profile {
+	change(&base)
+	return base
+}
+
+// profileFor подбирает профиль по языку; для незнакомых языков подходит C-подобный синтаксис
+func profileFor(language string) profile {
+	if p, ok := profiles[strings.ToLower(strings.TrimSpace(language))]; ok {
+		return p
+	}
+	return cLike
+}
+
+// strip убирает комментарии и содержимое строковых литералов, сохраняя переводы строк,
+// чтобы ключевые слова в тексте не принимались за код
+func (p profile) strip(code string) string {
+	var b strings.Builder
+	for i := 0; i < len(code); {
+		if closer, ok := p.blockComment(code[i:]); ok {
+			end := strings.Index(code[i+len(closer[0]):], closer[1])
+			if end < 0 {
+				end = len(code) - i - len(closer[0])
+			}
+			comment := code[i : i+len(closer[0])+end]
+			b.WriteString(strings.Repeat("\n", strings.Count(comment, "\n")))
+			i += len(comment) + len(closer[1])
+			continue
+		}
+		if p.lineComment(code[i:]) {
+			for i < len(code) && code[i] != '\n' {
+				i++
+			}
+			continue
+		}
+		if c := code[i]; strings.IndexByte(p.quotes, c) >= 0 {
+			b.WriteByte(c)
+			i++
+			for i < len(code) && code[i] != c {
+				if code[i] == '\\' {
+					i++
+				} else if code[i] == '\n' {
+					b.WriteByte('\n')
+				}
+				i++
+			}
+			if i < len(code) {
+				b.WriteByte(c)
+				i++
+			}
+			continue
+		}
+		b.WriteByte(code[i])
+		i++
+	}
+	return b.String()
+}
+
+func (p profile) blockComment(s string) ([2]string, bool) {
+	for _, pair := range p.blockComments {
+		if strings.HasPrefix(s, pair[0]) {
+			return pair, true
+		}
+	}
+	return [2]string{}, false
+}
+
+func (p profile) lineComment(s string) bool {
+	for _, prefix := range p.lineComments {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p profile) isLoop(word string) bool {
+	for _, loop := range p.loops {
+		if word == loop {
+			return true
+		}
+	}
+	return false
+}
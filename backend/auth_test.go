@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// signTest подписывает claims пользователя произвольным ключом и kid, минуя связку
+func signTest(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims *Claims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestAuthenticateRejectsBadTokens(t *testing.T) {
+	setupTest(t)
+	user := newTestUser(t, "alice", "password")
+	valid := newTestToken(t, user)
+
+	// Разбираем настоящий токен, чтобы подделки отличались от него только подписью или сроком
+	claims := &Claims{}
+	if _, err := parseToken(valid, claims); err != nil {
+		t.Fatal(err)
+	}
+	expired := *claims
+	expired.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+	secret := keys.keys["default"].private
+
+	tests := []struct {
+		name     string
+		header   string
+		wantCode int
+		wantBody string
+	}{
+		{"valid", "Bearer " + valid, http.StatusOK, "ok"},
+		{"no header", "", http.StatusUnauthorized, "Unauthorized"},
+		{"no bearer prefix", valid, http.StatusUnauthorized, "Unauthorized"},
+		{"malformed", "Bearer not.a.token", http.StatusUnauthorized, "Invalid token"},
+		{"wrong key", "Bearer " + signTest(t, jwt.SigningMethodHS256, "default", []byte(strings.Repeat("x", 32)), claims), http.StatusUnauthorized, "Invalid token"},
+		{"unknown kid", "Bearer " + signTest(t, jwt.SigningMethodHS256, "retired", secret, claims), http.StatusUnauthorized, "Invalid token"},
+		{"no kid", "Bearer " + signTest(t, jwt.SigningMethodHS256, "", secret, claims), http.StatusUnauthorized, "Invalid token"},
+		{"other algorithm", "Bearer " + signTest(t, jwt.SigningMethodHS512, "default", secret, claims), http.StatusUnauthorized, "Invalid token"},
+		{"expired", "Bearer " + signTest(t, jwt.SigningMethodHS256, "default", secret, &expired), http.StatusUnauthorized, "Token expired"},
+		{"expired with wrong key", "Bearer " + signTest(t, jwt.SigningMethodHS256, "default", []byte(strings.Repeat("x", 32)), &expired), http.StatusUnauthorized, "Invalid token"},
+	}
+
+	handler := Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		body := strings.TrimSpace(rec.Body.String())
+		if rec.Code != tt.wantCode || body != tt.wantBody {
+			t.Errorf("%s: got %d %q, want %d %q", tt.name, rec.Code, body, tt.wantCode, tt.wantBody)
+		}
+	}
+}
+
+func TestAuthenticateRejectsRevokedSession(t *testing.T) {
+	setupTest(t)
+	user := newTestUser(t, "alice", "password")
+	token := newTestToken(t, user)
+
+	claims := &Claims{}
+	if _, err := parseToken(token, claims); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RevokeSession(context.Background(), claims.SessionID, user.ID, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("revoked session: got %d, want 401", rec.Code)
+	}
+}
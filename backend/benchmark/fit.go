@@ -0,0 +1,171 @@
+package benchmark
+
+import (
+	"math"
+	"sort"
+)
+
+// Fit — эмпирическая оценка роста по замерам
+type Fit struct {
+	// Complexity — лучшая из моделей вида a + b·f(n) в записи пакета complexity, например O(n log n)
+	Complexity string `json:"complexity"`
+	// Exponent — показатель k лучшей модели вида a + b·n^k: около 1 для O(n), около 2 для O(n^2)
+	Exponent float64 `json:"exponent"`
+}
+
+type model struct {
+	complexity string
+	f          func(n float64) float64
+}
+
+// Модели в порядке роста; при равной точности побеждает более медленная
+var models = []model{
+	{"O(log n)", math.Log2},
+	{"O(sqrt(n))", math.Sqrt},
+	{"O(n)", func(n float64) float64 { return n }},
+	{"O(n log n)", func(n float64) float64 { return n * math.Log2(n) }},
+	{"O(n^2)", func(n float64) float64 { return n * n }},
+	{"O(n^3)", func(n float64) float64 { return n * n * n }},
+	{"O(2^n)", math.Exp2},
+}
+
+// MinPoints — сколько размеров нужно, чтобы оценка имела смысл
+const MinPoints = 3
+
+// Разброс значений меньше этой доли от среднего не отличить от шума и округления: это O(1).
+// Память, например, почти не меняется, пока программа не выделяет больше, чем занимает сам запуск.
+const minGrowth = 0.05
+
+// Насколько должен улучшиться критерий, чтобы модель с более быстрым ростом вытеснила медленную.
+// Разница больше 2 считается заметным свидетельством в её пользу.
+const evidence = 2
+
+// FitGrowth подбирает модель по значениям values на размерах sizes. Постоянная часть a
+// поглощает запуск процесса и интерпретатора. Модели сравниваются по байесовскому
+// информационному критерию, и более быстрый рост выбирается, только если он объясняет данные
+// заметно лучше: шум замеров не должен превращать O(n) в O(n log n), а O(1) — в O(log n).
+// ok ложно, если различных размеров меньше MinPoints.
+func FitGrowth(sizes []int, values []float64) (fit Fit, ok bool) {
+	distinct := map[int]bool{}
+	for _, size := range sizes {
+		distinct[size] = true
+	}
+	if len(distinct) < MinPoints || len(sizes) != len(values) {
+		return Fit{}, false
+	}
+
+	points := float64(len(values))
+	var sumSquares float64
+	for _, v := range values {
+		sumSquares += v * v
+	}
+	// Точная подгонка дала бы log(0); малая добавка сохраняет порядок моделей
+	criterion := func(rss float64, parameters int) float64 {
+		return points*math.Log(rss/points+1e-12*sumSquares/points+1e-300) + float64(parameters)*math.Log(points)
+	}
+
+	mean := average(values)
+	low, high := values[0], values[0]
+	for _, v := range values {
+		low, high = math.Min(low, v), math.Max(high, v)
+	}
+	if high-low <= minGrowth*math.Abs(mean) {
+		return Fit{Complexity: "O(1)"}, true
+	}
+
+	best, bestCriterion := "O(1)", criterion(residuals(values, nil, mean, 0), 1)
+	for _, m := range models {
+		if rss, ok := fitModel(sizes, values, m.f); ok {
+			if c := criterion(rss, 2); c < bestCriterion-evidence {
+				best, bestCriterion = m.complexity, c
+			}
+		}
+	}
+
+	fit = Fit{Complexity: best}
+	if best != "O(1)" {
+		fit.Exponent = exponent(sizes, values)
+	}
+	return fit, true
+}
+
+// fitModel подгоняет a + b·f(n) с b > 0 и возвращает сумму квадратов остатков
+func fitModel(sizes []int, values []float64, f func(n float64) float64) (float64, bool) {
+	xs := make([]float64, len(sizes))
+	for i, size := range sizes {
+		xs[i] = f(float64(size))
+		if math.IsInf(xs[i], 0) || math.IsNaN(xs[i]) {
+			return 0, false
+		}
+	}
+	intercept, slope, ok := linearRegression(xs, values)
+	if !ok || slope <= 0 {
+		return 0, false
+	}
+	return residuals(values, xs, intercept, slope), true
+}
+
+// exponent перебирает k с шагом 0.01 и возвращает показатель лучшей модели a + b·n^k
+func exponent(sizes []int, values []float64) float64 {
+	best, bestRSS := 0.0, math.Inf(1)
+	for i := 1; i <= 400; i++ {
+		k := float64(i) / 100
+		rss, ok := fitModel(sizes, values, func(n float64) float64 { return math.Pow(n, k) })
+		if ok && rss < bestRSS {
+			best, bestRSS = k, rss
+		}
+	}
+	return best
+}
+
+// linearRegression — метод наименьших квадратов для y = a + b·x
+func linearRegression(xs, ys []float64) (a, b float64, ok bool) {
+	if len(xs) < 2 {
+		return 0, 0, false
+	}
+	meanX, meanY := average(xs), average(ys)
+	var covariance, variance float64
+	for i := range xs {
+		covariance += (xs[i] - meanX) * (ys[i] - meanY)
+		variance += (xs[i] - meanX) * (xs[i] - meanX)
+	}
+	if variance == 0 {
+		return 0, 0, false
+	}
+	b = covariance / variance
+	return meanY - b*meanX, b, true
+}
+
+func residuals(ys, xs []float64, a, b float64) float64 {
+	var sum float64
+	for i, y := range ys {
+		predicted := a
+		if xs != nil {
+			predicted += b * xs[i]
+		}
+		sum += (y - predicted) * (y - predicted)
+	}
+	return sum
+}
+
+func average(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// Median — медиана повторов одного размера
+func Median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
@@ -0,0 +1,104 @@
+package benchmark
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// synthetic возвращает по repetitions замеров a + b·f(n) на каждом размере с относительным шумом noise
+func synthetic(sizes []int, repetitions int, f func(n float64) float64, a, b, noise float64, seed int64) ([]int, []float64) {
+	random := rand.New(rand.NewSource(seed))
+	var xs []int
+	var values []float64
+	for _, size := range sizes {
+		for i := 0; i < repetitions; i++ {
+			v := a + b*f(float64(size))
+			xs = append(xs, size)
+			values = append(values, v*(1+noise*(2*random.Float64()-1)))
+		}
+	}
+	return xs, values
+}
+
+func TestFitGrowthRecoversModel(t *testing.T) {
+	sizes := []int{1000, 2000, 4000, 8000, 16000, 32000, 64000}
+	tests := []struct {
+		name           string
+		f              func(n float64) float64
+		a, b           float64
+		want           string
+		minExp, maxExp float64
+	}{
+		{"constant", func(n float64) float64 { return 0 }, 5, 0, "O(1)", 0, 0},
+		{"linear", func(n float64) float64 { return n }, 20, 0.01, "O(n)", 0.9, 1.1},
+		{"n log n", func(n float64) float64 { return n * math.Log2(n) }, 20, 0.001, "O(n log n)", 1, 1.25},
+		{"quadratic", func(n float64) float64 { return n * n }, 20, 1e-6, "O(n^2)", 1.9, 2.1},
+		{"cubic", func(n float64) float64 { return n * n * n }, 20, 1e-11, "O(n^3)", 2.9, 3.1},
+		{"log", math.Log2, 10, 3, "O(log n)", 0, 0.5},
+	}
+	for _, tt := range tests {
+		for _, noise := range []float64{0, 0.02} {
+			xs, values := synthetic(sizes, 3, tt.f, tt.a, tt.b, noise, 1)
+			fit, ok := FitGrowth(xs, values)
+			if !ok {
+				t.Fatalf("%s: FitGrowth not ok", tt.name)
+			}
+			if fit.Complexity != tt.want {
+				t.Errorf("%s, noise %v: complexity %s, want %s", tt.name, noise, fit.Complexity, tt.want)
+			}
+			if fit.Exponent < tt.minExp || fit.Exponent > tt.maxExp {
+				t.Errorf("%s, noise %v: exponent %v, want between %v and %v", tt.name, noise, fit.Exponent, tt.minExp, tt.maxExp)
+			}
+		}
+	}
+}
+
+func TestFitGrowthLinearWithStartupCost(t *testing.T) {
+	// Запуск интерпретатора стоит больше, чем вся работа на малых размерах
+	xs, values := synthetic([]int{100, 1000, 10000, 100000, 1000000}, 5, func(n float64) float64 { return n }, 30, 1e-4, 0.01, 7)
+	fit, ok := FitGrowth(xs, values)
+	if !ok || fit.Complexity != "O(n)" {
+		t.Errorf("FitGrowth = %+v, %v, want O(n)", fit, ok)
+	}
+}
+
+func TestFitGrowthNeedsDistinctSizes(t *testing.T) {
+	tests := []struct {
+		name   string
+		sizes  []int
+		values []float64
+	}{
+		{"empty", nil, nil},
+		{"two sizes", []int{10, 10, 20}, []float64{1, 1, 2}},
+		{"length mismatch", []int{10, 20, 30}, []float64{1, 2}},
+	}
+	for _, tt := range tests {
+		if fit, ok := FitGrowth(tt.sizes, tt.values); ok {
+			t.Errorf("%s: FitGrowth = %+v, want not ok", tt.name, fit)
+		}
+	}
+}
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   float64
+	}{
+		{nil, 0},
+		{[]float64{3}, 3},
+		{[]float64{3, 1, 2}, 2},
+		{[]float64{4, 1, 3, 2}, 2.5},
+	}
+	for _, tt := range tests {
+		values := append([]float64(nil), tt.values...)
+		if got := Median(values); got != tt.want {
+			t.Errorf("Median(%v) = %v, want %v", tt.values, got, tt.want)
+		}
+		for i := range values {
+			if values[i] != tt.values[i] {
+				t.Errorf("Median(%v) reordered its argument", tt.values)
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package benchmark готовит замеры производительности алгоритмов: порождает входы заданного
+// размера и подбирает по замерам эмпирическую оценку роста, которую можно сравнить с
+// заявленной автором.
+package benchmark
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxSize — наибольший размер входа: массив такого размера занимает около 8 МБ
+	MaxSize = 1_000_000
+	// MaxTotalSize — наибольшая сумма размеров замера: все входы держатся в памяти, пока он идёт
+	MaxTotalSize = 2 * MaxSize
+)
+
+// Generator порождает вход размера n; одинаковые seed и n дают одинаковый вход, поэтому все
+// алгоритмы замера получают одни и те же данные
+type Generator func(rng *rand.Rand, n int) string
+
+// Generators — доступные генераторы входов. Формат каждого описан в README.
+var Generators = map[string]Generator{
+	"number":         number,
+	"array":          array,
+	"sorted_array":   sortedArray,
+	"reversed_array": reversedArray,
+	"string":         randomString,
+	"graph":          graph,
+}
+
+// Generate порождает вход генератора name; ok ложно, если такого генератора нет
+func Generate(name string, seed int64, n int) (input string, ok bool) {
+	generator, ok := Generators[name]
+	if !ok {
+		return "", false
+	}
+	return generator(rand.New(rand.NewSource(seed+int64(n))), n), true
+}
+
+// number — одно число n
+func number(rng *rand.Rand, n int) string {
+	return strconv.Itoa(n) + "\n"
+}
+
+// array — n на первой строке и n случайных чисел от -10^6 до 10^6 на второй
+func array(rng *rand.Rand, n int) string {
+	return formatArray(randomInts(rng, n))
+}
+
+func sortedArray(rng *rand.Rand, n int) string {
+	values := randomInts(rng, n)
+	sort.Ints(values)
+	return formatArray(values)
+}
+
+func reversedArray(rng *rand.Rand, n int) string {
+	values := randomInts(rng, n)
+	sort.Sort(sort.Reverse(sort.IntSlice(values)))
+	return formatArray(values)
+}
+
+// randomString — строка из n случайных строчных латинских букв
+func randomString(rng *rand.Rand, n int) string {
+	b := make([]byte, n, n+1)
+	for i := range b {
+		b[i] = byte('a' + rng.Intn(26))
+	}
+	return string(append(b, '\n'))
+}
+
+// graph — «n m» на первой строке и m = n случайных рёбер «u v» с вершинами от 1 до n
+func graph(rng *rand.Rand, n int) string {
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(n) + " " + strconv.Itoa(n) + "\n")
+	for i := 0; i < n; i++ {
+		b.WriteString(strconv.Itoa(rng.Intn(n)+1) + " " + strconv.Itoa(rng.Intn(n)+1) + "\n")
+	}
+	return b.String()
+}
+
+func randomInts(rng *rand.Rand, n int) []int {
+	values := make([]int, n)
+	for i := range values {
+		values[i] = rng.Intn(2_000_001) - 1_000_000
+	}
+	return values
+}
+
+func formatArray(values []int) string {
+	var b strings.Builder
+	b.Grow(len(values) * 8)
+	b.WriteString(strconv.Itoa(len(values)) + "\n")
+	for i, v := range values {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(strconv.Itoa(v))
+	}
+	b.WriteByte('\n')
+	return b.String()
+}
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"AlgorithmsOnlineLibrary/benchmark"
+	"AlgorithmsOnlineLibrary/sandbox"
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// Замеры выполняются в фоне по одному: каждый занимает одно место в пуле песочницы на всё
+// время замера алгоритма
+const (
+	benchmarkQueueSize = 20
+	benchmarkRetries   = 10
+)
+
+var benchmarkQueue = make(chan int, benchmarkQueueSize)
+
+// Размеры и число повторов по умолчанию: размер растёт вдвое, чтобы рост был заметен и на O(log n)
+var (
+	defaultBenchmarkSizes       = []int{1000, 2000, 4000, 8000, 16000, 32000, 64000}
+	defaultBenchmarkRepetitions = 3
+)
+
+// benchmarkRequest — тело POST /api/benchmarks
+type benchmarkRequest struct {
+	AlgorithmIDs []int  `json:"algorithm_ids"`
+	Generator    string `json:"generator"`
+	Sizes        []int  `json:"sizes"`
+	Repetitions  int    `json:"repetitions"`
+}
+
+func (request *benchmarkRequest) validate() error {
+	if len(request.AlgorithmIDs) == 0 || len(request.AlgorithmIDs) > storage.MaxBenchmarkAlgorithms {
+		return fmt.Errorf("algorithm_ids must list from 1 to %d algorithms", storage.MaxBenchmarkAlgorithms)
+	}
+	seen := map[int]bool{}
+	for _, id := range request.AlgorithmIDs {
+		if seen[id] {
+			return fmt.Errorf("algorithm %d is listed twice", id)
+		}
+		seen[id] = true
+	}
+
+	if _, ok := benchmark.Generators[request.Generator]; !ok {
+		names := make([]string, 0, len(benchmark.Generators))
+		for name := range benchmark.Generators {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("generator must be one of: %s", strings.Join(names, ", "))
+	}
+
+	if request.Sizes == nil {
+		request.Sizes = defaultBenchmarkSizes
+	}
+	if len(request.Sizes) < benchmark.MinPoints || len(request.Sizes) > storage.MaxBenchmarkSizes {
+		return fmt.Errorf("sizes must list from %d to %d sizes", benchmark.MinPoints, storage.MaxBenchmarkSizes)
+	}
+	total := 0
+	for i, size := range request.Sizes {
+		if size < 1 || size > benchmark.MaxSize {
+			return fmt.Errorf("sizes must be between 1 and %d", benchmark.MaxSize)
+		}
+		if i > 0 && size <= request.Sizes[i-1] {
+			return fmt.Errorf("sizes must be increasing")
+		}
+		total += size
+	}
+	if total > benchmark.MaxTotalSize {
+		return fmt.Errorf("sizes must add up to at most %d", benchmark.MaxTotalSize)
+	}
+
+	if request.Repetitions == 0 {
+		request.Repetitions = defaultBenchmarkRepetitions
+	}
+	if request.Repetitions < 1 || request.Repetitions > storage.MaxBenchmarkRepetitions {
+		return fmt.Errorf("repetitions must be between 1 and %d", storage.MaxBenchmarkRepetitions)
+	}
+	if runs := len(request.AlgorithmIDs) * len(request.Sizes) * request.Repetitions; runs > storage.MaxBenchmarkRuns {
+		return fmt.Errorf("a benchmark can have at most %d runs, this one has %d", storage.MaxBenchmarkRuns, runs)
+	}
+	return nil
+}
+
+// startBenchmarker запускает обработчик замеров и возвращает в очередь замеры, прерванные
+// перезапуском сервера. Список читается до того, как сервер начнёт принимать запросы, чтобы
+// новый замер не попал в очередь дважды.
+func startBenchmarker() {
+	if runner == nil {
+		return
+	}
+	unfinished, err := store.ListUnfinishedBenchmarks(context.Background())
+	if err != nil {
+		log.Println("Error fetching unfinished benchmarks:", err)
+	}
+	go func() {
+		for _, id := range unfinished {
+			runBenchmark(id)
+		}
+		for id := range benchmarkQueue {
+			runBenchmark(id)
+		}
+	}()
+}
+
+// runBenchmark порождает входы и замеряет алгоритмы по очереди; итог каждого алгоритма
+// сохраняется сразу, так что в ответе API видно, как продвигается замер
+func runBenchmark(id int) {
+	ctx := context.Background()
+	current, err := store.GetBenchmark(ctx, id)
+	if err != nil {
+		log.Printf("Error fetching benchmark %d: %v", id, err)
+		return
+	}
+	if err := store.StartBenchmark(ctx, id, time.Now().UTC()); err != nil {
+		log.Printf("Error starting benchmark %d: %v", id, err)
+		return
+	}
+
+	// Первый запуск на самом маленьком входе прогревает кеши и в замер не идёт,
+	// затем каждый размер повторяется подряд Repetitions раз
+	warmUp, _ := benchmark.Generate(current.Generator, current.Seed, current.Sizes[0])
+	stdins := []string{warmUp}
+	for _, size := range current.Sizes {
+		input, _ := benchmark.Generate(current.Generator, current.Seed, size)
+		for i := 0; i < current.Repetitions; i++ {
+			stdins = append(stdins, input)
+		}
+	}
+
+	status, message := storage.BenchmarkDone, ""
+	for _, algorithm := range current.Algorithms {
+		result, err := measureAlgorithm(ctx, current, algorithm, stdins)
+		if err == nil {
+			err = store.SaveBenchmarkAlgorithm(ctx, id, result)
+		}
+		if err != nil {
+			log.Printf("Error benchmarking algorithm %d in benchmark %d: %v", algorithm.AlgorithmID, id, err)
+			status, message = storage.BenchmarkFailed, "Failed to benchmark the algorithms"
+			break
+		}
+	}
+	if err := store.FinishBenchmark(ctx, id, status, message, time.Now().UTC()); err != nil {
+		log.Printf("Error finishing benchmark %d: %v", id, err)
+	}
+}
+
+// measureAlgorithm выполняет код последней ревизии на всех входах и подбирает оценки роста.
+// Ошибкой считается только сбой сервера; то, что алгоритм не удалось замерить, попадает в его Error.
+func measureAlgorithm(ctx context.Context, current storage.Benchmark, algorithm storage.BenchmarkAlgorithm, stdins []string) (storage.BenchmarkAlgorithm, error) {
+	algorithm.Status = storage.BenchmarkFailed
+	algorithm.Measurements = []storage.BenchmarkMeasurement{}
+	revision, code, language, err := latestCode(ctx, algorithm.AlgorithmID)
+	if err == storage.ErrNotFound {
+		algorithm.Error = "Algorithm not found"
+		return algorithm, nil
+	}
+	if err != nil {
+		return algorithm, err
+	}
+	algorithm.Revision = revision
+
+	var runs []sandbox.Result
+	for attempt := 1; ; attempt++ {
+		runs, err = runner.RunSeries(ctx, language, code, stdins)
+		if errors.Is(err, sandbox.ErrBusy) && attempt < benchmarkRetries {
+			time.Sleep(time.Duration(attempt) * 2 * time.Second)
+			continue
+		}
+		break
+	}
+	switch {
+	case errors.Is(err, sandbox.ErrUnsupported):
+		algorithm.Error = "Running " + language + " code is not supported"
+		return algorithm, nil
+	case errors.Is(err, sandbox.ErrBusy):
+		algorithm.Error = "The sandbox stayed busy, try again later"
+		return algorithm, nil
+	case err != nil:
+		return algorithm, err
+	case runs[0].Status == sandbox.StatusCompileError:
+		algorithm.Error = "Compilation failed"
+		return algorithm, nil
+	}
+	runs = runs[1:]
+
+	var sizes []int
+	var times, memory []float64
+	for i, size := range current.Sizes {
+		repetitions := runs[i*current.Repetitions : (i+1)*current.Repetitions]
+		measurement := storage.BenchmarkMeasurement{Size: size, Status: sandbox.StatusOK}
+		var wall, cpu, memoryKB []float64
+		for _, run := range repetitions {
+			if run.Status != sandbox.StatusOK {
+				measurement.Status = run.Status
+				break
+			}
+			wall = append(wall, float64(run.Usage.WallTime.Microseconds())/1000)
+			cpu = append(cpu, float64(run.Usage.CPUTime.Microseconds())/1000)
+			memoryKB = append(memoryKB, float64(run.Usage.MemoryKB))
+		}
+		if measurement.Status == sandbox.StatusOK {
+			measurement.WallTimeMS = benchmark.Median(wall)
+			measurement.CPUTimeMS = benchmark.Median(cpu)
+			measurement.MemoryKB = int64(benchmark.Median(memoryKB))
+			sizes = append(sizes, size)
+			times = append(times, measurement.WallTimeMS)
+			memory = append(memory, float64(measurement.MemoryKB))
+		}
+		algorithm.Measurements = append(algorithm.Measurements, measurement)
+	}
+
+	algorithm.Status = storage.BenchmarkDone
+	if fit, ok := benchmark.FitGrowth(sizes, times); ok {
+		algorithm.FittedTimeComplexity, algorithm.TimeExponent = fit.Complexity, fit.Exponent
+	}
+	if fit, ok := benchmark.FitGrowth(sizes, memory); ok {
+		algorithm.FittedSpaceComplexity, algorithm.SpaceExponent = fit.Complexity, fit.Exponent
+	}
+	return algorithm, nil
+}
+
+// compareComplexities заполняет TimeMatches и SpaceMatches алгоритмов замера
+func compareComplexities(algorithms []storage.BenchmarkAlgorithm) {
+	for i := range algorithms {
+		algorithm := &algorithms[i]
+		algorithm.TimeMatches = complexityMatches(algorithm.TimeComplexity, algorithm.FittedTimeComplexity)
+		algorithm.SpaceMatches = complexityMatches(algorithm.SpaceComplexity, algorithm.FittedSpaceComplexity)
+	}
+}
+
+// complexityMatches сравнивает оценки по рангу роста, как сортировка fastest: O(n·m) и O(n^2)
+// совпадают. nil, если одной из оценок нет.
+func complexityMatches(declared, fitted string) *bool {
+	if declared == "" || fitted == "" {
+		return nil
+	}
+	matches := storage.ComplexityRank(declared) == storage.ComplexityRank(fitted)
+	return &matches
+}
+
+// benchmarkRow — строка таблицы сравнения: результаты всех алгоритмов на одном размере в порядке
+// algorithms; null, если алгоритм на этом размере не замерялся
+type benchmarkRow struct {
+	Size    int                             `json:"size"`
+	Results []*storage.BenchmarkMeasurement `json:"results"`
+}
+
+func benchmarkTable(current storage.Benchmark) []benchmarkRow {
+	table := make([]benchmarkRow, len(current.Sizes))
+	for i, size := range current.Sizes {
+		table[i] = benchmarkRow{Size: size, Results: make([]*storage.BenchmarkMeasurement, len(current.Algorithms))}
+		for j, algorithm := range current.Algorithms {
+			for k := range algorithm.Measurements {
+				if algorithm.Measurements[k].Size == size {
+					table[i].Results[j] = &algorithm.Measurements[k]
+				}
+			}
+		}
+	}
+	return table
+}
+
+// CreateBenchmark ставит в очередь замер алгоритмов, которые видит пользователь, и сразу
+// отвечает 202: результат появляется в GET /api/benchmarks/{id}
+func CreateBenchmark(w http.ResponseWriter, r *http.Request) {
+	if runner == nil {
+		http.Error(w, "Running code is disabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	var request benchmarkRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := request.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID := r.Context().Value("userID").(int)
+	created := storage.Benchmark{
+		UserID:      userID,
+		Generator:   request.Generator,
+		Sizes:       request.Sizes,
+		Repetitions: request.Repetitions,
+		Seed:        rand.Int63n(1 << 31),
+	}
+	for _, id := range request.AlgorithmIDs {
+		visible, err := canViewAlgorithm(r.Context(), userID, roleFromContext(r), id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var algorithm storage.Algorithm
+		if visible {
+			algorithm, err = store.GetAlgorithm(r.Context(), id, false)
+		}
+		if !visible || err == storage.ErrNotFound {
+			http.Error(w, fmt.Sprintf("Algorithm %d not found", id), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !contains(runner.Languages(), algorithm.ProgrammingLanguage) {
+			http.Error(w, "Running "+algorithm.ProgrammingLanguage+" code is not supported", http.StatusUnprocessableEntity)
+			return
+		}
+		created.Algorithms = append(created.Algorithms, storage.BenchmarkAlgorithm{
+			AlgorithmID:         algorithm.ID,
+			Title:               algorithm.Title,
+			ProgrammingLanguage: algorithm.ProgrammingLanguage,
+			TimeComplexity:      algorithm.TimeComplexity,
+			SpaceComplexity:     algorithm.SpaceComplexity,
+		})
+	}
+
+	if err := store.CreateBenchmark(r.Context(), &created); err != nil {
+		http.Error(w, "Error creating benchmark", http.StatusInternalServerError)
+		return
+	}
+	select {
+	case benchmarkQueue <- created.ID:
+	default:
+		store.FinishBenchmark(r.Context(), created.ID, storage.BenchmarkFailed, "Too many benchmarks are queued", time.Now().UTC())
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, "Too many benchmarks are queued, try again later", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(created)
+}
+
+// GetBenchmarks возвращает замеры пользователя с итогами алгоритмов, без результатов по размерам
+func GetBenchmarks(w http.ResponseWriter, r *http.Request) {
+	benchmarks, err := store.ListBenchmarks(r.Context(), r.Context().Value("userID").(int))
+	if err != nil {
+		http.Error(w, "Error fetching benchmarks", http.StatusInternalServerError)
+		return
+	}
+	for i := range benchmarks {
+		compareComplexities(benchmarks[i].Algorithms)
+	}
+	json.NewEncoder(w).Encode(benchmarks)
+}
+
+// GetBenchmark возвращает замер с рядами по каждому алгоритму и таблицей сравнения по размерам.
+// Замер видят тот, кто его запустил, и модераторы.
+func GetBenchmark(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid ID parameter", http.StatusBadRequest)
+		return
+	}
+
+	current, err := store.GetBenchmark(r.Context(), id)
+	if err == storage.ErrNotFound || err == nil && current.UserID != r.Context().Value("userID").(int) &&
+		!hasPermission(roleFromContext(r), permModerateAlgorithms) {
+		http.Error(w, "Benchmark not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	compareComplexities(current.Algorithms)
+
+	json.NewEncoder(w).Encode(struct {
+		storage.Benchmark
+		Table []benchmarkRow `json:"table"`
+	}{current, benchmarkTable(current)})
+}
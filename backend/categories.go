@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// Наибольшая длина имени категории (categories.name VARCHAR(50))
+const maxCategoryName = 50
+
+// GetCategories возвращает дерево категорий
+func GetCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := store.ListCategories(r.Context())
+	if err != nil {
+		http.Error(w, "Error fetching categories", http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(storage.CategoryTree(categories))
+}
+
+// GetCategory возвращает категорию вместе с поддеревом
+func GetCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid ID parameter", http.StatusBadRequest)
+		return
+	}
+
+	categories, err := store.ListCategories(r.Context())
+	if err != nil {
+		http.Error(w, "Error fetching categories", http.StatusInternalServerError)
+		return
+	}
+	if category, ok := findCategory(storage.CategoryTree(categories), id); ok {
+		json.NewEncoder(w).Encode(category)
+		return
+	}
+	http.Error(w, "Category not found", http.StatusNotFound)
+}
+
+func findCategory(tree []storage.Category, id int) (storage.Category, bool) {
+	for _, category := range tree {
+		if category.ID == id {
+			return category, true
+		}
+		if found, ok := findCategory(category.Children, id); ok {
+			return found, true
+		}
+	}
+	return storage.Category{}, false
+}
+
+// categoryRequest — тело запросов создания и изменения категории
+type categoryRequest struct {
+	Name     string `json:"name"`
+	ParentID *int   `json:"parent_id"`
+}
+
+// validate проверяет имя и родителя. id — изменяемая категория или 0 при создании:
+// категорию нельзя перенести в её же поддерево.
+func (request *categoryRequest) validate(ctx context.Context, id int) (int, error) {
+	request.Name = strings.Join(strings.Fields(request.Name), " ")
+	if request.Name == "" {
+		return http.StatusBadRequest, fmt.Errorf("Category name is required")
+	}
+	if utf8.RuneCountInString(request.Name) > maxCategoryName {
+		return http.StatusBadRequest, fmt.Errorf("Category name must be at most %d characters", maxCategoryName)
+	}
+	if request.ParentID == nil {
+		return 0, nil
+	}
+
+	categories, err := store.ListCategories(ctx)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if _, ok := findCategory(storage.CategoryTree(categories), *request.ParentID); !ok {
+		return http.StatusBadRequest, fmt.Errorf("Parent category not found")
+	}
+	if id != 0 && storage.CategorySubtree(categories, id)[*request.ParentID] {
+		return http.StatusBadRequest, fmt.Errorf("A category cannot be moved under itself or its subcategories")
+	}
+	return 0, nil
+}
+
+// categoryError пишет ответ на ошибку хранилища при изменении категорий
+func categoryError(w http.ResponseWriter, err error) {
+	switch err {
+	case storage.ErrNotFound:
+		http.Error(w, "Category not found", http.StatusNotFound)
+	case storage.ErrDuplicate:
+		http.Error(w, "A category with this name already exists here", http.StatusConflict)
+	case storage.ErrInUse:
+		http.Error(w, "Category still has subcategories or algorithms", http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// CreateCategory добавляет категорию; без parent_id она становится корневой
+func CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var request categoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if status, err := request.validate(r.Context(), 0); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	category := storage.Category{Name: request.Name, ParentID: request.ParentID}
+	if err := store.CreateCategory(r.Context(), &category); err != nil {
+		categoryError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(category)
+}
+
+// UpdateCategory переименовывает категорию или переносит её к другому родителю
+func UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid ID parameter", http.StatusBadRequest)
+		return
+	}
+
+	var request categoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if status, err := request.validate(r.Context(), id); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	err = store.UpdateCategory(r.Context(), storage.Category{ID: id, Name: request.Name, ParentID: request.ParentID})
+	if err != nil {
+		categoryError(w, err)
+		return
+	}
+
+	category, err := store.GetCategory(r.Context(), id)
+	if err != nil {
+		categoryError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(category)
+}
+
+// DeleteCategory удаляет пустую категорию: без подкатегорий и алгоритмов
+func DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid ID parameter", http.StatusBadRequest)
+		return
+	}
+
+	if err := store.DeleteCategory(r.Context(), id); err != nil {
+		categoryError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// resolveCategory находит категорию алгоритма по category_id, а если он не передан — по topic,
+// сравнивая нормализованные имена. Так старые клиенты, присылающие только topic, продолжают работать.
+func resolveCategory(ctx context.Context, categoryID *int, topic string) (storage.Category, int, error) {
+	if categoryID != nil {
+		category, err := store.GetCategory(ctx, *categoryID)
+		if err == storage.ErrNotFound {
+			return category, http.StatusBadRequest, fmt.Errorf("Category not found")
+		}
+		if err != nil {
+			return category, http.StatusInternalServerError, err
+		}
+		return category, 0, nil
+	}
+
+	categories, err := store.ListCategories(ctx)
+	if err != nil {
+		return storage.Category{}, http.StatusInternalServerError, err
+	}
+	slug := storage.CategorySlug(topic)
+	var matches []storage.Category
+	for _, category := range categories {
+		if category.Slug == slug {
+			matches = append(matches, category)
+		}
+	}
+	// Корневая категория важнее одноимённых подкатегорий
+	for _, category := range matches {
+		if category.ParentID == nil {
+			return category, 0, nil
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return createTopicCategory(ctx, topic)
+	case 1:
+		return matches[0], 0, nil
+	}
+	return storage.Category{}, http.StatusBadRequest, fmt.Errorf("Topic %q matches several categories, pass category_id", topic)
+}
+
+// createTopicCategory заводит корневую категорию для темы, которой ещё нет: до появления дерева
+// категорий topic был произвольной строкой, и старые клиенты не должны получать на него ошибку.
+// Админ потом может перенести или переименовать такую категорию.
+func createTopicCategory(ctx context.Context, topic string) (storage.Category, int, error) {
+	request := categoryRequest{Name: topic}
+	if status, err := request.validate(ctx, 0); err != nil {
+		return storage.Category{}, status, err
+	}
+
+	category := storage.Category{Name: request.Name}
+	err := store.CreateCategory(ctx, &category)
+	if err == storage.ErrDuplicate {
+		// Ту же тему только что завёл параллельный запрос
+		return resolveCategory(ctx, nil, topic)
+	}
+	if err != nil {
+		return storage.Category{}, http.StatusInternalServerError, err
+	}
+	return category, 0, nil
+}
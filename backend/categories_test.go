@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+func TestNewStoreHasDefaultCategories(t *testing.T) {
+	for _, driver := range testDrivers {
+		setupTest(t)
+		useStore(t, driver)
+
+		rec := httptest.NewRecorder()
+		GetCategories(rec, httptest.NewRequest("GET", "/api/categories", nil))
+		var tree []storage.Category
+		if err := json.NewDecoder(rec.Body).Decode(&tree); err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+		for _, category := range tree {
+			names = append(names, category.Name)
+		}
+		if len(names) != len(storage.DefaultCategories) {
+			t.Errorf("%s: categories %v, want %v", driver, names, storage.DefaultCategories)
+		}
+		for _, name := range storage.DefaultCategories {
+			if !strings.Contains(strings.Join(names, ","), name) {
+				t.Errorf("%s: category %q missing", driver, name)
+			}
+		}
+	}
+}
+
+func TestCreateAlgorithmWithTopicOnNewStore(t *testing.T) {
+	tests := []struct {
+		topic     string
+		wantCode  int
+		wantTopic string
+		// newCategory — тема должна завести новую корневую категорию
+		newCategory bool
+	}{
+		{"Sorting", http.StatusOK, "Sorting", false},
+		{"  graph ", http.StatusOK, "Graphs", false},
+		{"Bit manipulation", http.StatusOK, "Bit manipulation", true},
+		{"bit  manipulations", http.StatusOK, "Bit manipulation", false},
+		{strings.Repeat("x", maxCategoryName+1), http.StatusBadRequest, "", false},
+	}
+
+	for _, driver := range testDrivers {
+		setupTest(t)
+		useStore(t, driver)
+		user := newTestUser(t, "alice", "password")
+
+		for _, tt := range tests {
+			before, err := store.ListCategories(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body := `{"title": "Algorithm", "code": "print(1)", "programming_language": "Python", "topic": ` + jsonString(tt.topic) + `}`
+			rec := httptest.NewRecorder()
+			CreateAlgorithm(rec, asUser(httptest.NewRequest("POST", "/algorithms", strings.NewReader(body)), user))
+			if rec.Code != tt.wantCode {
+				t.Errorf("%s, topic %q: got %d %s, want %d", driver, tt.topic, rec.Code, rec.Body, tt.wantCode)
+				continue
+			}
+
+			after, err := store.ListCategories(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if created := len(after) > len(before); created != tt.newCategory {
+				t.Errorf("%s, topic %q: new category created = %v, want %v", driver, tt.topic, created, tt.newCategory)
+			}
+			if rec.Code != http.StatusOK {
+				continue
+			}
+
+			var algorithm Algorithm
+			if err := json.NewDecoder(rec.Body).Decode(&algorithm); err != nil {
+				t.Fatal(err)
+			}
+			if algorithm.Topic != tt.wantTopic || algorithm.CategoryID == nil {
+				t.Errorf("%s, topic %q: got topic %q, category %v, want %q", driver, tt.topic, algorithm.Topic, algorithm.CategoryID, tt.wantTopic)
+			}
+		}
+	}
+}
+
+func jsonString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+
+	"AlgorithmsOnlineLibrary/markdown"
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// Наибольшая длина комментария в символах
+const maxCommentLength = 10000
+
+// commentCursorSort — значение Sort в курсоре страницы комментариев, чтобы курсор списка
+// алгоритмов не приняли за курсор комментариев и наоборот
+const commentCursorSort = "comments"
+
+// commentPage — ответ GET /api/algorithms/{id}/comments
+type commentPage struct {
+	Items      []storage.Comment `json:"items"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// renderComment заполняет body_html у комментария и его ответов. Текст удалённых не отдаётся.
+func renderComment(comment *storage.Comment) {
+	if comment.Deleted {
+		comment.Body, comment.BodyHTML = "", ""
+	} else {
+		comment.BodyHTML = markdown.Render(comment.Body)
+	}
+	for i := range comment.Replies {
+		renderComment(&comment.Replies[i])
+	}
+}
+
+func validCommentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", fmt.Errorf("Comment body is required")
+	}
+	if utf8.RuneCountInString(body) > maxCommentLength {
+		return "", fmt.Errorf("Comment must be at most %d characters", maxCommentLength)
+	}
+	return body, nil
+}
+
+// visibleAlgorithmID разбирает {id} и проверяет, что алгоритм виден пользователю. При отказе ответ уже записан в w.
+func visibleAlgorithmID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid ID parameter", http.StatusBadRequest)
+		return 0, false
+	}
+
+	visible, err := canViewAlgorithm(r.Context(), r.Context().Value("userID").(int), roleFromContext(r), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return 0, false
+	}
+	if !visible {
+		http.Error(w, "Algorithm not found", http.StatusNotFound)
+		return 0, false
+	}
+	return id, true
+}
+
+// GetComments возвращает страницу веток комментариев алгоритма. inline=true оставляет только
+// комментарии к строкам кода, revision — только написанные к этой ревизии.
+func GetComments(w http.ResponseWriter, r *http.Request) {
+	id, ok := visibleAlgorithmID(w, r)
+	if !ok {
+		return
+	}
+
+	params := r.URL.Query()
+	filter := storage.CommentFilter{AlgorithmID: id, Limit: defaultPageLimit}
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxPageLimit), http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+	if value := params.Get("cursor"); value != "" {
+		cursor, err := storage.DecodeCursor(value)
+		if err != nil || cursor.Sort != commentCursorSort {
+			http.Error(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
+		filter.AfterID = cursor.ID
+	}
+	if value := params.Get("revision"); value != "" {
+		revision, err := strconv.Atoi(value)
+		if err != nil || revision < 1 {
+			http.Error(w, "Invalid revision parameter", http.StatusBadRequest)
+			return
+		}
+		filter.Revision = revision
+		filter.InlineOnly = true
+	}
+	if inline, _ := strconv.ParseBool(params.Get("inline")); inline {
+		filter.InlineOnly = true
+	}
+
+	limit := filter.Limit
+	filter.Limit++
+	comments, err := store.ListComments(r.Context(), filter)
+	if err != nil {
+		http.Error(w, "Error fetching comments", http.StatusInternalServerError)
+		return
+	}
+
+	page := commentPage{Items: comments}
+	if len(comments) > limit {
+		page.Items = comments[:limit]
+		page.NextCursor = storage.Cursor{Sort: commentCursorSort, ID: page.Items[limit-1].ID}.Encode()
+	}
+	for i := range page.Items {
+		renderComment(&page.Items[i])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// CreateComment добавляет комментарий к алгоритму, ответ на комментарий (parent_id) или
+// комментарий к строкам line_start..line_end кода ревизии revision
+func CreateComment(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Body      string `json:"body"`
+		ParentID  *int   `json:"parent_id"`
+		Revision  *int   `json:"revision"`
+		LineStart *int   `json:"line_start"`
+		LineEnd   *int   `json:"line_end"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	body, err := validCommentBody(request.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, ok := visibleAlgorithmID(w, r)
+	if !ok {
+		return
+	}
+
+	comment := storage.Comment{AlgorithmID: id, UserID: r.Context().Value("userID").(int), Body: body}
+
+	if request.ParentID != nil {
+		// Ответ наследует привязку ветки, собственной у него быть не может
+		if request.Revision != nil || request.LineStart != nil || request.LineEnd != nil {
+			http.Error(w, "Replies cannot be anchored to code lines", http.StatusBadRequest)
+			return
+		}
+		parent, err := store.GetComment(r.Context(), *request.ParentID)
+		if err == storage.ErrNotFound || (err == nil && parent.AlgorithmID != id) {
+			http.Error(w, "Parent comment not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if parent.Deleted {
+			http.Error(w, "Cannot reply to a deleted comment", http.StatusConflict)
+			return
+		}
+		comment.ParentID = request.ParentID
+	} else if request.LineStart != nil || request.LineEnd != nil || request.Revision != nil {
+		if request.Revision == nil || request.LineStart == nil {
+			http.Error(w, "revision and line_start are required for inline comments", http.StatusBadRequest)
+			return
+		}
+		lineEnd := *request.LineStart
+		if request.LineEnd != nil {
+			lineEnd = *request.LineEnd
+		}
+
+		revision, err := store.GetRevision(r.Context(), id, *request.Revision)
+		if err == storage.ErrNotFound {
+			http.Error(w, "Revision not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		lines := strings.Count(strings.TrimSuffix(revision.Code, "\n"), "\n") + 1
+		if *request.LineStart < 1 || lineEnd < *request.LineStart || lineEnd > lines {
+			http.Error(w, fmt.Sprintf("Line range must be within 1..%d", lines), http.StatusBadRequest)
+			return
+		}
+		comment.Revision, comment.LineStart, comment.LineEnd = request.Revision, request.LineStart, &lineEnd
+	}
+
+	if err := store.CreateComment(r.Context(), &comment); err != nil {
+		http.Error(w, "Error creating comment", http.StatusInternalServerError)
+		return
+	}
+
+	comment, err = store.GetComment(r.Context(), comment.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderComment(&comment)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(comment)
+}
+
+// commentForChange находит комментарий по {id} и проверяет, что алгоритм ещё виден пользователю.
+// При отказе ответ уже записан в w.
+func commentForChange(w http.ResponseWriter, r *http.Request) (storage.Comment, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid ID parameter", http.StatusBadRequest)
+		return storage.Comment{}, false
+	}
+
+	comment, err := store.GetComment(r.Context(), id)
+	if err == nil && comment.Deleted {
+		err = storage.ErrNotFound
+	}
+	if err == nil {
+		var visible bool
+		visible, err = canViewAlgorithm(r.Context(), r.Context().Value("userID").(int), roleFromContext(r), comment.AlgorithmID)
+		if err == nil && !visible {
+			err = storage.ErrNotFound
+		}
+	}
+	if err == storage.ErrNotFound {
+		http.Error(w, "Comment not found", http.StatusNotFound)
+		return storage.Comment{}, false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return storage.Comment{}, false
+	}
+	return comment, true
+}
+
+// UpdateComment изменяет текст комментария; доступно только автору
+func UpdateComment(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	body, err := validCommentBody(request.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	comment, ok := commentForChange(w, r)
+	if !ok {
+		return
+	}
+	if comment.UserID != r.Context().Value("userID").(int) {
+		forbidden(w)
+		return
+	}
+
+	comment, err = store.UpdateComment(r.Context(), comment.ID, body, time.Now())
+	if err == storage.ErrNotFound {
+		http.Error(w, "Comment not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	renderComment(&comment)
+	json.NewEncoder(w).Encode(comment)
+}
+
+// DeleteComment удаляет комментарий. Автор удаляет свой, модератор — любой. Ответы остаются
+// в ветке, а на месте удалённого комментария клиент показывает заглушку.
+func DeleteComment(w http.ResponseWriter, r *http.Request) {
+	comment, ok := commentForChange(w, r)
+	if !ok {
+		return
+	}
+	userID := r.Context().Value("userID").(int)
+	if comment.UserID != userID && !hasPermission(roleFromContext(r), permModerateComments) {
+		forbidden(w)
+		return
+	}
+
+	err := store.DeleteComment(r.Context(), comment.ID, userID, time.Now())
+	if err == storage.ErrNotFound {
+		http.Error(w, "Comment not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
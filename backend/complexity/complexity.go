@@ -0,0 +1,383 @@
+// Package complexity разбирает асимптотические оценки вида O(n log n): проверяет запись,
+// приводит её к единому виду и вычисляет ранг роста, по которому оценки можно сортировать.
+//
+// Поддерживаются числа, переменные из одной буквы, сумма, произведение (явное через * или ·
+// и неявное, как в «n log n» или «nm»), степени n^2 и 2^n, факториал, log, lg, ln и sqrt.
+package complexity
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// MaxLength — наибольшая длина записи оценки (algorithms.time_complexity VARCHAR(100))
+const MaxLength = 100
+
+// Parse проверяет оценку и возвращает её нормализованную запись и ранг роста. Ранг считает все
+// переменные одной величиной n: O(1) < O(log n) < O(sqrt(n)) < O(n) < O(n log n) < O(n^2) < O(2^n) < O(n!).
+func Parse(s string) (string, float64, error) {
+	text := strings.TrimSpace(s)
+	if len([]rune(text)) > MaxLength {
+		return "", 0, fmt.Errorf("complexity must be at most %d characters", MaxLength)
+	}
+	if !strings.HasPrefix(text, "O(") || !strings.HasSuffix(text, ")") {
+		return "", 0, fmt.Errorf("complexity must look like O(n log n)")
+	}
+
+	p := &parser{tokens: tokenize(text[2 : len(text)-1])}
+	root, err := p.sum()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid complexity %q: %v", s, err)
+	}
+	return "O(" + root.String() + ")", root.growth().rank(), nil
+}
+
+// tokenize разбивает выражение на лексемы. Буквы читаются по одной, кроме имён функций,
+// поэтому «nlogn» разбирается как n, log, n.
+func tokenize(s string) []string {
+	s = strings.NewReplacer("²", "^2", "³", "^3", "√", "sqrt", "·", "*", "×", "*", "⋅", "*").Replace(s)
+	var tokens []string
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		case unicode.IsLetter(r):
+			name := string(r)
+			for _, f := range []string{"sqrt", "log", "lg", "ln"} {
+				if strings.HasPrefix(strings.ToLower(string(runes[i:])), f) {
+					name = f
+					break
+				}
+			}
+			tokens = append(tokens, name)
+			i += len([]rune(name))
+		default:
+			tokens = append(tokens, string(r))
+			i++
+		}
+	}
+	return tokens
+}
+
+type node interface {
+	String() string
+	growth() growth
+}
+
+type (
+	number   string
+	variable string
+	sum      []node
+	product  []node
+	power    struct{ base, exponent node }
+	factor   struct{ arg node }
+	function struct {
+		name  string
+		power int
+		arg   node
+	}
+)
+
+func (n number) String() string   { return string(n) }
+func (v variable) String() string { return string(v) }
+
+func (s sum) String() string {
+	terms := make([]string, len(s))
+	for i, term := range s {
+		terms[i] = term.String()
+	}
+	return strings.Join(terms, " + ")
+}
+
+// В произведении простые множители разделяются точкой (n·m), а перед функциями и скобками
+// ставится пробел (n log n)
+func (p product) String() string {
+	var b strings.Builder
+	for i, f := range p {
+		text := wrap(f, false)
+		if i > 0 {
+			if isAtom(p[i-1]) && isAtom(f) {
+				b.WriteString("·")
+			} else {
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString(text)
+	}
+	return b.String()
+}
+
+func (p power) String() string  { return wrap(p.base, true) + "^" + wrap(p.exponent, true) }
+func (f factor) String() string { return wrap(f.arg, true) + "!" }
+
+func (f function) String() string {
+	name := f.name
+	if f.power > 1 {
+		name += "^" + strconv.Itoa(f.power)
+	}
+	if f.name == "sqrt" {
+		return name + "(" + f.arg.String() + ")"
+	}
+	if _, ok := f.arg.(variable); ok {
+		return name + " " + f.arg.String()
+	}
+	return name + "(" + f.arg.String() + ")"
+}
+
+func isAtom(n node) bool {
+	switch n.(type) {
+	case number, variable, power, factor:
+		return true
+	}
+	return false
+}
+
+// wrap берёт в скобки составные части; tight — внутри степени и факториала, где в скобках нуждается и произведение
+func wrap(n node, tight bool) string {
+	switch n.(type) {
+	case sum:
+		return "(" + n.String() + ")"
+	case product, function:
+		if tight {
+			return "(" + n.String() + ")"
+		}
+	}
+	return n.String()
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *parser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+func (p *parser) sum() (node, error) {
+	var terms sum
+	for {
+		term, err := p.product()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+		if p.peek() != "+" {
+			break
+		}
+		p.next()
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return terms, nil
+}
+
+func (p *parser) product() (node, error) {
+	var factors product
+	for {
+		f, err := p.postfix()
+		if err != nil {
+			return nil, err
+		}
+		factors = append(factors, f)
+
+		// Умножение бывает явным или неявным: следующий множитель идёт сразу
+		if p.peek() == "*" {
+			p.next()
+			continue
+		}
+		if next := p.peek(); next == "" || next == "+" || next == ")" {
+			break
+		}
+	}
+	if len(factors) == 1 {
+		return factors[0], nil
+	}
+	return factors, nil
+}
+
+func (p *parser) postfix() (node, error) {
+	base, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch p.peek() {
+		case "^":
+			p.next()
+			exponent, err := p.primary()
+			if err != nil {
+				return nil, err
+			}
+			base = power{base, exponent}
+		case "!":
+			p.next()
+			base = factor{base}
+		default:
+			return base, nil
+		}
+	}
+}
+
+func (p *parser) primary() (node, error) {
+	token := p.next()
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("unexpected end")
+	case token == "(":
+		inner, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		return inner, nil
+	case token == "sqrt" || token == "log" || token == "lg" || token == "ln":
+		f := function{name: token, power: 1}
+		if f.name != "sqrt" {
+			// lg и ln отличаются от log только константой
+			f.name = "log"
+			if p.peek() == "^" {
+				p.next()
+				k, err := strconv.Atoi(p.next())
+				if err != nil || k < 1 {
+					return nil, fmt.Errorf("log power must be a positive integer")
+				}
+				f.power = k
+			}
+		}
+		arg, err := p.postfix()
+		if err != nil {
+			return nil, err
+		}
+		f.arg = arg
+		return f, nil
+	case unicode.IsDigit([]rune(token)[0]):
+		if _, err := strconv.ParseFloat(token, 64); err != nil {
+			return nil, fmt.Errorf("invalid number %q", token)
+		}
+		return number(token), nil
+	case len([]rune(token)) == 1 && unicode.IsLetter([]rune(token)[0]):
+		return variable(token), nil
+	}
+	return nil, fmt.Errorf("unexpected %q", token)
+}
+
+// growth — порядок роста при всех переменных, равных n: n!^fact · 2^(exp) · n^poly · log^log n
+type growth struct {
+	fact, exp, poly, log float64
+}
+
+func (g growth) less(other growth) bool {
+	if g.fact != other.fact {
+		return g.fact < other.fact
+	}
+	if g.exp != other.exp {
+		return g.exp < other.exp
+	}
+	if g.poly != other.poly {
+		return g.poly < other.poly
+	}
+	return g.log < other.log
+}
+
+func (g growth) plus(other growth) growth {
+	return growth{g.fact + other.fact, g.exp + other.exp, g.poly + other.poly, g.log + other.log}
+}
+
+func (g growth) times(k float64) growth {
+	return growth{g.fact * k, g.exp * k, g.poly * k, g.log * k}
+}
+
+// rank сворачивает порядок роста в одно число; составляющие ограничены, чтобы младшие не перекрывали старшие
+func (g growth) rank() float64 {
+	clamp := func(v float64) float64 { return math.Min(v, 999) }
+	return clamp(g.fact)*1e9 + clamp(g.exp)*1e6 + clamp(g.poly)*1e3 + clamp(g.log)
+}
+
+func (number) growth() growth   { return growth{} }
+func (variable) growth() growth { return growth{poly: 1} }
+
+func (s sum) growth() growth {
+	var max growth
+	for _, term := range s {
+		if g := term.growth(); max.less(g) {
+			max = g
+		}
+	}
+	return max
+}
+
+func (p product) growth() growth {
+	var total growth
+	for _, f := range p {
+		total = total.plus(f.growth())
+	}
+	return total
+}
+
+func (p power) growth() growth {
+	exponent := p.exponent.growth()
+	// Постоянный показатель: n^2, (n log n)^2
+	if exponent == (growth{}) {
+		k, _ := strconv.ParseFloat(p.exponent.String(), 64)
+		if _, ok := p.exponent.(number); !ok {
+			k = 1
+		}
+		return p.base.growth().times(k)
+	}
+	// Переменный показатель: 2^n, 3^n, а n^n растёт быстрее n!
+	if base, ok := p.base.(number); ok {
+		b, _ := strconv.ParseFloat(string(base), 64)
+		if b <= 1 {
+			return growth{}
+		}
+		return growth{exp: math.Log2(b) * math.Max(exponent.poly, 1)}
+	}
+	return growth{fact: 2}
+}
+
+func (f factor) growth() growth {
+	return growth{fact: 1}.plus(growth{fact: f.arg.growth().fact})
+}
+
+func (f function) growth() growth {
+	arg := f.arg.growth()
+	if f.name == "sqrt" {
+		return arg.times(0.5)
+	}
+	// Логарифм многочлена — тот же логарифм с точностью до константы, логарифм константы — константа
+	if arg == (growth{}) {
+		return growth{}
+	}
+	if arg.fact > 0 || arg.exp > 0 {
+		return growth{poly: 1, log: float64(f.power - 1)}
+	}
+	return growth{log: float64(f.power)}
+}
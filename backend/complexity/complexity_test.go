@@ -0,0 +1,115 @@
+package complexity
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseNormalizes(t *testing.T) {
+	tests := []struct {
+		source, want string
+	}{
+		{"O(1)", "O(1)"},
+		{"  O(n)  ", "O(n)"},
+		{"O(nlogn)", "O(n log n)"},
+		{"O(n log n)", "O(n log n)"},
+		{"O(n*log(n))", "O(n log n)"},
+		{"O(N LOG N)", "O(N log N)"},
+		{"O(lg n)", "O(log n)"},
+		{"O(ln n)", "O(log n)"},
+		{"O(log^2 n)", "O(log^2 n)"},
+		{"O(n²)", "O(n^2)"},
+		{"O(n³)", "O(n^3)"},
+		{"O(√n)", "O(sqrt(n))"},
+		{"O(sqrt(n))", "O(sqrt(n))"},
+		{"O(nm)", "O(n·m)"},
+		{"O(n×m)", "O(n·m)"},
+		{"O(V+E)", "O(V + E)"},
+		{"O((n log n)^2)", "O((n log n)^2)"},
+		{"O(n log n + n^2)", "O(n log n + n^2)"},
+		{"O(2^n)", "O(2^n)"},
+		{"O(n!)", "O(n!)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			got, _, err := Parse(tt.source)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.source, err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %q, want %q", tt.source, got, tt.want)
+			}
+			// Нормализованная запись разбирается в саму себя
+			again, _, err := Parse(got)
+			if err != nil || again != got {
+				t.Errorf("Parse(%q) = %q, %v; want it unchanged", got, again, err)
+			}
+		})
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	for _, source := range []string{
+		"", "n", "O(n", "O()", "O(n +)", "O((n)", "O(n))", "O(n $ m)", "O(log^0 n)", "O(log^x n)", "O(1..2)",
+		"O(" + strings.Repeat("n", MaxLength) + ")",
+	} {
+		if got, _, err := Parse(source); err == nil {
+			t.Errorf("Parse(%q) = %q, want an error", source, got)
+		}
+	}
+}
+
+func TestParseRankOrder(t *testing.T) {
+	// Каждая оценка растёт строго быстрее предыдущей
+	ordered := []string{
+		"O(1)",
+		"O(log n)",
+		"O(log^2 n)",
+		"O(sqrt(n))",
+		"O(n)",
+		"O(n log n)",
+		"O(n log^2 n)",
+		"O(n^2)",
+		"O(n^2 log n)",
+		"O(n^3)",
+		"O(1.5^n)",
+		"O(2^n)",
+		"O(3^n)",
+		"O(n!)",
+		"O(n^n)",
+	}
+	var previous float64
+	for i, source := range ordered {
+		_, rank, err := Parse(source)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", source, err)
+		}
+		if i > 0 && rank <= previous {
+			t.Errorf("rank(%s) = %v, want more than rank(%s) = %v", source, rank, ordered[i-1], previous)
+		}
+		previous = rank
+	}
+}
+
+func TestParseEqualRanks(t *testing.T) {
+	// Константы, основание логарифма и младшие слагаемые на ранг не влияют
+	tests := [][2]string{
+		{"O(1)", "O(42)"},
+		{"O(log n)", "O(lg n)"},
+		{"O(log n)", "O(log(n^2))"},
+		{"O(n)", "O(2n + 1)"},
+		{"O(n^2)", "O(n^2 + n log n)"},
+		{"O(n·m)", "O(n^2)"},
+		{"O(V + E)", "O(n)"},
+	}
+	for _, tt := range tests {
+		_, a, errA := Parse(tt[0])
+		_, b, errB := Parse(tt[1])
+		if errA != nil || errB != nil {
+			t.Fatalf("Parse(%q, %q) errors: %v, %v", tt[0], tt[1], errA, errB)
+		}
+		if a != b {
+			t.Errorf("rank(%s) = %v, rank(%s) = %v, want equal", tt[0], a, tt[1], b)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"AlgorithmsOnlineLibrary/storage"
+	"AlgorithmsOnlineLibrary/storage/memstore"
+)
+
+// setupTest подменяет глобальные cfg, store и keys на тестовые: хранилище в памяти,
+// дешёвый bcrypt, один HS256-ключ и почта в devMailbox
+func setupTest(t *testing.T) {
+	t.Helper()
+	cfg = defaultConfig()
+	cfg.Auth.BcryptCost = bcrypt.MinCost
+	cfg.Mail.Transport = "memory"
+	openMailer()
+	store = memstore.New()
+
+	var err error
+	keys, err = loadKeyRing("", "", strings.Repeat("s", 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testDrivers — хранилища, на которых гоняются тесты обработчиков: в памяти и SQLite
+var testDrivers = []string{"memory", "sqlite"}
+
+// useStore подменяет хранилище на новое пустое хранилище driver; SQLite создаётся
+// во временном каталоге со всеми миграциями
+func useStore(t *testing.T, driver string) {
+	t.Helper()
+	var err error
+	store, err = openStore(driver, filepath.Join(t.TempDir(), "test.db"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+}
+
+// asUser добавляет в контекст запроса то, что туда кладёт Authenticate
+func asUser(r *http.Request, user User) *http.Request {
+	ctx := context.WithValue(r.Context(), "userID", user.ID)
+	ctx = context.WithValue(ctx, "role", user.Role)
+	return r.WithContext(ctx)
+}
+
+// newTestUser создаёт подтверждённого пользователя с паролем password
+func newTestUser(t *testing.T, username, password string) User {
+	t.Helper()
+	hash, err := hashPassword(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	user := User{Username: username, Email: username + "@example.com", Role: roleUser}
+	tokenHash := hashToken("verify-" + username)
+	if err := store.RegisterUser(ctx, &user, hash, tokenHash, time.Now().Add(time.Hour), &storage.OutboxEmail{Kind: "verification", Recipient: user.Email}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.ConfirmUser(ctx, tokenHash, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// newTestToken открывает сессию пользователя и возвращает access-токен для неё
+func newTestToken(t *testing.T, user User) string {
+	t.Helper()
+	sessionID, _, err := createSession(user.ID, httptest.NewRequest("POST", "/login", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _, err := issueAccessToken(user, sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// signingKey — ключ из связки. У ключей, оставленных только для проверки старых токенов, private == nil.
+type signingKey struct {
+	ID      string
+	Method  jwt.SigningMethod
+	private interface{} // []byte, *rsa.PrivateKey или ed25519.PrivateKey
+	public  interface{} // []byte, *rsa.PublicKey или ed25519.PublicKey
+}
+
+// keyRing хранит все известные ключи по kid. Новые токены подписываются активным ключом,
+// а проверяться могут любым ключом связки, поэтому при ротации старые токены остаются валидными.
+type keyRing struct {
+	active *signingKey
+	keys   map[string]*signingKey
+}
+
+var keys *keyRing
+
+// loadKeyRing читает ключи из каталога dir. Имя файла без расширения становится kid:
+//
+//	<kid>.pem    — закрытый ключ RSA (RS256) или Ed25519 (EdDSA) в PEM, PKCS#1 или PKCS#8
+//	<kid>.pub    — открытый ключ в PEM (PKIX), только для проверки
+//	<kid>.secret — общий секрет HS256
+//
+// Если каталог не задан, используется один HS256-ключ из secret с kid "default".
+func loadKeyRing(dir, activeKID, secret string) (*keyRing, error) {
+	ring := &keyRing{keys: map[string]*signingKey{}}
+
+	if dir != "" {
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if file.IsDir() {
+				continue
+			}
+			key, err := loadKeyFile(filepath.Join(dir, file.Name()))
+			if err != nil {
+				return nil, err
+			}
+			if key == nil {
+				continue
+			}
+			if _, exists := ring.keys[key.ID]; exists {
+				return nil, fmt.Errorf("duplicate key id %q in %s", key.ID, dir)
+			}
+			ring.keys[key.ID] = key
+		}
+	}
+
+	if len(ring.keys) == 0 {
+		if secret == "" {
+			log.Println("WARNING: no JWT keys configured, using a random secret; tokens will not survive a restart")
+			random := make([]byte, 32)
+			if _, err := rand.Read(random); err != nil {
+				return nil, err
+			}
+			secret = string(random)
+		}
+		ring.keys["default"] = &signingKey{ID: "default", Method: jwt.SigningMethodHS256, private: []byte(secret), public: []byte(secret)}
+		if activeKID == "" {
+			activeKID = "default"
+		}
+	}
+
+	if activeKID == "" && len(ring.keys) == 1 {
+		for kid := range ring.keys {
+			activeKID = kid
+		}
+	}
+
+	active, ok := ring.keys[activeKID]
+	if !ok {
+		return nil, fmt.Errorf("active key %q not found", activeKID)
+	}
+	if active.private == nil {
+		return nil, fmt.Errorf("active key %q has no private part and cannot sign tokens", activeKID)
+	}
+	ring.active = active
+
+	return ring, nil
+}
+
+func loadKeyFile(path string) (*signingKey, error) {
+	name := filepath.Base(path)
+	ext := filepath.Ext(name)
+	kid := strings.TrimSuffix(name, ext)
+
+	if ext != ".pem" && ext != ".pub" && ext != ".secret" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if ext == ".secret" {
+		secret := strings.TrimSpace(string(data))
+		if len(secret) < 32 {
+			return nil, fmt.Errorf("%s: HS256 secret must be at least 32 bytes", path)
+		}
+		return &signingKey{ID: kid, Method: jwt.SigningMethodHS256, private: []byte(secret), public: []byte(secret)}, nil
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", path)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		err = fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		return &signingKey{ID: kid, Method: jwt.SigningMethodRS256, private: k, public: &k.PublicKey}, nil
+	case *rsa.PublicKey:
+		return &signingKey{ID: kid, Method: jwt.SigningMethodRS256, public: k}, nil
+	case ed25519.PrivateKey:
+		return &signingKey{ID: kid, Method: signingMethodEdDSA, private: k, public: k.Public().(ed25519.PublicKey)}, nil
+	case ed25519.PublicKey:
+		return &signingKey{ID: kid, Method: signingMethodEdDSA, public: k}, nil
+	}
+	return nil, fmt.Errorf("%s: unsupported key type %T", path, parsed)
+}
+
+// sign подписывает claims активным ключом и записывает его kid в заголовок
+func (kr *keyRing) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(kr.active.Method, claims)
+	token.Header["kid"] = kr.active.ID
+	return token.SignedString(kr.active.private)
+}
+
+// keyFunc выбирает ключ по kid и не принимает токен, подписанный другим алгоритмом,
+// чем тот, к которому привязан ключ (иначе открытый RSA-ключ можно подсунуть как секрет HS256).
+func (kr *keyRing) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token has no kid header")
+	}
+
+	key, ok := kr.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s for key %q", token.Method.Alg(), kid)
+	}
+
+	return key.public, nil
+}
+
+// parseToken проверяет подпись и срок действия токена
+func parseToken(tokenStr string, claims *Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenStr, claims, keys.keyFunc)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS публикует открытые ключи связки, чтобы другие сервисы могли проверять наши токены.
+// Ключи HS256 симметричные и сюда не попадают.
+func JWKS(w http.ResponseWriter, r *http.Request) {
+	var set []jwk = []jwk{}
+	for _, key := range keys.keys {
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			set = append(set, jwk{
+				Kty: "RSA",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set = append(set, jwk{
+				Kty: "OKP",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(public),
+			})
+		}
+	}
+	sort.Slice(set, func(i, j int) bool { return set[i].Kid < set[j].Kid })
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(map[string]interface{}{"keys": set})
+}
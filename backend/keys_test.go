@@ -0,0 +1,266 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// writeKeyDir складывает в каталог RSA- и Ed25519-ключи, открытый ключ без закрытого и секрет HS256
+func writeKeyDir(t *testing.T) (string, *rsa.PrivateKey) {
+	t.Helper()
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, filepath.Join(dir, "rsa1.pem"), "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, filepath.Join(dir, "ed1.pem"), "PRIVATE KEY", der)
+
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err = x509.MarshalPKIXPublicKey(&oldKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, filepath.Join(dir, "old.pub"), "PUBLIC KEY", der)
+
+	if err := os.WriteFile(filepath.Join(dir, "hs1.secret"), []byte(strings.Repeat("k", 32)+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// Файлы с другими расширениями пропускаются
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("keys"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return dir, rsaKey
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadKeyRing(t *testing.T) {
+	dir, _ := writeKeyDir(t)
+
+	tests := []struct {
+		name       string
+		dir        string
+		activeKID  string
+		secret     string
+		wantActive string
+		wantAlg    string
+		wantErr    bool
+	}{
+		{"secret only", "", "", strings.Repeat("s", 32), "default", "HS256", false},
+		{"random secret", "", "", "", "default", "HS256", false},
+		{"rsa", dir, "rsa1", "", "rsa1", "RS256", false},
+		{"ed25519", dir, "ed1", "", "ed1", "EdDSA", false},
+		{"hs256 file", dir, "hs1", "", "hs1", "HS256", false},
+		{"several keys without active", dir, "", "", "", "", true},
+		{"unknown active", dir, "missing", "", "", "", true},
+		{"public key cannot sign", dir, "old", "", "", "", true},
+		{"missing dir", filepath.Join(dir, "missing"), "rsa1", "", "", "", true},
+	}
+	for _, tt := range tests {
+		ring, err := loadKeyRing(tt.dir, tt.activeKID, tt.secret)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: loadKeyRing succeeded, want error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if ring.active.ID != tt.wantActive || ring.active.Method.Alg() != tt.wantAlg {
+			t.Errorf("%s: active %s %s, want %s %s", tt.name, ring.active.ID, ring.active.Method.Alg(), tt.wantActive, tt.wantAlg)
+		}
+	}
+}
+
+func TestLoadKeyRingRejectsBadFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{"short secret", map[string]string{"a.secret": "short"}},
+		{"not pem", map[string]string{"a.pem": "garbage"}},
+		{"unsupported block", map[string]string{"a.pem": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte{1}}))}},
+		{"duplicate kid", map[string]string{"a.secret": strings.Repeat("k", 32), "a.pub": "whatever"}},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		for name, content := range tt.files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := loadKeyRing(dir, "a", ""); err == nil {
+			t.Errorf("%s: loadKeyRing succeeded, want error", tt.name)
+		}
+	}
+}
+
+func TestKeyRingSignAndVerify(t *testing.T) {
+	dir, _ := writeKeyDir(t)
+	for _, kid := range []string{"rsa1", "ed1", "hs1"} {
+		ring, err := loadKeyRing(dir, kid, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = ring
+
+		claims := &Claims{Username: "alice", UserID: 1, StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Minute).Unix()}}
+		signed, err := keys.sign(claims)
+		if err != nil {
+			t.Fatalf("%s: %v", kid, err)
+		}
+		parsed := &Claims{}
+		token, err := parseToken(signed, parsed)
+		if err != nil || !token.Valid {
+			t.Errorf("%s: parseToken = %v", kid, err)
+			continue
+		}
+		if token.Header["kid"] != kid || parsed.Username != "alice" {
+			t.Errorf("%s: kid %v, username %q", kid, token.Header["kid"], parsed.Username)
+		}
+	}
+}
+
+func TestKeyRingRotation(t *testing.T) {
+	dir, _ := writeKeyDir(t)
+
+	// Токен, подписанный ключом до ротации, проверяется и после смены активного ключа
+	old, err := loadKeyRing(dir, "rsa1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := old.sign(&Claims{Username: "alice", StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Minute).Unix()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err = loadKeyRing(dir, "ed1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseToken(signed, &Claims{}); err != nil {
+		t.Errorf("token signed before rotation rejected: %v", err)
+	}
+
+	// А после удаления старого ключа из каталога — нет
+	if err := os.Remove(filepath.Join(dir, "rsa1.pem")); err != nil {
+		t.Fatal(err)
+	}
+	keys, err = loadKeyRing(dir, "ed1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseToken(signed, &Claims{}); err == nil {
+		t.Error("token signed by a removed key accepted")
+	}
+}
+
+func TestKeyFuncPinsAlgorithm(t *testing.T) {
+	dir, rsaKey := writeKeyDir(t)
+	var err error
+	keys, err = loadKeyRing(dir, "rsa1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)})
+	claims := &Claims{Username: "mallory", StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Minute).Unix()}}
+
+	tests := []struct {
+		name   string
+		method jwt.SigningMethod
+		kid    string
+		key    interface{}
+	}{
+		// Классическая подмена: открытый RSA-ключ используется как секрет HS256
+		{"rsa public key as hmac secret", jwt.SigningMethodHS256, "rsa1", publicPEM},
+		{"hmac key under rsa kid", jwt.SigningMethodHS256, "rsa1", []byte(strings.Repeat("k", 32))},
+		{"rsa token under hmac kid", jwt.SigningMethodRS256, "hs1", rsaKey},
+		{"unknown kid", jwt.SigningMethodRS256, "rsa2", rsaKey},
+		{"no kid", jwt.SigningMethodRS256, "", rsaKey},
+	}
+	for _, tt := range tests {
+		signed := signTest(t, tt.method, tt.kid, tt.key, claims)
+		if _, err := parseToken(signed, &Claims{}); err == nil {
+			t.Errorf("%s: token accepted", tt.name)
+		}
+	}
+
+	if _, err := parseToken(signTest(t, jwt.SigningMethodRS256, "rsa1", rsaKey, claims), &Claims{}); err != nil {
+		t.Errorf("properly signed token rejected: %v", err)
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	dir, rsaKey := writeKeyDir(t)
+	var err error
+	keys, err = loadKeyRing(dir, "rsa1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	JWKS(rec, httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&set); err != nil {
+		t.Fatal(err)
+	}
+
+	// Секрет HS256 публиковать нельзя, остальные ключи отсортированы по kid
+	var kids []string
+	for _, key := range set.Keys {
+		kids = append(kids, key.Kid+":"+key.Kty+":"+key.Alg)
+	}
+	want := "ed1:OKP:EdDSA old:RSA:RS256 rsa1:RSA:RS256"
+	if got := strings.Join(kids, " "); got != want {
+		t.Errorf("JWKS keys = %s, want %s", got, want)
+	}
+
+	for _, key := range set.Keys {
+		if key.Kid == "rsa1" && key.E != "AQAB" {
+			t.Errorf("rsa1 exponent %q, want AQAB", key.E)
+		}
+		if key.Kid == "rsa1" && len(key.N) != (rsaKey.N.BitLen()+5)/6 {
+			t.Errorf("rsa1 modulus has %d base64 characters", len(key.N))
+		}
+		if key.Kid == "ed1" && (key.Crv != "Ed25519" || key.X == "") {
+			t.Errorf("ed1 = %+v", key)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// Вход защищён от подбора пароля двумя счётчиками неудач подряд: по имени пользователя и по IP-адресу.
+// После login.free_attempts неудач следующую попытку можно сделать только после паузы, которая растёт
+// вдвое с каждой неудачей, а после login.lockout_threshold вход блокируется. Счётчик имени ведётся
+// и для несуществующих имён, чтобы по паузам нельзя было узнать, какие имена заняты.
+
+const (
+	// Как часто удалять забытые счётчики
+	loginPurgeInterval = time.Hour
+	// Сколько счётчиков показывать администратору
+	loginLockoutListLimit = 100
+)
+
+// Пароль неизвестного пользователя всё равно сравнивается с хешем, чтобы время ответа не выдавало,
+// есть ли такой пользователь
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, err := hashPassword("not a real password")
+	if err != nil {
+		log.Println("Error hashing dummy password:", err)
+	}
+	return hash
+})
+
+type loginLimit struct {
+	scope, subject   string
+	freeAttempts     int
+	lockoutThreshold int
+}
+
+func loginLimits(username, ip string) []loginLimit {
+	return []loginLimit{
+		{storage.LoginScopeAccount, username, cfg.Login.FreeAttempts, cfg.Login.LockoutThreshold},
+		{storage.LoginScopeIP, ip, cfg.Login.IPFreeAttempts, cfg.Login.IPLockoutThreshold},
+	}
+}
+
+// loginDelay — пауза после failures неудач подряд: первые freeAttempts обходятся без неё, дальше
+// login.delay, вдвое больше после каждой следующей, но не больше login.max_delay
+func loginDelay(failures, freeAttempts int) time.Duration {
+	if failures < freeAttempts {
+		return 0
+	}
+	delay, limit := time.Duration(cfg.Login.Delay), time.Duration(cfg.Login.MaxDelay)
+	for i := freeAttempts; i < failures && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+// claimLoginAttempt засчитывает попытку входа под именем username с адреса ip как неудачу ещё до
+// проверки пароля: проверка паузы и увеличение счётчика атомарны, поэтому пачка одновременных
+// запросов не получит больше попыток, чем пропускает пауза. Если попытку делать рано, ничего
+// не засчитывается и возвращается, сколько ждать. Удачный вход возвращает попытку через refundLoginAttempts.
+func claimLoginAttempt(ctx context.Context, username, ip string, now time.Time) ([]storage.LoginThrottle, time.Duration, error) {
+	var claimed []storage.LoginThrottle
+	for _, limit := range loginLimits(username, ip) {
+		throttle, wait, err := store.ClaimLoginAttempt(ctx, limit.scope, limit.subject, now, now.Add(-time.Duration(cfg.Login.FailureWindow)),
+			func(failures int) time.Duration { return loginDelay(failures, limit.freeAttempts) })
+		if err != nil || wait > 0 {
+			refundLoginAttempts(ctx, claimed)
+			return nil, wait, err
+		}
+		claimed = append(claimed, throttle)
+	}
+	return claimed, 0, nil
+}
+
+// refundLoginAttempts возвращает попытки, засчитанные claimLoginAttempt
+func refundLoginAttempts(ctx context.Context, claimed []storage.LoginThrottle) {
+	for _, throttle := range claimed {
+		err := store.RefundLoginAttempt(ctx, throttle.Scope, throttle.Subject)
+		if err != nil && err != storage.ErrNotFound {
+			log.Printf("Error refunding login attempt for %s %s: %v", throttle.Scope, throttle.Subject, err)
+		}
+	}
+}
+
+// recordLoginFailure блокирует вход, если неудач, уже засчитанных claimLoginAttempt, стало
+// слишком много. user — пользователь с именем username или nil, если такого нет.
+func recordLoginFailure(ctx context.Context, claimed []storage.LoginThrottle, username, ip string, user *storage.UserRecord, now time.Time) {
+	for i, limit := range loginLimits(username, ip) {
+		throttle := claimed[i]
+		if limit.lockoutThreshold == 0 || throttle.Failures < limit.lockoutThreshold ||
+			throttle.LockedUntil != nil && throttle.LockedUntil.After(now) {
+			continue
+		}
+		if err := lockLogin(ctx, throttle, ip, user, now); err != nil {
+			log.Printf("Error locking login for %s %s: %v", limit.scope, limit.subject, err)
+		}
+	}
+}
+
+// lockLogin блокирует вход на login.lockout_duration. Владельцу заблокированной учётной записи
+// уходит письмо со ссылкой, которая снимает блокировку.
+func lockLogin(ctx context.Context, throttle storage.LoginThrottle, ip string, user *storage.UserRecord, now time.Time) error {
+	until := now.Add(time.Duration(cfg.Login.LockoutDuration))
+
+	var unlockTokenHash string
+	var email *storage.OutboxEmail
+	if throttle.Scope == storage.LoginScopeAccount && user != nil && user.Confirmed {
+		unlockToken, err := generateResetToken()
+		if err != nil {
+			return err
+		}
+		email, err = composeEmail(storage.EmailAccountLocked, user.User, map[string]interface{}{
+			"URL":         cfg.Server.AppURL + "/unlock-account?token=" + unlockToken,
+			"LockedUntil": until.UTC().Format(emailTimeLayout),
+			"Failures":    throttle.Failures,
+			"IP":          ip,
+		})
+		if err != nil {
+			return err
+		}
+		unlockTokenHash = hashToken(unlockToken)
+	}
+
+	if err := store.LockLogin(ctx, throttle.Scope, throttle.Subject, until, unlockTokenHash, email); err != nil {
+		return err
+	}
+	log.Printf("Login locked for %s %s until %s after %d failed attempts", throttle.Scope, throttle.Subject, until.Format(time.RFC3339), throttle.Failures)
+	if email != nil {
+		notifyMailer()
+	}
+	return nil
+}
+
+// clearAccountLockout обнуляет счётчик имени после успешного входа или сброса пароля. Счётчик
+// адреса остаётся: иначе его сбрасывал бы вход в собственную учётную запись атакующего.
+func clearAccountLockout(ctx context.Context, username string) {
+	err := store.ClearLoginThrottle(ctx, storage.LoginScopeAccount, username)
+	if err != nil && err != storage.ErrNotFound {
+		log.Printf("Error clearing failed logins of %s: %v", username, err)
+	}
+}
+
+// setRetryAfter сообщает клиенту, через сколько секунд повторить запрос
+func setRetryAfter(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+}
+
+func purgeLoginThrottles() {
+	now := time.Now()
+	purged, err := store.PurgeLoginThrottles(context.Background(), now.Add(-time.Duration(cfg.Login.FailureWindow)), now)
+	if err != nil {
+		log.Println("Failed to purge login throttles:", err)
+		return
+	}
+
+	if purged > 0 {
+		log.Println("Purged login throttles:", purged)
+	}
+}
+
+func startLoginPurger() {
+	go func() {
+		ticker := time.NewTicker(loginPurgeInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			purgeLoginThrottles()
+		}
+	}()
+}
+
+// UnlockAccount снимает блокировку входа по ссылке из письма о блокировке
+func UnlockAccount(w http.ResponseWriter, r *http.Request) {
+	throttle, err := store.UnlockLogin(r.Context(), hashToken(r.URL.Query().Get("token")), time.Now())
+	if err == storage.ErrNotFound {
+		http.Error(w, "Invalid or expired unlock link", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Login unlocked for %s %s by the link from email", throttle.Scope, throttle.Subject)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Account unlocked, you can log in now"})
+}
+
+// GetLoginLockouts показывает администратору счётчики неудачных входов и действующие блокировки
+func GetLoginLockouts(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	throttles, err := store.ListLoginThrottles(r.Context(), now, now.Add(-time.Duration(cfg.Login.FailureWindow)), loginLockoutListLimit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(throttles)
+}
+
+// ClearLoginLockout обнуляет счётчик и снимает блокировку имени пользователя или IP-адреса
+func ClearLoginLockout(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	scope := vars["scope"]
+	if scope != storage.LoginScopeAccount && scope != storage.LoginScopeIP {
+		http.Error(w, "scope must be one of account, ip", http.StatusBadRequest)
+		return
+	}
+
+	err := store.ClearLoginThrottle(r.Context(), scope, vars["subject"])
+	if err == storage.ErrNotFound {
+		http.Error(w, "Lockout not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// login отправляет Login с адреса ip и возвращает код ответа
+func login(username, password, ip string) int {
+	req := httptest.NewRequest("POST", "/login", strings.NewReader(`{"username": "`+username+`", "password": "`+password+`"}`))
+	req.RemoteAddr = ip + ":40000"
+	rec := httptest.NewRecorder()
+	Login(rec, req)
+	return rec.Code
+}
+
+func TestLoginBurstCannotSkipDelay(t *testing.T) {
+	for _, driver := range testDrivers {
+		setupTest(t)
+		useStore(t, driver)
+		newTestUser(t, "alice", "password")
+
+		// Одновременные неверные пароли: пройти проверку должны ровно бесплатные попытки
+		const burst = 20
+		codes := make(chan int, burst)
+		var wg sync.WaitGroup
+		for i := 0; i < burst; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				codes <- login("alice", "wrong", "192.0.2.1")
+			}()
+		}
+		wg.Wait()
+		close(codes)
+
+		counts := map[int]int{}
+		for code := range codes {
+			counts[code]++
+		}
+		if counts[http.StatusUnauthorized] != cfg.Login.FreeAttempts || counts[http.StatusTooManyRequests] != burst-cfg.Login.FreeAttempts {
+			t.Errorf("%s: responses %v, want %d × 401 and the rest 429", driver, counts, cfg.Login.FreeAttempts)
+		}
+
+		throttles, err := store.ListLoginThrottles(context.Background(), time.Now(), time.Now().Add(-time.Hour), 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, throttle := range throttles {
+			if throttle.Failures != cfg.Login.FreeAttempts {
+				t.Errorf("%s: %s counter has %d failures, want %d", driver, throttle.Scope, throttle.Failures, cfg.Login.FreeAttempts)
+			}
+		}
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	for _, driver := range testDrivers {
+		setupTest(t)
+		useStore(t, driver)
+		cfg.Login.FreeAttempts = 10
+		cfg.Login.LockoutThreshold = 3
+		newTestUser(t, "alice", "password")
+
+		steps := []struct {
+			username, password, ip string
+			want                   int
+		}{
+			// Удачный вход не оставляет неудачи адресу
+			{"alice", "password", "192.0.2.1", http.StatusOK},
+			{"alice", "wrong", "192.0.2.1", http.StatusUnauthorized},
+			{"alice", "wrong", "192.0.2.2", http.StatusUnauthorized},
+			{"alice", "wrong", "192.0.2.3", http.StatusUnauthorized},
+			// Третья неудача заблокировала имя, и верный пароль уже не помогает ни с какого адреса
+			{"alice", "password", "192.0.2.4", http.StatusTooManyRequests},
+			// Несуществующее имя считается так же
+			{"bob", "wrong", "192.0.2.5", http.StatusUnauthorized},
+		}
+		for i, step := range steps {
+			if got := login(step.username, step.password, step.ip); got != step.want {
+				t.Errorf("%s, step %d: got %d, want %d", driver, i, got, step.want)
+			}
+		}
+
+		ctx := context.Background()
+		throttles, err := store.ListLoginThrottles(ctx, time.Now(), time.Now().Add(-time.Hour), 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := map[string]int{}
+		locked := map[string]bool{}
+		for _, throttle := range throttles {
+			got[throttle.Scope+" "+throttle.Subject] = throttle.Failures
+			locked[throttle.Scope+" "+throttle.Subject] = throttle.LockedUntil != nil
+		}
+		want := map[string]int{"account alice": 3, "ip 192.0.2.1": 1, "ip 192.0.2.2": 1, "ip 192.0.2.3": 1, "account bob": 1, "ip 192.0.2.5": 1}
+		for key, failures := range want {
+			if got[key] != failures {
+				t.Errorf("%s: %s has %d failures, want %d", driver, key, got[key], failures)
+			}
+		}
+		if len(got) != len(want) || !locked["account alice"] || locked["account bob"] {
+			t.Errorf("%s: counters %v, locked %v", driver, got, locked)
+		}
+
+		// Владельцу ушло письмо со ссылкой разблокировки
+		emails, err := store.ListEmails(ctx, storage.EmailPending, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var lockedEmails []string
+		for _, email := range emails {
+			if email.Kind == storage.EmailAccountLocked {
+				lockedEmails = append(lockedEmails, email.Recipient)
+			}
+		}
+		if strings.Join(lockedEmails, ",") != "alice@example.com" {
+			t.Errorf("%s: account_locked emails to %v, want one to alice", driver, lockedEmails)
+		}
+	}
+}
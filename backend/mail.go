@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"AlgorithmsOnlineLibrary/mailer"
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// Сколько писем помнит транспорт memory
+const devMailLimit = 100
+
+// Время в письмах указывается в UTC: часовой пояс получателя неизвестен
+const emailTimeLayout = "2006-01-02 15:04 UTC"
+
+var (
+	// mailSender доставляет письма из очереди; nil, если SMTP не настроен
+	mailSender mailer.Mailer
+	// devMailbox — тот же транспорт, если это memory
+	devMailbox     *mailer.Memory
+	emailTemplates *mailer.Templates
+)
+
+func openMailer() {
+	var err error
+	emailTemplates, err = mailer.LoadTemplates(cfg.Mail.TemplatesDir)
+	if err != nil {
+		log.Fatal("Failed to load email templates: ", err)
+	}
+
+	switch cfg.Mail.Transport {
+	case "memory":
+		devMailbox = mailer.NewMemory(devMailLimit)
+		mailSender = devMailbox
+	case "file":
+		dir, err := mailer.NewDir(cfg.Mail.Dir)
+		if err != nil {
+			log.Fatal("Failed to open mail directory: ", err)
+		}
+		mailSender = dir
+	default:
+		smtpMailer, err := mailer.NewSMTP(mailer.SMTPConfig{
+			Host:               cfg.SMTP.Host,
+			Port:               cfg.SMTP.Port,
+			Username:           cfg.SMTP.Username,
+			Password:           cfg.SMTP.Password,
+			Security:           cfg.SMTP.Security,
+			InsecureSkipVerify: cfg.SMTP.InsecureSkipVerify,
+			Timeout:            time.Duration(cfg.SMTP.Timeout),
+			IdleTimeout:        time.Duration(cfg.SMTP.IdleTimeout),
+		})
+		// Без почтового сервера письма копятся в очереди, пока его не настроят
+		if err != nil {
+			log.Println("Emails are not delivered:", err)
+			return
+		}
+		mailSender = smtpMailer
+	}
+}
+
+// composeEmail готовит письмо kind по шаблону на языке получателя. К data добавляются имя
+// получателя и адрес приложения.
+func composeEmail(kind string, to User, data map[string]interface{}) (*storage.OutboxEmail, error) {
+	data["Username"] = to.Username
+	data["AppURL"] = cfg.Server.AppURL
+	message, err := emailTemplates.Render(kind, to.Locale, data)
+	if err != nil {
+		return nil, err
+	}
+	return &storage.OutboxEmail{Kind: kind, Recipient: to.Email, Subject: message.Subject, Body: message.Text, HTMLBody: message.HTML}, nil
+}
+
+// emailSample — данные для предпросмотра шаблона kind
+func emailSample(kind string) map[string]interface{} {
+	switch kind {
+	case storage.EmailVerification:
+		return map[string]interface{}{"URL": cfg.Server.AppURL + "/verify-email?token=0123456789abcdef0123456789abcdef",
+			"ExpiresAt": time.Now().Add(time.Duration(cfg.Auth.VerificationTTL)).UTC().Format(emailTimeLayout)}
+	case storage.EmailPasswordReset:
+		return map[string]interface{}{"Token": "0123456789abcdef0123456789abcdef"}
+	case storage.EmailModeration:
+		return map[string]interface{}{"AlgorithmID": 1, "Title": "Binary search", "Status": moderationChangesRequested,
+			"Comment": "Please describe the input format."}
+	case storage.EmailPasswordChanged:
+		return map[string]interface{}{"ChangedAt": time.Now().UTC().Format(emailTimeLayout)}
+	case storage.EmailAccountLocked:
+		return map[string]interface{}{"URL": cfg.Server.AppURL + "/unlock-account?token=0123456789abcdef0123456789abcdef",
+			"LockedUntil": time.Now().Add(time.Duration(cfg.Login.LockoutDuration)).UTC().Format(emailTimeLayout), "Failures": cfg.Login.LockoutThreshold,
+			"IP": "203.0.113.7"}
+	}
+	return map[string]interface{}{}
+}
+
+// SetLocale меняет язык писем текущего пользователя
+func SetLocale(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Locale string `json:"locale"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if mailer.Locale(body.Locale) != body.Locale {
+		http.Error(w, "Unsupported locale", http.StatusBadRequest)
+		return
+	}
+
+	if err := store.SetLocale(r.Context(), r.Context().Value("userID").(int), body.Locale); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"locale": body.Locale})
+}
+
+// GetEmailTemplates перечисляет шаблоны писем и языки, на которых они есть
+func GetEmailTemplates(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string][]string{"templates": emailTemplates.Names(), "locales": mailer.Locales})
+}
+
+// PreviewEmailTemplate собирает письмо по шаблону на примерных данных. По умолчанию отдаёт тему,
+// текст и HTML в JSON; format=html и format=text отдают одну версию, чтобы открыть её в браузере.
+func PreviewEmailTemplate(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	locale := r.URL.Query().Get("locale")
+	if locale == "" {
+		locale = mailer.Locales[0]
+	}
+	if mailer.Locale(locale) != locale {
+		http.Error(w, "Unsupported locale", http.StatusBadRequest)
+		return
+	}
+	known := false
+	for _, template := range emailTemplates.Names() {
+		known = known || template == name
+	}
+	if !known {
+		http.Error(w, "Template not found", http.StatusNotFound)
+		return
+	}
+
+	sample := User{Username: "alice", Email: "alice@example.com", Locale: locale}
+	email, err := composeEmail(name, sample, emailSample(name))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(email.HTMLBody))
+	case "text":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(email.Body))
+	case "":
+		json.NewEncoder(w).Encode(map[string]string{"subject": email.Subject, "text": email.Body, "html": email.HTMLBody})
+	default:
+		http.Error(w, "format must be html or text", http.StatusBadRequest)
+	}
+}
+
+// GetDevMail показывает письма, перехваченные транспортом memory, вместе с текстом; параметр to
+// оставляет письма одного адресата. Маршрут есть, только когда mail.transport — memory.
+func GetDevMail(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(devMailbox.Messages(r.URL.Query().Get("to")))
+}
+
+func GetDevMailMessage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid ID parameter", http.StatusBadRequest)
+		return
+	}
+	message, ok := devMailbox.Message(id)
+	if !ok {
+		http.Error(w, "Message not found", http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(message)
+}
+
+func ClearDevMail(w http.ResponseWriter, r *http.Request) {
+	devMailbox.Clear()
+	w.WriteHeader(http.StatusNoContent)
+}
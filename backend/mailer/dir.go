@@ -0,0 +1,58 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Dir сохраняет каждое письмо в отдельный .eml-файл каталога; такой файл открывает любой
+// почтовый клиент. Имена файлов упорядочены по времени отправки.
+type Dir struct {
+	path string
+
+	mu   sync.Mutex
+	sent int
+}
+
+func NewDir(path string) (*Dir, error) {
+	if err := os.MkdirAll(path, 0o700); err != nil {
+		return nil, err
+	}
+	return &Dir{path: path}, nil
+}
+
+func (d *Dir) Send(ctx context.Context, message Message) error {
+	data, err := Encode(message)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.sent++
+	name := fmt.Sprintf("%s-%06d.eml", time.Now().UTC().Format("20060102T150405.000Z"), d.sent)
+	d.mu.Unlock()
+
+	// Письмо пишется во временный файл и переименовывается, чтобы в каталоге не появлялись недописанные
+	file, err := os.CreateTemp(d.path, ".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return os.Rename(file.Name(), filepath.Join(d.path, name))
+}
+
+func (d *Dir) Close() error {
+	return nil
+}
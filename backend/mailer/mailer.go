@@ -0,0 +1,73 @@
+// Package mailer доставляет письма. Интерфейс Mailer скрывает способ доставки: SMTP для работы,
+// а для разработки без почтового сервера — каталог с .eml-файлами или память процесса.
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/mail"
+	"strings"
+	"time"
+
+	"gopkg.in/gomail.v2"
+)
+
+// Message — письмо. Адреса можно указывать с именем: "Library <noreply@example.com>".
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Text    string
+	// HTML — необязательная HTML-версия; с ней письмо отправляется как multipart/alternative
+	HTML string
+}
+
+type Mailer interface {
+	// Send доставляет письмо; ошибка означает, что письмо не принято и его можно отправить снова
+	Send(ctx context.Context, message Message) error
+	Close() error
+}
+
+// Encode собирает письмо в формате RFC 5322 вместе с заголовками Date и Message-ID
+func Encode(message Message) ([]byte, error) {
+	m := gomail.NewMessage()
+	m.SetHeader("From", message.From)
+	m.SetHeader("To", message.To)
+	m.SetHeader("Subject", message.Subject)
+	m.SetHeader("Message-ID", messageID(message.From))
+	m.SetDateHeader("Date", time.Now())
+	m.SetBody("text/plain", message.Text)
+	if message.HTML != "" {
+		m.AddAlternative("text/html", message.HTML)
+	}
+
+	var buf bytes.Buffer
+	if _, err := m.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// messageID — уникальный идентификатор письма в домене отправителя
+func messageID(from string) string {
+	domain := "localhost"
+	if address, err := mail.ParseAddress(from); err == nil {
+		if at := strings.LastIndex(address.Address, "@"); at >= 0 {
+			domain = address.Address[at+1:]
+		}
+	}
+	id := make([]byte, 16)
+	rand.Read(id)
+	return "<" + hex.EncodeToString(id) + "@" + domain + ">"
+}
+
+// address возвращает адрес без имени для конверта SMTP
+func address(value string) (string, error) {
+	parsed, err := mail.ParseAddress(value)
+	if err != nil {
+		return "", err
+	}
+	return parsed.Address, nil
+}
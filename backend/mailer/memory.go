@@ -0,0 +1,82 @@
+package mailer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Captured — письмо, которое перехватил Memory
+type Captured struct {
+	ID      int       `json:"id"`
+	From    string    `json:"from"`
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Text    string    `json:"text"`
+	HTML    string    `json:"html,omitempty"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// Memory ничего не отправляет, а хранит последние limit писем в памяти процесса, чтобы их
+// можно было прочитать через API, например ссылку подтверждения при регистрации
+type Memory struct {
+	limit int
+
+	mu       sync.Mutex
+	lastID   int
+	captured []Captured
+}
+
+func NewMemory(limit int) *Memory {
+	return &Memory{limit: limit}
+}
+
+func (m *Memory) Send(ctx context.Context, message Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lastID++
+	m.captured = append(m.captured, Captured{ID: m.lastID, From: message.From, To: message.To, Subject: message.Subject,
+		Text: message.Text, HTML: message.HTML, SentAt: time.Now().UTC()})
+	if len(m.captured) > m.limit {
+		m.captured = append([]Captured(nil), m.captured[len(m.captured)-m.limit:]...)
+	}
+	return nil
+}
+
+// Messages возвращает перехваченные письма, начиная с последнего; при непустом to — только этому адресату
+func (m *Memory) Messages(to string) []Captured {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	messages := []Captured{}
+	for i := len(m.captured) - 1; i >= 0; i-- {
+		if to == "" || m.captured[i].To == to {
+			messages = append(messages, m.captured[i])
+		}
+	}
+	return messages
+}
+
+func (m *Memory) Message(id int) (Captured, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, message := range m.captured {
+		if message.ID == id {
+			return message, true
+		}
+	}
+	return Captured{}, false
+}
+
+func (m *Memory) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.captured = nil
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
@@ -0,0 +1,259 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Способ защиты соединения с SMTP-сервером
+const (
+	// SecurityAuto — TLS с самого начала на порту 465, иначе STARTTLS, если сервер его предлагает
+	SecurityAuto = "auto"
+	// SecurityTLS — TLS с самого начала соединения (SMTPS)
+	SecurityTLS = "tls"
+	// SecurityStartTLS — обязательный STARTTLS: без него письма не отправляются
+	SecurityStartTLS = "starttls"
+	// SecurityNone — без шифрования; годится только для локального сервера
+	SecurityNone = "none"
+)
+
+var ErrNoHost = errors.New("smtp host is not configured")
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	Security string
+	// Не проверять сертификат сервера, например самоподписанный у тестового сервера
+	InsecureSkipVerify bool
+	// Ограничение на установку соединения и на отправку одного письма
+	Timeout time.Duration
+	// Сколько держать открытым соединение, по которому не идут письма
+	IdleTimeout time.Duration
+}
+
+// SMTP отправляет письма через одно соединение, пока они идут чаще IdleTimeout, и открывает
+// новое, если сервер закрыл прежнее. Письма отправляются по очереди.
+type SMTP struct {
+	config SMTPConfig
+
+	mu       sync.Mutex
+	conn     net.Conn
+	client   *smtp.Client
+	lastUsed time.Time
+	idle     *time.Timer
+}
+
+func NewSMTP(config SMTPConfig) (*SMTP, error) {
+	if config.Host == "" {
+		return nil, ErrNoHost
+	}
+	switch config.Security {
+	case SecurityAuto, SecurityTLS, SecurityStartTLS, SecurityNone:
+	default:
+		return nil, fmt.Errorf("unknown smtp security %q", config.Security)
+	}
+	return &SMTP{config: config}, nil
+}
+
+func (s *SMTP) Send(ctx context.Context, message Message) error {
+	from, err := address(message.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender: %w", err)
+	}
+	to, err := address(message.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient: %w", err)
+	}
+	data, err := Encode(message)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Сервер мог закрыть соединение, пока писем не было: RSET проверяет его перед письмом
+	if s.client != nil {
+		s.conn.SetDeadline(s.deadline(ctx))
+		if err := s.client.Reset(); err != nil {
+			s.closeLocked()
+		}
+	}
+	if s.client == nil {
+		if err := s.dial(ctx); err != nil {
+			return err
+		}
+	}
+
+	s.conn.SetDeadline(s.deadline(ctx))
+	if err := s.deliver(from, to, data); err != nil {
+		// После ошибки посреди диалога состояние соединения неизвестно
+		s.closeLocked()
+		return err
+	}
+	s.lastUsed = time.Now()
+	if s.idle == nil {
+		s.idle = time.AfterFunc(s.config.IdleTimeout, s.closeIdle)
+	} else {
+		s.idle.Reset(s.config.IdleTimeout)
+	}
+	return nil
+}
+
+func (s *SMTP) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.idle != nil {
+		s.idle.Stop()
+	}
+	if s.client == nil {
+		return nil
+	}
+	s.conn.SetDeadline(time.Now().Add(s.config.Timeout))
+	err := s.client.Quit()
+	s.closeLocked()
+	return err
+}
+
+func (s *SMTP) deliver(from, to string, data []byte) error {
+	if err := s.client.Mail(from); err != nil {
+		return err
+	}
+	if err := s.client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := s.client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (s *SMTP) dial(ctx context.Context) error {
+	tlsConfig := &tls.Config{ServerName: s.config.Host, InsecureSkipVerify: s.config.InsecureSkipVerify}
+	implicitTLS := s.config.Security == SecurityTLS || s.config.Security == SecurityAuto && s.config.Port == 465
+
+	dialer := net.Dialer{Timeout: s.config.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port)))
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(s.deadline(ctx))
+	if implicitTLS {
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return err
+		}
+		conn = tlsConn
+	}
+
+	client, err := smtp.NewClient(conn, s.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if !implicitTLS && s.config.Security != SecurityNone {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			err = client.StartTLS(tlsConfig)
+		} else if s.config.Security == SecurityStartTLS {
+			err = errors.New("smtp server does not support STARTTLS")
+		}
+	}
+	if err == nil && s.config.Username != "" {
+		if ok, mechanisms := client.Extension("AUTH"); ok {
+			err = client.Auth(s.auth(mechanisms))
+		}
+	}
+	if err != nil {
+		client.Close()
+		return err
+	}
+	s.conn, s.client = conn, client
+	return nil
+}
+
+// auth выбирает способ входа из предложенных сервером, как это делал gomail: CRAM-MD5, затем
+// PLAIN, а LOGIN — только если сервер не знает PLAIN
+func (s *SMTP) auth(mechanisms string) smtp.Auth {
+	switch {
+	case strings.Contains(mechanisms, "CRAM-MD5"):
+		return smtp.CRAMMD5Auth(s.config.Username, s.config.Password)
+	case strings.Contains(mechanisms, "LOGIN") && !strings.Contains(mechanisms, "PLAIN"):
+		return &loginAuth{username: s.config.Username, password: s.config.Password, host: s.config.Host}
+	default:
+		// PlainAuth сам откажется передавать пароль без шифрования, если сервер не локальный
+		return smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+	}
+}
+
+// loginAuth — способ входа LOGIN, которого нет в net/smtp
+type loginAuth struct {
+	username, password, host string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && server.Name != "localhost" && server.Name != "127.0.0.1" && server.Name != "::1" {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSuffix(string(fromServer), ":")) {
+	case "username":
+		return []byte(a.username), nil
+	case "password":
+		return []byte(a.password), nil
+	}
+	return nil, fmt.Errorf("unexpected server challenge %q", fromServer)
+}
+
+// deadline — срок операции: Timeout от текущего момента, но не позже срока ctx
+func (s *SMTP) deadline(ctx context.Context) time.Time {
+	deadline := time.Now().Add(s.config.Timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		return ctxDeadline
+	}
+	return deadline
+}
+
+// closeIdle закрывает соединение, если за IdleTimeout по нему ничего не отправили
+func (s *SMTP) closeIdle() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client != nil && time.Since(s.lastUsed) >= s.config.IdleTimeout {
+		s.conn.SetDeadline(time.Now().Add(s.config.Timeout))
+		s.client.Quit()
+		s.closeLocked()
+	}
+}
+
+func (s *SMTP) closeLocked() {
+	if s.client != nil {
+		s.client.Close()
+	}
+	s.conn, s.client = nil, nil
+}
@@ -0,0 +1,158 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Locales — языки писем. Первый используется, если языка получателя среди них нет.
+var Locales = []string{"en", "ru"}
+
+//go:embed templates
+var embeddedTemplates embed.FS
+
+// Templates — шаблоны писем. Письмо name на языке locale задают два файла: <locale>/<name>.txt
+// с темой в блоке subject и текстом письма, и <locale>/<name>.html с блоком content, который
+// вставляется в общий layout.html. Файлы из каталога переопределения заменяют одноимённые
+// встроенные, остальные берутся из встроенных.
+type Templates struct {
+	names []string
+	text  map[string]*texttemplate.Template
+	html  map[string]*htmltemplate.Template
+}
+
+// LoadTemplates разбирает все шаблоны сразу, чтобы ошибка в переопределённом файле была видна
+// при запуске, а не при отправке. Пустой dir — только встроенные шаблоны.
+func LoadTemplates(dir string) (*Templates, error) {
+	entries, err := fs.ReadDir(embeddedTemplates, "templates/"+Locales[0])
+	if err != nil {
+		return nil, err
+	}
+	t := &Templates{text: map[string]*texttemplate.Template{}, html: map[string]*htmltemplate.Template{}}
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), ".txt"); ok {
+			t.names = append(t.names, name)
+		}
+	}
+	sort.Strings(t.names)
+
+	layout, err := readTemplate(dir, "layout.html")
+	if err != nil {
+		return nil, err
+	}
+	for _, locale := range Locales {
+		for _, name := range t.names {
+			key := locale + "/" + name
+
+			source, err := readTemplate(dir, key+".txt")
+			if err != nil {
+				return nil, err
+			}
+			text, err := texttemplate.New(name).Option("missingkey=error").Parse(source)
+			if err != nil {
+				return nil, err
+			}
+			if text.Lookup("subject") == nil {
+				return nil, fmt.Errorf("%s.txt: subject block is missing", key)
+			}
+
+			source, err = readTemplate(dir, key+".html")
+			if err != nil {
+				return nil, err
+			}
+			html, err := htmltemplate.New("layout.html").Option("missingkey=error").Parse(layout)
+			if err == nil {
+				_, err = html.New(name).Parse(source)
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			t.text[key], t.html[key] = text, html
+		}
+	}
+	return t, nil
+}
+
+// Names возвращает имена писем, для которых есть шаблоны
+func (t *Templates) Names() []string {
+	return append([]string(nil), t.names...)
+}
+
+// Render собирает тему, текст и HTML письма name на языке locale; у результата не заполнены
+// отправитель и получатель
+func (t *Templates) Render(name, locale string, data interface{}) (Message, error) {
+	key := Locale(locale) + "/" + name
+	text, ok := t.text[key]
+	if !ok {
+		return Message{}, fmt.Errorf("unknown email template %q", name)
+	}
+
+	var subject, body, html bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, err
+	}
+	if err := text.Execute(&body, data); err != nil {
+		return Message{}, err
+	}
+	if err := t.html[key].ExecuteTemplate(&html, "layout.html", data); err != nil {
+		return Message{}, err
+	}
+	return Message{
+		// Перевод строки в теме сломал бы заголовки письма
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Text:    strings.TrimSpace(body.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
+
+// Locale возвращает locale, если на нём есть шаблоны, иначе язык по умолчанию
+func Locale(locale string) string {
+	for _, supported := range Locales {
+		if locale == supported {
+			return locale
+		}
+	}
+	return Locales[0]
+}
+
+// MatchLocale выбирает язык из заголовка Accept-Language, например "ru-RU,ru;q=0.9,en;q=0.8".
+// Языки берутся в порядке заголовка, веса q не учитываются.
+func MatchLocale(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		language, _, _ := strings.Cut(tag, "-")
+		language = strings.ToLower(language)
+		for _, supported := range Locales {
+			if language == supported {
+				return supported
+			}
+		}
+	}
+	return Locales[0]
+}
+
+// readTemplate читает файл из каталога переопределения, а если его там нет — встроенный
+func readTemplate(dir, name string) (string, error) {
+	if dir != "" {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err == nil {
+			return string(data), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+	data, err := embeddedTemplates.ReadFile(path.Join("templates", name))
+	return string(data), err
+}
@@ -0,0 +1,213 @@
+// Package markdown превращает пользовательский Markdown в безопасный HTML.
+//
+// Поддерживается подмножество, которого хватает для комментариев и описаний: абзацы, заголовки,
+// списки, цитаты, блоки кода, `код`, **жирный**, *курсив*, ~~зачёркнутый~~ и ссылки.
+// Весь текст сначала экранируется, а теги порождает только сам рендерер, поэтому HTML
+// из ввода никогда не попадает в результат как разметка. Ссылки допускаются только
+// на http(s), mailto и относительные адреса.
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	headingRe     = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	unorderedRe   = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	orderedRe     = regexp.MustCompile(`^\s*\d{1,9}[.)]\s+(.*)$`)
+	quoteRe       = regexp.MustCompile(`^\s*&gt;\s?(.*)$`)
+	fenceRe       = regexp.MustCompile("^\\s*(```|~~~)\\s*([A-Za-z0-9+#._-]*)\\s*$")
+	strongRe      = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*|__(\S(?:.*?\S)?)__`)
+	emphasisRe    = regexp.MustCompile(`\*(\S(?:.*?\S)?)\*`)
+	strikeRe      = regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`)
+	linkStartRe   = regexp.MustCompile(`\[([^\]]+)\]\(`)
+	allowedScheme = []string{"http://", "https://", "mailto:", "/", "#"}
+)
+
+// Render возвращает HTML для текста source
+func Render(source string) string {
+	// Нулевой байт служит меткой ссылки в emphasis, во вводе ему делать нечего
+	source = strings.NewReplacer("\r\n", "\n", "\x00", "").Replace(source)
+	lines := strings.Split(html.EscapeString(source), "\n")
+
+	var out strings.Builder
+	var paragraph []string
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			out.WriteString("<p>" + inline(strings.Join(paragraph, "\n")) + "</p>\n")
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if match := fenceRe.FindStringSubmatch(line); match != nil {
+			flushParagraph()
+			var code []string
+			for i++; i < len(lines) && strings.TrimSpace(lines[i]) != match[1]; i++ {
+				code = append(code, lines[i])
+			}
+			if match[2] != "" {
+				out.WriteString(`<pre><code class="language-` + match[2] + `">`)
+			} else {
+				out.WriteString("<pre><code>")
+			}
+			out.WriteString(strings.Join(code, "\n") + "</code></pre>\n")
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			flushParagraph()
+			continue
+		}
+
+		if match := headingRe.FindStringSubmatch(line); match != nil {
+			flushParagraph()
+			level := string(rune('0' + len(match[1])))
+			out.WriteString("<h" + level + ">" + inline(match[2]) + "</h" + level + ">\n")
+			continue
+		}
+
+		if quoteRe.MatchString(line) {
+			flushParagraph()
+			var quote []string
+			for ; i < len(lines) && quoteRe.MatchString(lines[i]); i++ {
+				quote = append(quote, quoteRe.FindStringSubmatch(lines[i])[1])
+			}
+			i--
+			out.WriteString("<blockquote><p>" + inline(strings.Join(quote, "\n")) + "</p></blockquote>\n")
+			continue
+		}
+
+		if list, re := listKind(line); list != "" {
+			flushParagraph()
+			out.WriteString("<" + list + ">\n")
+			for ; i < len(lines) && re.MatchString(lines[i]); i++ {
+				out.WriteString("<li>" + inline(re.FindStringSubmatch(lines[i])[1]) + "</li>\n")
+			}
+			i--
+			out.WriteString("</" + list + ">\n")
+			continue
+		}
+
+		paragraph = append(paragraph, line)
+	}
+	flushParagraph()
+
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+func listKind(line string) (string, *regexp.Regexp) {
+	switch {
+	case unorderedRe.MatchString(line):
+		return "ul", unorderedRe
+	case orderedRe.MatchString(line):
+		return "ol", orderedRe
+	}
+	return "", nil
+}
+
+// inline размечает строку внутри блока. Код в обратных кавычках не разбирается дальше,
+// чтобы звёздочки и скобки в нём остались как есть.
+func inline(text string) string {
+	parts := strings.Split(text, "`")
+	var out strings.Builder
+	for i, part := range parts {
+		switch {
+		case i%2 == 1 && i < len(parts)-1:
+			out.WriteString("<code>" + part + "</code>")
+		case i%2 == 1:
+			// Непарная обратная кавычка остаётся текстом
+			out.WriteString("`" + emphasis(part))
+		default:
+			out.WriteString(emphasis(part))
+		}
+	}
+	return strings.ReplaceAll(out.String(), "\n", "<br>\n")
+}
+
+// emphasis размечает выделение и ссылки. Адреса ссылок на время разбора выделения заменяются
+// метками, иначе звёздочки в URL превратились бы в теги внутри href.
+func emphasis(text string) string {
+	var hrefs []string
+	text = replaceLinks(text, func(label, url string) string {
+		if !safeURL(url) {
+			return label
+		}
+		hrefs = append(hrefs, url)
+		return "<a href=\"\x00\" rel=\"nofollow noopener noreferrer\">" + label + "</a>"
+	})
+
+	text = strongRe.ReplaceAllString(text, "<strong>$1$2</strong>")
+	text = emphasisRe.ReplaceAllString(text, "<em>$1</em>")
+	text = strikeRe.ReplaceAllString(text, "<del>$1</del>")
+
+	for _, href := range hrefs {
+		text = strings.Replace(text, "\x00", href, 1)
+	}
+	return text
+}
+
+// replaceLinks заменяет каждую ссылку [text](url) результатом fn. Как в CommonMark, адрес может
+// содержать скобки, если они сбалансированы, и кончается на первой непарной «)»: так
+// https://en.wikipedia.org/wiki/Tree_(graph_theory) остаётся целым адресом.
+func replaceLinks(text string, fn func(label, url string) string) string {
+	var out strings.Builder
+	for {
+		match := linkStartRe.FindStringSubmatchIndex(text)
+		if match == nil {
+			break
+		}
+		end := linkDestinationEnd(text[match[1]:])
+		if end < 0 {
+			// Не ссылка: скобка остаётся текстом, ищем дальше
+			out.WriteString(text[:match[0]+1])
+			text = text[match[0]+1:]
+			continue
+		}
+		out.WriteString(text[:match[0]])
+		out.WriteString(fn(text[match[2]:match[3]], text[match[1]:match[1]+end]))
+		text = text[match[1]+end+1:]
+	}
+	out.WriteString(text)
+	return out.String()
+}
+
+// linkDestinationEnd возвращает длину адреса в начале s до закрывающей его «)» или -1, если
+// адрес пустой, содержит пробел или скобка так и не закрылась
+func linkDestinationEnd(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case ' ', '\t', '\n':
+			return -1
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+				continue
+			}
+			if i == 0 {
+				return -1
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// safeURL пропускает только адреса с разрешённой схемой. URL уже экранирован, поэтому
+// кавычки в нём не могут закрыть атрибут href.
+func safeURL(url string) bool {
+	lower := strings.ToLower(url)
+	for _, prefix := range allowedScheme {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name, source, want string
+	}{
+		{"paragraph", "line1\nline2", "<p>line1<br>\nline2</p>"},
+		{"heading", "# Head #", "<h1>Head</h1>"},
+		{"unordered list", "- a\n- b", "<ul>\n<li>a</li>\n<li>b</li>\n</ul>"},
+		{"ordered list", "1. a\n2) b", "<ol>\n<li>a</li>\n<li>b</li>\n</ol>"},
+		{"quote", "> q\n> r", "<blockquote><p>q<br>\nr</p></blockquote>"},
+		{"fence with language", "```go\nx\n```\nafter", "<pre><code class=\"language-go\">x</code></pre>\n<p>after</p>"},
+		{"unpaired fence runs to the end", "```\ncode *a*\n<b>", "<pre><code>code *a*\n&lt;b&gt;</code></pre>"},
+		{"emphasis", "**bold** and *em* and ~~del~~", "<p><strong>bold</strong> and <em>em</em> and <del>del</del></p>"},
+		{"underscore strong", "__bold__", "<p><strong>bold</strong></p>"},
+		{"stars around spaces are text", "a * b * c", "<p>a * b * c</p>"},
+		{"no emphasis inside code", "`*not*` *yes*", "<p><code>*not*</code> <em>yes</em></p>"},
+		{"unpaired backtick is text", "odd ` tick *e*", "<p>odd ` tick <em>e</em></p>"},
+		{"no emphasis inside href", "[l](http://a.io/*x*)", `<p><a href="http://a.io/*x*" rel="nofollow noopener noreferrer">l</a></p>`},
+		{"relative link", "[up](/algorithms/1)", `<p><a href="/algorithms/1" rel="nofollow noopener noreferrer">up</a></p>`},
+		{"mailto link", "[mail](mailto:a@b.io)", `<p><a href="mailto:a@b.io" rel="nofollow noopener noreferrer">mail</a></p>`},
+		{"parentheses in href", "[x](https://en.wikipedia.org/wiki/Tree_(graph_theory))",
+			`<p><a href="https://en.wikipedia.org/wiki/Tree_(graph_theory)" rel="nofollow noopener noreferrer">x</a></p>`},
+		{"nested parentheses in href", "[x](/a_(b_(c)))", `<p><a href="/a_(b_(c))" rel="nofollow noopener noreferrer">x</a></p>`},
+		{"link inside parentheses", "see ([x](/a_(b)))", `<p>see (<a href="/a_(b)" rel="nofollow noopener noreferrer">x</a>)</p>`},
+		{"unbalanced parenthesis is not a link", "[x](/a_(b)", "<p>[x](/a_(b)</p>"},
+		{"empty href is not a link", "[x]()", "<p>[x]()</p>"},
+		{"space ends a link", "[x](/a b)", "<p>[x](/a b)</p>"},
+		{"two links", "[a](/a) and [b](/b_(1))",
+			`<p><a href="/a" rel="nofollow noopener noreferrer">a</a> and <a href="/b_(1)" rel="nofollow noopener noreferrer">b</a></p>`},
+		{"unsafe link with parentheses drops whole href", "[x](javascript:alert(1)) after", "<p>x after</p>"},
+		{"crlf", "a\r\nb", "<p>a<br>\nb</p>"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(tt.source); got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderSanitizes(t *testing.T) {
+	tests := []struct {
+		name, source string
+		// Подстроки, которых не должно быть в результате (без учёта регистра)
+		forbidden []string
+	}{
+		{"script tag", "<script>alert(1)</script>", []string{"<script"}},
+		{"event handler", "<img src=x onerror=alert(1)>", []string{"<img"}},
+		{"javascript link", "[x](javascript:alert(1))", []string{"javascript:", "<a"}},
+		{"javascript link in mixed case", "[x](JavaScript:alert(1))", []string{"javascript:", "<a"}},
+		{"data link", "[x](data:text/html;base64,PHNjcmlwdD4=)", []string{"data:", "<a"}},
+		{"quote in href", "[x](http://a.io/\"onmouseover=alert(1))", []string{`"onmouseover`, `" onmouseover`}},
+		{"quote in fence language", "```js\"><script>\nx\n```", []string{"<script", `"><`}},
+		{"html in link text", "[<b>x</b>](http://a.io)", []string{"<b>"}},
+		{"nul byte", "[a](http://a.io) *\x00*", []string{"\x00"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.ToLower(Render(tt.source))
+			for _, bad := range tt.forbidden {
+				if strings.Contains(got, bad) {
+					t.Errorf("Render(%q) = %q contains %q", tt.source, got, bad)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderEscapesQuoteInHref(t *testing.T) {
+	got := Render("[x](http://a.io/\"onmouseover=alert(1))")
+	want := `<a href="http://a.io/&#34;onmouseover=alert(1)"`
+	if !strings.Contains(got, want) {
+		t.Errorf("Render = %q, want it to contain %q", got, want)
+	}
+}
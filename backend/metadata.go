@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"AlgorithmsOnlineLibrary/complexity"
+	"AlgorithmsOnlineLibrary/markdown"
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// checkMetadata проверяет метаданные из запроса и приводит их к хранимому виду: оценки
+// сложности нормализуются, предварительные требования очищаются от повторов. algorithmID — id
+// изменяемого алгоритма или 0 при создании. Возвращает HTTP-статус ошибки.
+func checkMetadata(ctx context.Context, change *algorithmChange, algorithmID, userID int, role string) (int, error) {
+	if change.Description != nil && utf8.RuneCountInString(*change.Description) > storage.MaxDescriptionLength {
+		return http.StatusBadRequest, fmt.Errorf("Description must be at most %d characters", storage.MaxDescriptionLength)
+	}
+	for _, expr := range []*string{change.TimeComplexity, change.SpaceComplexity} {
+		if expr == nil || strings.TrimSpace(*expr) == "" {
+			continue
+		}
+		normalized, _, err := complexity.Parse(*expr)
+		if err != nil {
+			return http.StatusBadRequest, err
+		}
+		*expr = normalized
+	}
+
+	if change.Prerequisites == nil {
+		return 0, nil
+	}
+	seen := map[int]bool{}
+	prerequisites := []int{}
+	for _, id := range change.Prerequisites {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if id == algorithmID {
+			return http.StatusBadRequest, fmt.Errorf("An algorithm cannot be its own prerequisite")
+		}
+		visible, err := canViewAlgorithm(ctx, userID, role, id)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		if !visible {
+			return http.StatusBadRequest, fmt.Errorf("Prerequisite %d not found", id)
+		}
+		prerequisites = append(prerequisites, id)
+	}
+	if len(prerequisites) > storage.MaxPrerequisites {
+		return http.StatusBadRequest, fmt.Errorf("An algorithm can have at most %d prerequisites", storage.MaxPrerequisites)
+	}
+	if algorithmID != 0 {
+		cycle, err := requires(ctx, prerequisites, algorithmID)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		if cycle {
+			return http.StatusBadRequest, fmt.Errorf("Prerequisites must not form a cycle")
+		}
+	}
+	change.Prerequisites = prerequisites
+	return 0, nil
+}
+
+// requires сообщает, ведёт ли цепочка предварительных требований от алгоритмов ids к target
+func requires(ctx context.Context, ids []int, target int) (bool, error) {
+	visited := map[int]bool{}
+	for len(ids) > 0 {
+		id := ids[0]
+		ids = ids[1:]
+		if id == target {
+			return true, nil
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+
+		algorithm, err := store.GetAlgorithm(ctx, id, false)
+		if err == storage.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		ids = append(ids, algorithm.Prerequisites...)
+	}
+	return false, nil
+}
+
+// applyMetadata переносит в algorithm метаданные, которые есть в запросе; отсутствующие остаются прежними
+func (change algorithmChange) applyMetadata(algorithm *Algorithm) {
+	if change.Description != nil {
+		algorithm.Description = *change.Description
+	}
+	if change.Difficulty != nil {
+		algorithm.Difficulty = *change.Difficulty
+	}
+	if change.TimeComplexity != nil {
+		algorithm.TimeComplexity = strings.TrimSpace(*change.TimeComplexity)
+	}
+	if change.SpaceComplexity != nil {
+		algorithm.SpaceComplexity = strings.TrimSpace(*change.SpaceComplexity)
+	}
+	if change.Prerequisites != nil {
+		algorithm.Prerequisites = change.Prerequisites
+	}
+}
+
+// withDescriptionHTML добавляет к алгоритму описание, отрисованное из Markdown
+func withDescriptionHTML(algorithm Algorithm) Algorithm {
+	if algorithm.Description != "" {
+		algorithm.DescriptionHTML = markdown.Render(algorithm.Description)
+	}
+	return algorithm
+}
+
+// parseMetadataFilter читает параметры поиска по метаданным: difficulty (уровень или число),
+// difficulty_min, difficulty_max, time_complexity, space_complexity, max_time_complexity,
+// max_space_complexity и prerequisite_id
+func parseMetadataFilter(params url.Values, search *storage.AlgorithmFilter) error {
+	if value := params.Get("difficulty"); value != "" {
+		min, max, err := storage.DifficultyRange(value)
+		if err != nil {
+			return err
+		}
+		search.DifficultyMin, search.DifficultyMax = min, max
+	}
+	// Уровень в границе диапазона берётся с той стороны, которая его включает: difficulty_max=medium — до 6
+	if value := params.Get("difficulty_min"); value != "" {
+		min, _, err := storage.DifficultyRange(value)
+		if err != nil {
+			return err
+		}
+		search.DifficultyMin = min
+	}
+	if value := params.Get("difficulty_max"); value != "" {
+		_, max, err := storage.DifficultyRange(value)
+		if err != nil {
+			return err
+		}
+		search.DifficultyMax = max
+	}
+
+	complexities := []struct {
+		param string
+		exact *string
+		max   **float64
+	}{
+		{"time_complexity", &search.TimeComplexity, nil},
+		{"space_complexity", &search.SpaceComplexity, nil},
+		{"max_time_complexity", nil, &search.MaxTimeRank},
+		{"max_space_complexity", nil, &search.MaxSpaceRank},
+	}
+	for _, c := range complexities {
+		value := params.Get(c.param)
+		if value == "" {
+			continue
+		}
+		normalized, rank, err := complexity.Parse(value)
+		if err != nil {
+			return fmt.Errorf("%s: %v", c.param, err)
+		}
+		if c.exact != nil {
+			*c.exact = normalized
+		} else {
+			*c.max = &rank
+		}
+	}
+
+	if value := params.Get("prerequisite_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			return fmt.Errorf("Invalid prerequisite_id parameter")
+		}
+		search.PrerequisiteID = id
+	}
+	return nil
+}
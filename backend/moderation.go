@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// Статусы модерации алгоритма (колонка moderation_status). Публично виден только approved.
+const (
+	moderationPending          = storage.ModerationPending
+	moderationApproved         = storage.ModerationApproved
+	moderationRejected         = storage.ModerationRejected
+	moderationChangesRequested = storage.ModerationChangesRequested
+)
+
+// canViewAlgorithm проверяет, что алгоритм существует и доступен пользователю.
+// Модераторы видят неодобренные алгоритмы, чтобы их можно было проверить.
+func canViewAlgorithm(ctx context.Context, userID int, role string, algorithmID int) (bool, error) {
+	algorithm, err := store.GetAlgorithm(ctx, algorithmID, false)
+	if err == storage.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return algorithm.Approved || algorithm.UserID == userID || hasPermission(role, permModerateAlgorithms), nil
+}
+
+// moderationEmail сообщает автору решение модератора
+func moderationEmail(author User, algorithm Algorithm, status, comment string) (*storage.OutboxEmail, error) {
+	return composeEmail(storage.EmailModeration, author, map[string]interface{}{
+		"AlgorithmID": algorithm.ID,
+		"Title":       algorithm.Title,
+		"Status":      status,
+		"Comment":     comment,
+	})
+}
+
+// GetModerationQueue возвращает алгоритмы, ожидающие проверки, начиная с самых старых
+func GetModerationQueue(w http.ResponseWriter, r *http.Request) {
+	queue, err := store.ListAlgorithms(r.Context(), storage.AlgorithmFilter{ModerationStatus: moderationPending, Sort: storage.SortOldest})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(queue)
+}
+
+// moderate возвращает обработчик решения модератора; для отказа и доработки комментарий обязателен
+func moderate(status string, commentRequired bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Context().Value("userID").(int)
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			http.Error(w, "Invalid ID parameter", http.StatusBadRequest)
+			return
+		}
+
+		var body struct {
+			Comment string `json:"comment"`
+			Reason  string `json:"reason"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		comment := body.Comment
+		if comment == "" {
+			comment = body.Reason
+		}
+		if commentRequired && comment == "" {
+			http.Error(w, "A reason must be provided", http.StatusBadRequest)
+			return
+		}
+
+		algorithm, author, err := store.ModerateAlgorithm(r.Context(), id, userID, status, comment)
+		if err == storage.ErrNotFound {
+			http.Error(w, "Algorithm not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// Решение уже сохранено: письмо, которое не удалось поставить в очередь, не должно его отменять
+		email, err := moderationEmail(author, algorithm, status, comment)
+		if err == nil {
+			err = store.EnqueueEmail(r.Context(), email)
+		}
+		if err != nil {
+			log.Printf("Error queueing moderation email for algorithm %d: %v", id, err)
+		}
+		notifyMailer()
+
+		json.NewEncoder(w).Encode(algorithm)
+	}
+}
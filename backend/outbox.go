@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"AlgorithmsOnlineLibrary/mailer"
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// Обработчики не отправляют письма сами: письмо записывается в email_outbox в той же транзакции,
+// что и данные, о которых оно сообщает, а доставляет его фоновый обработчик с повторами.
+
+const (
+	// Письмо, взятое в отправку, скрыто от других попыток на это время. Если сервер упадёт
+	// посреди отправки, письмо возьмут снова, когда аренда истечёт.
+	emailLease = 10 * time.Minute
+	// Сколько писем брать из очереди за раз
+	emailBatchSize = 20
+	// Сколько писем показывать администратору
+	emailListLimit = 100
+	// Как часто удалять старые отправленные письма
+	emailPurgeInterval = time.Hour
+)
+
+var mailerWakeup = make(chan struct{}, 1)
+
+// notifyMailer будит обработчик очереди, чтобы новое письмо ушло сразу, а не при следующей проверке
+func notifyMailer() {
+	select {
+	case mailerWakeup <- struct{}{}:
+	default:
+	}
+}
+
+func startMailer() {
+	go func() {
+		ticker := time.NewTicker(time.Duration(cfg.Mail.PollInterval))
+		defer ticker.Stop()
+		var purgedAt time.Time
+		for {
+			if time.Since(purgedAt) >= emailPurgeInterval {
+				purgeSentEmails()
+				purgedAt = time.Now()
+			}
+			deliverDueEmails()
+			select {
+			case <-ticker.C:
+			case <-mailerWakeup:
+			}
+		}
+	}()
+}
+
+// deliverDueEmails отправляет письма, срок которых наступил, пока они не кончатся
+func deliverDueEmails() {
+	ctx := context.Background()
+	for {
+		now := time.Now()
+		emails, err := store.ClaimEmails(ctx, now, now.Add(emailLease), emailBatchSize)
+		if err != nil {
+			log.Println("Error claiming emails:", err)
+			return
+		}
+		for _, email := range emails {
+			deliverEmail(ctx, email)
+		}
+		if len(emails) < emailBatchSize {
+			return
+		}
+	}
+}
+
+func deliverEmail(ctx context.Context, email storage.OutboxEmail) {
+	err := sendEmail(ctx, email)
+	if err == nil {
+		if err := store.MarkEmailSent(ctx, email.ID, time.Now()); err != nil {
+			log.Printf("Error marking email %d as sent: %v", email.ID, err)
+		}
+		return
+	}
+
+	var retryAt *time.Time
+	if email.Attempts < cfg.Mail.MaxAttempts {
+		at := time.Now().Add(retryDelay(email.Attempts))
+		retryAt = &at
+		log.Printf("Failed to send email %d (attempt %d), retrying at %s: %v", email.ID, email.Attempts, at.Format(time.RFC3339), err)
+	} else {
+		log.Printf("Failed to send email %d, giving up after %d attempts: %v", email.ID, email.Attempts, err)
+	}
+	if err := store.MarkEmailFailed(ctx, email.ID, err.Error(), retryAt); err != nil {
+		log.Printf("Error saving failure of email %d: %v", email.ID, err)
+	}
+}
+
+// retryDelay — пауза после attempts неудачных попыток: retry_delay после первой, дальше вдвое
+// больше после каждой, но не больше max_retry_delay
+func retryDelay(attempts int) time.Duration {
+	delay, limit := time.Duration(cfg.Mail.RetryDelay), time.Duration(cfg.Mail.MaxRetryDelay)
+	for i := 1; i < attempts && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+func sendEmail(ctx context.Context, email storage.OutboxEmail) error {
+	if mailSender == nil {
+		return mailer.ErrNoHost
+	}
+	return mailSender.Send(ctx, mailer.Message{From: cfg.SMTP.From, To: email.Recipient, Subject: email.Subject, Text: email.Body,
+		HTML: email.HTMLBody})
+}
+
+func purgeSentEmails() {
+	purged, err := store.PurgeSentEmails(context.Background(), time.Now().Add(-time.Duration(cfg.Mail.Retention)))
+	if err != nil {
+		log.Println("Error purging sent emails:", err)
+		return
+	}
+	if purged > 0 {
+		log.Println("Purged sent emails:", purged)
+	}
+}
+
+// GetEmails показывает администратору последние письма очереди, в параметре status можно
+// выбрать pending, sent или dead
+func GetEmails(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", storage.EmailPending, storage.EmailSent, storage.EmailDead:
+	default:
+		http.Error(w, "status must be one of pending, sent, dead", http.StatusBadRequest)
+		return
+	}
+
+	emails, err := store.ListEmails(r.Context(), status, emailListLimit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(emails)
+}
+
+// ResendEmail возвращает брошенное письмо в очередь с новым запасом попыток. Отправленные письма
+// не переотправляются: их тела стёрты, а ссылки из них пользователь может запросить заново.
+func ResendEmail(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid ID parameter", http.StatusBadRequest)
+		return
+	}
+
+	email, err := store.ResendEmail(r.Context(), id, time.Now())
+	if err == storage.ErrNotFound {
+		if email, err := store.GetEmail(r.Context(), id); err == nil {
+			if email.Status == storage.EmailSent {
+				http.Error(w, "Email has already been sent and its body erased", http.StatusConflict)
+				return
+			}
+			http.Error(w, "Email is already queued for delivery", http.StatusConflict)
+			return
+		}
+		http.Error(w, "Email not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	notifyMailer()
+	json.NewEncoder(w).Encode(email)
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"AlgorithmsOnlineLibrary/mailer"
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+var verifyLinkToken = regexp.MustCompile(`verify-email\?token=([0-9a-f]+)`)
+
+func TestSentEmailKeepsNoToken(t *testing.T) {
+	for _, driver := range testDrivers {
+		setupTest(t)
+		useStore(t, driver)
+
+		body := `{"username": "alice", "password": "secret-password", "email": "alice@example.com"}`
+		rec := httptest.NewRecorder()
+		Register(rec, httptest.NewRequest("POST", "/register", strings.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: register: %d %s", driver, rec.Code, rec.Body)
+		}
+
+		ctx := context.Background()
+		queued, err := store.ListEmails(ctx, storage.EmailPending, 10)
+		if err != nil || len(queued) != 1 {
+			t.Fatalf("%s: pending emails %v, %v", driver, queued, err)
+		}
+		// До отправки тело нужно обработчику очереди
+		if !verifyLinkToken.MatchString(queued[0].Body) {
+			t.Fatalf("%s: queued body has no verification link: %q", driver, queued[0].Body)
+		}
+
+		deliverDueEmails()
+
+		messages := devMailbox.Messages("alice@example.com")
+		if len(messages) != 1 {
+			t.Fatalf("%s: delivered %d messages, want 1", driver, len(messages))
+		}
+		match := verifyLinkToken.FindStringSubmatch(messages[0].Text)
+		if match == nil {
+			t.Fatalf("%s: delivered message has no verification link: %q", driver, messages[0].Text)
+		}
+		token := match[1]
+
+		email, err := store.GetEmail(ctx, queued[0].ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if email.Status != storage.EmailSent {
+			t.Errorf("%s: status %s, want sent", driver, email.Status)
+		}
+		for name, text := range map[string]string{"body": email.Body, "html_body": email.HTMLBody, "subject": email.Subject} {
+			if strings.Contains(text, token) {
+				t.Errorf("%s: %s of the sent email still contains the token", driver, name)
+			}
+		}
+		if email.Body != "" || email.HTMLBody != "" {
+			t.Errorf("%s: sent email body not erased: %q, %q", driver, email.Body, email.HTMLBody)
+		}
+	}
+}
+
+func TestResendEmail(t *testing.T) {
+	for _, driver := range testDrivers {
+		setupTest(t)
+		useStore(t, driver)
+		ctx := context.Background()
+
+		enqueue := func() int {
+			email := storage.OutboxEmail{Kind: storage.EmailVerification, Recipient: "alice@example.com", Subject: "Hello", Body: "body"}
+			if err := store.EnqueueEmail(ctx, &email); err != nil {
+				t.Fatal(err)
+			}
+			return email.ID
+		}
+		sent := enqueue()
+		deliverDueEmails()
+		dead := enqueue()
+		if err := store.MarkEmailFailed(ctx, dead, "mailbox unavailable", nil); err != nil {
+			t.Fatal(err)
+		}
+		pending := enqueue()
+
+		tests := []struct {
+			name     string
+			id       int
+			wantCode int
+		}{
+			{"dead", dead, http.StatusOK},
+			{"sent", sent, http.StatusConflict},
+			{"pending", pending, http.StatusConflict},
+			{"missing", 1000, http.StatusNotFound},
+		}
+		for _, tt := range tests {
+			rec := httptest.NewRecorder()
+			req := mux.SetURLVars(httptest.NewRequest("POST", "/", nil), map[string]string{"id": strconv.Itoa(tt.id)})
+			ResendEmail(rec, req)
+			if rec.Code != tt.wantCode {
+				t.Errorf("%s, %s: got %d %s, want %d", driver, tt.name, rec.Code, rec.Body, tt.wantCode)
+			}
+		}
+
+		email, err := store.GetEmail(ctx, dead)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if email.Status != storage.EmailPending || email.Attempts != 0 || email.Body != "body" {
+			t.Errorf("%s: resent email = %+v", driver, email)
+		}
+	}
+}
+
+// failingMailer отклоняет все письма, как недоступный SMTP-сервер
+type failingMailer struct{}
+
+func (failingMailer) Send(ctx context.Context, message mailer.Message) error {
+	return errors.New("dial tcp: connection refused")
+}
+
+func (failingMailer) Close() error { return nil }
+
+func TestRetryDelay(t *testing.T) {
+	setupTest(t)
+	cfg.Mail.RetryDelay = Duration(time.Minute)
+	cfg.Mail.MaxRetryDelay = Duration(time.Hour)
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{6, 32 * time.Minute},
+		{7, time.Hour},
+		{100, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(tt.attempts); got != tt.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestFailedEmailRetriesThenDies(t *testing.T) {
+	for _, driver := range testDrivers {
+		setupTest(t)
+		useStore(t, driver)
+		cfg.Mail.MaxAttempts = 3
+		mailSender = failingMailer{}
+		ctx := context.Background()
+
+		email := storage.OutboxEmail{Kind: storage.EmailVerification, Recipient: "alice@example.com", Subject: "Hello", Body: "body"}
+		if err := store.EnqueueEmail(ctx, &email); err != nil {
+			t.Fatal(err)
+		}
+
+		// Очередь проверяется сразу после срока следующей попытки, не дожидаясь его на самом деле
+		at := time.Now()
+		for attempt := 1; attempt <= cfg.Mail.MaxAttempts; attempt++ {
+			claimed, err := store.ClaimEmails(ctx, at, at.Add(emailLease), emailBatchSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(claimed) != 1 || claimed[0].Attempts != attempt {
+				t.Fatalf("%s: attempt %d claimed %+v", driver, attempt, claimed)
+			}
+			before := time.Now()
+			deliverEmail(ctx, claimed[0])
+
+			got, err := store.GetEmail(ctx, email.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.LastError != "dial tcp: connection refused" {
+				t.Errorf("%s: attempt %d: last error %q", driver, attempt, got.LastError)
+			}
+			if attempt == cfg.Mail.MaxAttempts {
+				if got.Status != storage.EmailDead {
+					t.Errorf("%s: status after the last attempt %s, want dead", driver, got.Status)
+				}
+				break
+			}
+			wait := got.NextAttemptAt.Sub(before)
+			if got.Status != storage.EmailPending || wait < retryDelay(attempt)-time.Second || wait > retryDelay(attempt)+time.Second {
+				t.Errorf("%s: attempt %d: status %s, next attempt in %v, want pending in %v", driver, attempt, got.Status, wait,
+					retryDelay(attempt))
+			}
+			at = got.NextAttemptAt
+		}
+
+		// Брошенное письмо больше не берётся, даже когда отправка снова работает
+		mailSender = devMailbox
+		if claimed, err := store.ClaimEmails(ctx, at.Add(24*time.Hour), at.Add(25*time.Hour), emailBatchSize); err != nil || len(claimed) != 0 {
+			t.Errorf("%s: dead email claimed again: %+v, %v", driver, claimed, err)
+		}
+	}
+}
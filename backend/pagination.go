@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// Размер страницы списков алгоритмов, если limit не указан, и наибольший допустимый
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// algorithmPage — ответ списочных эндпоинтов. next_cursor пуст на последней странице,
+// total есть только при include_total=true, потому что COUNT по большой выборке недёшев.
+type algorithmPage struct {
+	Items      []json.RawMessage `json:"items"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+	Total      *int              `json:"total,omitempty"`
+}
+
+// pageRequest — параметры страницы из query string: limit, cursor, include_total и fields
+type pageRequest struct {
+	limit        int
+	after        *storage.Cursor
+	includeTotal bool
+	fields       []string
+}
+
+// algorithmFields — имена полей алгоритма в JSON, допустимые в параметре fields
+var algorithmFields = jsonFieldNames(reflect.TypeOf(Algorithm{}))
+
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// parsePageRequest разбирает параметры страницы; sort — порядок выборки, с которым должен совпадать курсор
+func parsePageRequest(r *http.Request, sort string) (pageRequest, error) {
+	params := r.URL.Query()
+	page := pageRequest{limit: defaultPageLimit}
+
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return page, fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+		}
+		page.limit = limit
+	}
+
+	if value := params.Get("cursor"); value != "" {
+		cursor, err := storage.DecodeCursor(value)
+		if err != nil {
+			return page, err
+		}
+		// Курсор хранит ключ сортировки, с другим порядком он указывал бы не туда
+		if cursor.Sort != sort {
+			return page, fmt.Errorf("cursor does not match the sort order")
+		}
+		page.after = &cursor
+	}
+
+	page.includeTotal, _ = strconv.ParseBool(params.Get("include_total"))
+
+	if value := params.Get("fields"); value != "" {
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			if !algorithmFields[field] {
+				return page, fmt.Errorf("unknown field %q", field)
+			}
+			page.fields = append(page.fields, field)
+		}
+	}
+	return page, nil
+}
+
+// apply переносит страницу в фильтр. Запрашивается на один алгоритм больше,
+// чтобы без отдельного запроса узнать, есть ли следующая страница.
+func (page pageRequest) apply(filter *storage.AlgorithmFilter) {
+	filter.Limit = page.limit + 1
+	filter.After = page.after
+	if page.fields != nil && !contains(page.fields, "code") && !contains(page.fields, "description") {
+		filter.OmitCode = true
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// listAlgorithmsPage выбирает страницу алгоритмов по фильтру и пишет её в ответ
+func listAlgorithmsPage(w http.ResponseWriter, r *http.Request, filter storage.AlgorithmFilter) {
+	page, err := parsePageRequest(r, filter.Sort)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	page.apply(&filter)
+
+	algorithms, err := store.ListAlgorithms(r.Context(), filter)
+	if err != nil {
+		http.Error(w, "Error fetching algorithms", http.StatusInternalServerError)
+		return
+	}
+
+	response := algorithmPage{Items: []json.RawMessage{}}
+	if len(algorithms) > page.limit {
+		algorithms = algorithms[:page.limit]
+		response.NextCursor = storage.CursorAt(algorithms[len(algorithms)-1], filter.Sort).Encode()
+	}
+
+	for _, algorithm := range algorithms {
+		item, err := selectFields(algorithm, page.fields)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		response.Items = append(response.Items, item)
+	}
+
+	if page.includeTotal {
+		total, err := store.CountAlgorithms(r.Context(), filter)
+		if err != nil {
+			http.Error(w, "Error counting algorithms", http.StatusInternalServerError)
+			return
+		}
+		response.Total = &total
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// selectFields оставляет в JSON алгоритма только перечисленные поля; без fields отдаёт все
+func selectFields(algorithm Algorithm, fields []string) (json.RawMessage, error) {
+	data, err := json.Marshal(algorithm)
+	if err != nil || fields == nil {
+		return data, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+
+	selected := map[string]json.RawMessage{}
+	for _, field := range fields {
+		if value, ok := all[field]; ok {
+			selected[field] = value
+		}
+	}
+	return json.Marshal(selected)
+}
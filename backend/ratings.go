@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// ratableAlgorithm проверяет, что пользователь может оценить алгоритм: оценивать можно только
+// опубликованные чужие алгоритмы. При отказе ответ уже записан в w.
+func ratableAlgorithm(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid ID parameter", http.StatusBadRequest)
+		return 0, false
+	}
+
+	algorithm, err := store.GetAlgorithm(r.Context(), id, false)
+	if err == storage.ErrNotFound {
+		http.Error(w, "Algorithm not found", http.StatusNotFound)
+		return 0, false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return 0, false
+	}
+
+	userID := r.Context().Value("userID").(int)
+	if !algorithm.Approved {
+		if algorithm.UserID != userID && !hasPermission(roleFromContext(r), permModerateAlgorithms) {
+			http.Error(w, "Algorithm not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Only approved algorithms can be rated", http.StatusConflict)
+		}
+		return 0, false
+	}
+	if algorithm.UserID == userID {
+		http.Error(w, "You cannot rate your own algorithm", http.StatusForbidden)
+		return 0, false
+	}
+	return id, true
+}
+
+// RateAlgorithm ставит оценку от 1 до 5; повторный запрос заменяет прежнюю оценку пользователя
+func RateAlgorithm(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Score int `json:"score"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.Score < storage.MinRatingScore || request.Score > storage.MaxRatingScore {
+		http.Error(w, fmt.Sprintf("Score must be between %d and %d", storage.MinRatingScore, storage.MaxRatingScore), http.StatusBadRequest)
+		return
+	}
+
+	id, ok := ratableAlgorithm(w, r)
+	if !ok {
+		return
+	}
+
+	userID := r.Context().Value("userID").(int)
+	summary, err := store.RateAlgorithm(r.Context(), id, userID, request.Score, time.Now())
+	if err == storage.ErrNotFound {
+		http.Error(w, "Algorithm not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(struct {
+		storage.RatingSummary
+		MyRating int `json:"my_rating"`
+	}{summary, request.Score})
+}
+
+// DeleteRating снимает оценку пользователя
+func DeleteRating(w http.ResponseWriter, r *http.Request) {
+	id, ok := ratableAlgorithm(w, r)
+	if !ok {
+		return
+	}
+
+	userID := r.Context().Value("userID").(int)
+	summary, err := store.DeleteRating(r.Context(), id, userID)
+	if err == storage.ErrNotFound {
+		http.Error(w, "Rating not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(summary)
+}
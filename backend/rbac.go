@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// Роли пользователей (колонка users.role)
+const (
+	roleUser      = storage.DefaultRole
+	roleModerator = "moderator"
+	roleAdmin     = "admin"
+)
+
+// Permission — отдельное действие, которое может быть разрешено роли
+type Permission string
+
+const (
+	// Просмотр неодобренных алгоритмов и решения по очереди модерации
+	permModerateAlgorithms Permission = "algorithms:moderate"
+	// Удаление, восстановление и откат чужих алгоритмов
+	permManageAnyAlgorithm Permission = "algorithms:manage-any"
+	// Управление пользователями: роли, смена пароля
+	permManageUsers Permission = "users:manage"
+	// Удаление чужих комментариев
+	permModerateComments Permission = "comments:moderate"
+)
+
+var rolePermissions = map[string][]Permission{
+	roleUser:      {},
+	roleModerator: {permModerateAlgorithms, permModerateComments},
+	roleAdmin:     {permModerateAlgorithms, permModerateComments, permManageAnyAlgorithm, permManageUsers},
+}
+
+func validRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+func hasPermission(role string, permission Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// roleFromContext возвращает роль, которую Authenticate положил в контекст из Claims
+func roleFromContext(r *http.Request) string {
+	role, _ := r.Context().Value("role").(string)
+	return role
+}
+
+// forbidden — единый ответ для запросов без нужных прав
+func forbidden(w http.ResponseWriter) {
+	http.Error(w, "Forbidden", http.StatusForbidden)
+}
+
+// RequireRole пропускает запрос, только если роль пользователя входит в roles.
+// Должен стоять после Authenticate.
+func RequireRole(roles ...string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role := roleFromContext(r)
+			for _, allowed := range roles {
+				if role == allowed {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			forbidden(w)
+		})
+	}
+}
+
+// RequirePermission пропускает запрос, только если роли пользователя выданы все permissions.
+// Должен стоять после Authenticate.
+func RequirePermission(permissions ...Permission) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role := roleFromContext(r)
+			for _, permission := range permissions {
+				if !hasPermission(role, permission) {
+					forbidden(w)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// GetUsers возвращает список пользователей для админки
+func GetUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := store.ListUsers(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(users)
+}
+
+// SetUserRole повышает или понижает роль пользователя. Менять собственную роль нельзя,
+// чтобы админ случайно не лишил себя доступа.
+func SetUserRole(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid ID parameter", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Role string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !validRole(body.Role) {
+		http.Error(w, "Unknown role", http.StatusBadRequest)
+		return
+	}
+
+	if id == r.Context().Value("userID").(int) {
+		http.Error(w, "You cannot change your own role", http.StatusBadRequest)
+		return
+	}
+
+	user, err := store.SetRole(r.Context(), id, body.Role)
+	if err == storage.ErrNotFound {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Роль хранится в токене, поэтому пользователь должен войти заново, чтобы она применилась
+	if err := store.RevokeUserSessions(r.Context(), user.ID, time.Now()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(user)
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+type Revision = storage.Revision
+
+// Тело запроса на изменение алгоритма: сам алгоритм и комментарий к правке. Метаданные
+// перекрывают одноимённые поля Algorithm указателями, чтобы отличить отсутствующее поле от пустого.
+type algorithmChange struct {
+	Algorithm
+	Message         string              `json:"message"`
+	Description     *string             `json:"description"`
+	Difficulty      *storage.Difficulty `json:"difficulty"`
+	TimeComplexity  *string             `json:"time_complexity"`
+	SpaceComplexity *string             `json:"space_complexity"`
+	// Принять предложения анализатора для метаданных, которых нет в запросе
+	AcceptAnalysis bool `json:"accept_analysis"`
+}
+
+func parseRevisionVars(r *http.Request) (algorithmID int, revision int, err error) {
+	vars := mux.Vars(r)
+	algorithmID, err = strconv.Atoi(vars["id"])
+	if err != nil {
+		return 0, 0, errors.New("Invalid ID parameter")
+	}
+	if revStr, ok := vars["revision"]; ok {
+		revision, err = strconv.Atoi(revStr)
+		if err != nil {
+			return 0, 0, errors.New("Invalid revision parameter")
+		}
+	}
+	return algorithmID, revision, nil
+}
+
+// GetRevisions возвращает историю ревизий алгоритма без кода, от новых к старым
+func GetRevisions(w http.ResponseWriter, r *http.Request) {
+	algorithmID, _, err := parseRevisionVars(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	visible, err := canViewAlgorithm(r.Context(), r.Context().Value("userID").(int), roleFromContext(r), algorithmID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !visible {
+		http.Error(w, "Algorithm not found", http.StatusNotFound)
+		return
+	}
+
+	revisions, err := store.ListRevisions(r.Context(), algorithmID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(revisions)
+}
+
+// GetRevision возвращает ревизию алгоритма целиком, вместе с кодом
+func GetRevision(w http.ResponseWriter, r *http.Request) {
+	algorithmID, revision, err := parseRevisionVars(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	visible, err := canViewAlgorithm(r.Context(), r.Context().Value("userID").(int), roleFromContext(r), algorithmID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !visible {
+		http.Error(w, "Algorithm not found", http.StatusNotFound)
+		return
+	}
+
+	rev, err := store.GetRevision(r.Context(), algorithmID, revision)
+	if err == storage.ErrNotFound {
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(rev)
+}
+
+// GetRevisionDiff возвращает unified diff кода между ревизиями from и to
+func GetRevisionDiff(w http.ResponseWriter, r *http.Request) {
+	algorithmID, _, err := parseRevisionVars(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	visible, err := canViewAlgorithm(r.Context(), r.Context().Value("userID").(int), roleFromContext(r), algorithmID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !visible {
+		http.Error(w, "Algorithm not found", http.StatusNotFound)
+		return
+	}
+
+	params := r.URL.Query()
+	from, errFrom := strconv.Atoi(params.Get("from"))
+	to, errTo := strconv.Atoi(params.Get("to"))
+	if errFrom != nil || errTo != nil {
+		http.Error(w, "Both from and to revision numbers must be provided", http.StatusBadRequest)
+		return
+	}
+
+	var revs [2]Revision
+	for i, number := range []int{from, to} {
+		revs[i], err = store.GetRevision(r.Context(), algorithmID, number)
+		if err == storage.ErrNotFound {
+			http.Error(w, fmt.Sprintf("Revision %d not found", number), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"algorithm_id": algorithmID,
+		"from":         from,
+		"to":           to,
+		"diff":         unifiedDiff(fmt.Sprintf("revision %d", from), fmt.Sprintf("revision %d", to), revs[0].Code, revs[1].Code),
+	})
+}
+
+// RollbackAlgorithm восстанавливает содержимое старой ревизии. История не переписывается:
+// откат записывается как новая ревизия.
+func RollbackAlgorithm(w http.ResponseWriter, r *http.Request) {
+	algorithmID, revision, err := parseRevisionVars(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if body.Message == "" {
+		body.Message = fmt.Sprintf("Rollback to revision %d", revision)
+	}
+
+	userID := r.Context().Value("userID").(int)
+
+	allowed, found, err := canManageAlgorithm(r.Context(), userID, roleFromContext(r), algorithmID, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Algorithm not found", http.StatusNotFound)
+		return
+	}
+	if !allowed {
+		forbidden(w)
+		return
+	}
+
+	target, err := store.GetRevision(r.Context(), algorithmID, revision)
+	if err == storage.ErrNotFound {
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Категория ревизии могла быть удалена, тогда алгоритм остаётся в текущей; имя берётся
+	// нынешнее, а не записанное в ревизии
+	var category *storage.Category
+	if target.CategoryID != nil {
+		found, err := store.GetCategory(r.Context(), *target.CategoryID)
+		if err != nil && err != storage.ErrNotFound {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err == nil {
+			category = &found
+		}
+	}
+
+	analysis := analyzeCode(r.Context(), target.Code, target.ProgrammingLanguage)
+
+	algorithm, newRevision, err := store.ReviseAlgorithm(r.Context(), algorithmID, userID, body.Message, func(current *Algorithm) error {
+		current.Title = target.Title
+		current.Code = target.Code
+		current.Analysis = analysis
+		if category != nil {
+			current.CategoryID, current.Topic = &category.ID, category.Name
+		}
+		current.ProgrammingLanguage = target.ProgrammingLanguage
+		return nil
+	})
+	if err == storage.ErrNotFound {
+		http.Error(w, "Algorithm not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	scheduleVerification(algorithmID)
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   fmt.Sprintf("Rolled back to revision %d", revision),
+		"revision":  newRevision,
+		"algorithm": algorithm,
+	})
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"AlgorithmsOnlineLibrary/sandbox"
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// runner выполняет код алгоритмов; nil, если запуск кода отключён (sandbox.workers: 0)
+var runner *sandbox.Runner
+
+func startSandbox() {
+	if cfg.Sandbox.Workers == 0 {
+		log.Println("Running code is disabled")
+		return
+	}
+	var err error
+	runner, err = sandbox.New(sandbox.Config{
+		Workers:          cfg.Sandbox.Workers,
+		QueueSize:        cfg.Sandbox.QueueSize,
+		TimeLimit:        time.Duration(cfg.Sandbox.TimeLimit),
+		CompileTimeLimit: time.Duration(cfg.Sandbox.CompileTimeLimit),
+		MemoryLimit:      int64(cfg.Sandbox.MemoryLimitMB) << 20,
+		OutputLimit:      cfg.Sandbox.OutputLimitKB << 10,
+		Dir:              cfg.Sandbox.Dir,
+		UID:              cfg.Sandbox.UID,
+		GID:              cfg.Sandbox.GID,
+		Go:               cfg.Sandbox.Go,
+		Python:           cfg.Sandbox.Python,
+		Cxx:              cfg.Sandbox.Cxx,
+	})
+	// Без песочницы сервер полезен и так, поэтому её ошибка не мешает запуску
+	if err != nil {
+		log.Println("WARNING: running code is disabled:", err)
+		return
+	}
+	log.Println("Code can be run in:", runner.Languages())
+}
+
+// RunAlgorithm выполняет текущий код алгоритма со stdin из запроса
+func RunAlgorithm(w http.ResponseWriter, r *http.Request) {
+	if runner == nil {
+		http.Error(w, "Running code is disabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid ID parameter", http.StatusBadRequest)
+		return
+	}
+
+	var request struct {
+		Stdin string `json:"stdin"`
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, int64(cfg.Sandbox.StdinLimitKB)<<10+1024)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "Stdin is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(request.Stdin) > cfg.Sandbox.StdinLimitKB<<10 {
+		http.Error(w, "Stdin is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	userID := r.Context().Value("userID").(int)
+	visible, err := canViewAlgorithm(r.Context(), userID, roleFromContext(r), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !visible {
+		http.Error(w, "Algorithm not found", http.StatusNotFound)
+		return
+	}
+	algorithm, err := store.GetAlgorithm(r.Context(), id, false)
+	if err == storage.ErrNotFound {
+		http.Error(w, "Algorithm not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result, err := runner.Run(r.Context(), algorithm.ProgrammingLanguage, algorithm.Code, request.Stdin)
+	switch {
+	case errors.Is(err, sandbox.ErrUnsupported):
+		http.Error(w, "Running "+algorithm.ProgrammingLanguage+" code is not supported", http.StatusUnprocessableEntity)
+		return
+	case errors.Is(err, sandbox.ErrBusy):
+		w.Header().Set("Retry-After", "5")
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	case r.Context().Err() != nil:
+		// Клиент ушёл, отвечать некому
+		return
+	case err != nil:
+		log.Printf("Error running algorithm %d: %v", id, err)
+		http.Error(w, "Failed to run the code", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,302 @@
+package sandbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Этих констант нет в пакете syscall; значения одинаковы для amd64 и arm64
+const (
+	rlimitNproc     = 6
+	prSetNoNewPrivs = 38
+)
+
+// Флаги statfs и соответствующие им флаги mount. В user namespace флаги, с которыми каталог
+// смонтирован на хосте, снять нельзя, поэтому привязка перемонтируется с ними же.
+var lockedFlags = []struct {
+	statfs int64
+	mount  uintptr
+}{
+	{1, syscall.MS_RDONLY},
+	{4, syscall.MS_NODEV},
+	{8, syscall.MS_NOEXEC},
+	{1024, syscall.MS_NOATIME},
+	{2048, syscall.MS_NODIRATIME},
+	{4096, syscall.MS_RELATIME},
+}
+
+// Exec выполняется в дочернем процессе песочницы с аргументами
+// <секунды процессора> <байты памяти> <байты файла> <процессы> <корень в JSON> -- <команда>:
+// переходит в корень песочницы, выставляет ограничения и заменяет процесс командой.
+// Возвращается только при ошибке, после чего main должен завершиться с кодом ExitCode.
+func Exec(args []string) error {
+	if len(args) < 7 || args[5] != "--" {
+		return fmt.Errorf("%s: usage: %s <cpu> <memory> <file size> <processes> <layout> -- command", ExecCommand, ExecCommand)
+	}
+	var values [4]uint64
+	for i := range values {
+		value, err := strconv.ParseUint(args[i], 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %w", ExecCommand, err)
+		}
+		values[i] = value
+	}
+	var root layout
+	if err := json.Unmarshal([]byte(args[4]), &root); err != nil {
+		return fmt.Errorf("%s: layout: %w", ExecCommand, err)
+	}
+
+	// Ограничивающий набор возможностей и no_new_privs относятся к потоку, поэтому их выставляет
+	// тот же поток, который потом вызовет execve
+	runtime.LockOSThread()
+	if err := enter(root); err != nil {
+		return fmt.Errorf("%s: %w", ExecCommand, err)
+	}
+
+	limits := []struct {
+		resource   int
+		soft, hard uint64
+	}{
+		// После мягкого предела по процессору приходит SIGXCPU, после жёсткого — SIGKILL
+		{syscall.RLIMIT_CPU, values[0], values[0] + 1},
+		// Не RLIMIT_AS: среда выполнения Go заранее резервирует гигабайты адресного пространства
+		// и с таким ограничением не запускается. RLIMIT_DATA учитывает только память, в которую можно писать.
+		{syscall.RLIMIT_DATA, values[1], values[1]},
+		{syscall.RLIMIT_FSIZE, values[2], values[2]},
+		{syscall.RLIMIT_CORE, 0, 0},
+		{syscall.RLIMIT_NOFILE, 64, 64},
+		// Без этого ограничения fork-бомба исчерпала бы процессы хоста
+		{rlimitNproc, values[3], values[3]},
+	}
+	for _, limit := range limits {
+		if err := syscall.Setrlimit(limit.resource, &syscall.Rlimit{Cur: limit.soft, Max: limit.hard}); err != nil {
+			return fmt.Errorf("%s: setrlimit %d: %w", ExecCommand, limit.resource, err)
+		}
+	}
+	if err := dropCapabilities(); err != nil {
+		return fmt.Errorf("%s: %w", ExecCommand, err)
+	}
+
+	argv := args[6:]
+	path, err := exec.LookPath(argv[0])
+	if err != nil {
+		return fmt.Errorf("%s: %w", ExecCommand, err)
+	}
+	return fmt.Errorf("%s: %w", ExecCommand, syscall.Exec(path, argv, os.Environ()))
+}
+
+func checkPlatform() error {
+	return nil
+}
+
+// isolation запускает процесс в новых namespace: без сети, без доступа к процессам и IPC сервера
+// и с собственными точками монтирования, из которых Exec собирает корень песочницы. В своём user
+// namespace процесс — root, но на хосте это пользователь сервера, а если сервер работает под root,
+// то Config.UID.
+func (r *Runner) isolation() *syscall.SysProcAttr {
+	uid, gid := os.Getuid(), os.Getgid()
+	attr := &syscall.SysProcAttr{
+		Cloneflags: syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWNET | syscall.CLONE_NEWPID |
+			syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS,
+		// Если сервер упадёт, запуск не переживёт его
+		Pdeathsig: syscall.SIGKILL,
+	}
+	if uid == 0 {
+		uid, gid = r.config.UID, r.config.GID
+		// Дополнительные группы root запуску не достаются
+		attr.Credential = &syscall.Credential{}
+		attr.GidMappingsEnableSetgroups = true
+	}
+	attr.UidMappings = []syscall.SysProcIDMap{{ContainerID: 0, HostID: uid, Size: 1}}
+	attr.GidMappings = []syscall.SysProcIDMap{{ContainerID: 0, HostID: gid, Size: 1}}
+	return attr
+}
+
+// enter собирает корень песочницы и переходит в него: монтирует пустой tmpfs в l.Root, привязывает
+// к нему каталоги из l по тем же путям, что и на хосте, и делает его корнем через pivot_root.
+// Прежний корень отсоединяется, так что остальные файлы хоста из песочницы не видны.
+func enter(l layout) error {
+	// Иначе монтирования из песочницы распространились бы на хост
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("make mounts private: %w", err)
+	}
+	if err := syscall.Mount("tmpfs", l.Root, "tmpfs", syscall.MS_NOSUID|syscall.MS_NODEV, "size=1m,mode=755"); err != nil {
+		return fmt.Errorf("mount root: %w", err)
+	}
+	for _, path := range l.ReadOnly {
+		if err := bind(l.Root, path, false); err != nil {
+			return err
+		}
+	}
+	if err := bind(l.Root, l.Dir, true); err != nil {
+		return err
+	}
+	if len(l.Overlays) > 0 {
+		if err := overlay(l.Root, l.Overlays); err != nil {
+			return err
+		}
+	}
+
+	if err := syscall.Chdir(l.Root); err != nil {
+		return err
+	}
+	if err := syscall.PivotRoot(".", "."); err != nil {
+		return fmt.Errorf("pivot_root: %w", err)
+	}
+	// Прежний корень теперь лежит поверх нового
+	if err := syscall.Unmount(".", syscall.MNT_DETACH); err != nil {
+		return fmt.Errorf("detach old root: %w", err)
+	}
+	// В самом корне только точки монтирования, писать в него незачем
+	if err := syscall.Mount("", "/", "", syscall.MS_BIND|syscall.MS_REMOUNT|syscall.MS_RDONLY|syscall.MS_NOSUID|syscall.MS_NODEV, ""); err != nil {
+		return fmt.Errorf("remount root: %w", err)
+	}
+	return syscall.Chdir(l.Dir)
+}
+
+// bind делает файл или каталог хоста path видимым в root по тому же пути. Символические ссылки
+// копируются как есть: /bin и /lib во многих дистрибутивах ведут в /usr.
+func bind(root, path string, writable bool) error {
+	target := filepath.Join(root, path)
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		link, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(link, target)
+	case info.IsDir():
+		err = os.Mkdir(target, 0o755)
+	default:
+		err = os.WriteFile(target, nil, 0o644)
+	}
+	if err != nil && !os.IsExist(err) {
+		return err
+	}
+
+	if err := syscall.Mount(path, target, "", syscall.MS_BIND, ""); err != nil {
+		return fmt.Errorf("bind %s: %w", path, err)
+	}
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(target, &stat); err != nil {
+		return err
+	}
+	flags := uintptr(syscall.MS_BIND | syscall.MS_REMOUNT | syscall.MS_NOSUID)
+	if !writable {
+		flags |= syscall.MS_RDONLY
+	}
+	for _, locked := range lockedFlags {
+		if stat.Flags&locked.statfs != 0 {
+			flags |= locked.mount
+		}
+	}
+	if err := syscall.Mount("", target, "", flags, ""); err != nil {
+		return fmt.Errorf("remount %s: %w", path, err)
+	}
+	return nil
+}
+
+// overlay делает каталоги paths видимыми в root по тем же путям через overlayfs: запись в них уходит
+// в верхний слой на отдельном tmpfs, который исчезает вместе с процессом, а оригинал не меняется
+func overlay(root string, paths []string) error {
+	scratch := filepath.Join(root, ".overlay")
+	if err := os.Mkdir(scratch, 0o700); err != nil {
+		return err
+	}
+	if err := syscall.Mount("tmpfs", scratch, "tmpfs", syscall.MS_NOSUID|syscall.MS_NODEV, "size="+strconv.Itoa(cacheLayerLimit)); err != nil {
+		return fmt.Errorf("mount overlay layers: %w", err)
+	}
+	for i, path := range paths {
+		upper := filepath.Join(scratch, strconv.Itoa(i), "upper")
+		work := filepath.Join(scratch, strconv.Itoa(i), "work")
+		target := filepath.Join(root, path)
+		for _, dir := range []string{upper, work, target} {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return err
+			}
+		}
+		options := fmt.Sprintf("lowerdir=%s,upperdir=%s,workdir=%s", path, upper, work)
+		if err := syscall.Mount("overlay", target, "overlay", syscall.MS_NOSUID|syscall.MS_NODEV, options); err != nil {
+			return fmt.Errorf("overlay %s: %w", path, err)
+		}
+	}
+	// overlay держит слои сам, так что tmpfs можно убрать из дерева, чтобы его не было видно в песочнице
+	if err := syscall.Unmount(scratch, syscall.MNT_DETACH); err != nil {
+		return fmt.Errorf("detach overlay layers: %w", err)
+	}
+	return os.Remove(scratch)
+}
+
+// toolchainUser — пользователь, от имени которого сервер сам запускает инструментарий. Под root это
+// Config.UID: файлы общего кэша должны принадлежать тому, кто в user namespace песочницы, иначе
+// overlay не сможет скопировать каталоги кэша в верхний слой. Из песочницы сам кэш не виден.
+func (r *Runner) toolchainUser() *syscall.SysProcAttr {
+	if os.Getuid() != 0 {
+		return nil
+	}
+	return &syscall.SysProcAttr{Credential: &syscall.Credential{Uid: uint32(r.config.UID), Gid: uint32(r.config.GID)}}
+}
+
+// dropCapabilities очищает ограничивающий набор возможностей: после execve у программы нет прав
+// даже в собственном user namespace, так что перемонтировать каталоги для записи она не сможет
+func dropCapabilities() error {
+	for capability := 0; ; capability++ {
+		_, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, syscall.PR_CAPBSET_DROP, uintptr(capability), 0)
+		// Возможности с таким номером нет: набор пуст
+		if errno == syscall.EINVAL {
+			break
+		}
+		if errno != 0 {
+			return fmt.Errorf("drop capability %d: %w", capability, errno)
+		}
+	}
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prSetNoNewPrivs, 1, 0); errno != 0 {
+		return fmt.Errorf("set no_new_privs: %w", errno)
+	}
+	return nil
+}
+
+func usage(state *os.ProcessState, wall time.Duration) Usage {
+	cpu := state.UserTime() + state.SystemTime()
+	result := Usage{WallTimeMS: wall.Milliseconds(), CPUTimeMS: cpu.Milliseconds(), WallTime: wall, CPUTime: cpu}
+	if rusage, ok := state.SysUsage().(*syscall.Rusage); ok {
+		// В Linux ru_maxrss в килобайтах
+		result.MemoryKB = rusage.Maxrss
+	}
+	return result
+}
+
+func terminatedBy(state *os.ProcessState) string {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return ""
+	}
+	switch signal := status.Signal(); signal {
+	case syscall.SIGXCPU:
+		return "SIGXCPU"
+	case syscall.SIGKILL:
+		return "SIGKILL"
+	case syscall.SIGSEGV:
+		return "SIGSEGV"
+	case syscall.SIGABRT:
+		return "SIGABRT"
+	case syscall.SIGFPE:
+		return "SIGFPE"
+	default:
+		return fmt.Sprintf("signal %d", int(signal))
+	}
+}
@@ -0,0 +1,41 @@
+//go:build !linux
+
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+)
+
+// Без namespace и rlimit из Linux песочница не изолирует код, поэтому на других системах
+// запуск кода недоступен
+var errPlatform = errors.New("running code is only supported on Linux")
+
+func checkPlatform() error {
+	return errPlatform
+}
+
+// Exec на других системах только сообщает, что песочница недоступна
+func Exec(args []string) error {
+	return fmt.Errorf("%s: %w", ExecCommand, errPlatform)
+}
+
+func (r *Runner) isolation() *syscall.SysProcAttr {
+	return nil
+}
+
+func (r *Runner) toolchainUser() *syscall.SysProcAttr {
+	return nil
+}
+
+func usage(state *os.ProcessState, wall time.Duration) Usage {
+	cpu := state.UserTime() + state.SystemTime()
+	return Usage{WallTimeMS: wall.Milliseconds(), CPUTimeMS: cpu.Milliseconds(), WallTime: wall, CPUTime: cpu}
+}
+
+func terminatedBy(state *os.ProcessState) string {
+	return ""
+}
@@ -0,0 +1,75 @@
+package sandbox
+
+import (
+	"path/filepath"
+)
+
+// systemPaths — файлы и каталоги хоста, без которых не запускаются компиляторы и интерпретатор:
+// общие библиотеки и устройства, которые они открывают. В песочнице они доступны только для чтения.
+var systemPaths = []string{
+	"/bin", "/lib", "/lib32", "/lib64", "/usr",
+	"/etc/alternatives", "/etc/ld.so.cache",
+	"/dev/null", "/dev/zero", "/dev/random", "/dev/urandom",
+}
+
+// language описывает, как собрать и выполнить программу. Исходник лежит в файле source
+// во временном каталоге, который служит рабочим каталогом обоих шагов.
+type language struct {
+	name   string
+	tool   string
+	source string
+	// Команда сборки; nil для интерпретируемых языков
+	compile func(tool string) []string
+	run     func(tool string) []string
+}
+
+// goCachePackages — пакеты стандартной библиотеки, которые собираются в общий кэш при старте.
+// Остальные пакеты запуск собирает сам, в свой временный слой кэша.
+var goCachePackages = []string{
+	"bufio", "bytes", "container/heap", "container/list", "errors", "fmt", "math", "math/big",
+	"math/bits", "math/rand", "os", "sort", "strconv", "strings", "time", "unicode",
+}
+
+// languages перечисляет поддерживаемые языки; имена совпадают с programming_language алгоритмов
+func languages(config Config) []language {
+	binary := func(string) []string { return []string{"./program"} }
+	return []language{
+		{
+			name:   "Go",
+			tool:   config.Go,
+			source: "main.go",
+			compile: func(tool string) []string {
+				return []string{tool, "build", "-o", "program", "main.go"}
+			},
+			run: binary,
+		},
+		{
+			name:   "Python",
+			tool:   config.Python,
+			source: "main.py",
+			// -I не даёт читать переменные окружения и пользовательские site-packages, -B — писать .pyc
+			run: func(tool string) []string { return []string{tool, "-I", "-B", "main.py"} },
+		},
+		{
+			name:   "C++",
+			tool:   config.Cxx,
+			source: "main.cpp",
+			compile: func(tool string) []string {
+				return []string{tool, "-std=c++17", "-O2", "-pipe", "-o", "program", "main.cpp"}
+			},
+			run: binary,
+		},
+	}
+}
+
+// installDir — каталог, в который установлен инструмент: для /opt/python/bin/python3 это /opt/python
+func installDir(tool string) string {
+	if path, err := filepath.EvalSymlinks(tool); err == nil {
+		tool = path
+	}
+	dir := filepath.Dir(tool)
+	if filepath.Base(dir) == "bin" && filepath.Dir(dir) != "/" {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
@@ -0,0 +1,572 @@
+// Package sandbox запускает код алгоритмов на сервере: каждый запуск идёт в отдельном процессе
+// с ограничениями rlimit на процессорное время, память, размер файлов и число процессов,
+// без доступа к сети и во временном каталоге, который удаляется после запуска. Корень файловой
+// системы у запуска свой: в нём видны только инструментарий языков и этот каталог, файлы
+// сервера (конфигурация, ключи, база данных) недоступны.
+//
+// Ограничения выставляет сам сервер: он запускает свой исполняемый файл с аргументом ExecCommand,
+// тот собирает корень, ставит rlimit и заменяет себя программой через execve. Поэтому main должен
+// первым делом передавать такой вызов в Exec.
+//
+// Одновременно выполняется не больше Config.Workers запусков, остальные ждут в очереди ограниченной
+// длины, а при переполненной очереди Run сразу возвращает ErrBusy: запуски не могут занять все
+// ресурсы сервера и помешать API.
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExecCommand — аргумент, с которым сервер запускает сам себя внутри песочницы
+const ExecCommand = "sandbox-exec"
+
+// ExitCode — код, с которым main завершается, если Exec не смог запустить программу
+const ExitCode = 126
+
+const (
+	// Ограничения на компиляцию не настраиваются: компилятору нужно заметно больше, чем программе
+	compileMemoryLimit = 2 << 30
+	// Размер любого файла, который программа может записать во временный каталог
+	fileSizeLimit = 64 << 20
+	// Размер временного слоя, в который запуск пишет поверх общего кэша сборки Go
+	cacheLayerLimit = 256 << 20
+	// Число процессов и потоков запуска. Ядро считает их в собственном user namespace запуска
+	// (с Linux 5.14), поэтому ни процессы сервера, ни соседние запуски в это число не входят.
+	processLimit = 256
+)
+
+var (
+	ErrUnsupported = errors.New("language is not supported")
+	ErrBusy        = errors.New("too many runs are in progress, try again later")
+)
+
+// Статусы запуска
+const (
+	StatusOK           = "ok"
+	StatusCompileError = "compile_error"
+	StatusRuntimeError = "runtime_error"
+	StatusTimeLimit    = "time_limit_exceeded"
+	StatusMemoryLimit  = "memory_limit_exceeded"
+	// Вход не выполнялся, потому что RunSeries остановилась на одном из предыдущих
+	StatusSkipped = "skipped"
+)
+
+// Config — настройки песочницы. Пути к компиляторам и интерпретаторам ищутся в PATH сервера;
+// язык, для которого ничего не нашлось, считается неподдерживаемым.
+type Config struct {
+	Workers          int
+	QueueSize        int
+	TimeLimit        time.Duration
+	CompileTimeLimit time.Duration
+	MemoryLimit      int64
+	OutputLimit      int
+	// Каталог для временных каталогов запусков; по умолчанию системный
+	Dir string
+	// uid и gid, от имени которых выполняется код, если сервер работает под root.
+	// Иначе код выполняется от имени пользователя сервера.
+	UID, GID int
+
+	Go, Python, Cxx string
+}
+
+// Usage — ресурсы, потраченные программой. Время компиляции учитывается отдельно.
+type Usage struct {
+	WallTimeMS    int64 `json:"wall_time_ms"`
+	CPUTimeMS     int64 `json:"cpu_time_ms"`
+	MemoryKB      int64 `json:"memory_kb"`
+	CompileTimeMS int64 `json:"compile_time_ms,omitempty"`
+	// Те же времена без округления до миллисекунд, для замеров производительности
+	WallTime time.Duration `json:"-"`
+	CPUTime  time.Duration `json:"-"`
+}
+
+// Result — итог запуска. ExitCode равен -1, если процесс завершён сигналом.
+type Result struct {
+	Language        string `json:"language"`
+	Status          string `json:"status"`
+	ExitCode        int    `json:"exit_code"`
+	Signal          string `json:"signal,omitempty"`
+	Stdout          string `json:"stdout"`
+	Stderr          string `json:"stderr"`
+	StdoutTruncated bool   `json:"stdout_truncated,omitempty"`
+	StderrTruncated bool   `json:"stderr_truncated,omitempty"`
+	CompileOutput   string `json:"compile_output,omitempty"`
+	Usage           Usage  `json:"usage"`
+}
+
+// layout — корень файловой системы запуска: пустой tmpfs, в котором по тем же путям, что и на хосте,
+// видны только перечисленные файлы и каталоги. Передаётся в Exec одним аргументом в JSON.
+type layout struct {
+	// Точка монтирования корня на хосте
+	Root string `json:"root"`
+	// Рабочий каталог запуска
+	Dir      string   `json:"dir"`
+	ReadOnly []string `json:"read_only"`
+	// Каталоги, запись в которые уходит во временный слой запуска и не меняет оригинал
+	Overlays []string `json:"overlays,omitempty"`
+}
+
+type job struct {
+	ctx      context.Context
+	language language
+	code     string
+	stdins   []string
+	series   bool
+	done     chan outcome
+}
+
+type outcome struct {
+	results []Result
+	err     error
+}
+
+// Runner выполняет запуски пулом из Config.Workers горутин
+type Runner struct {
+	config    Config
+	self      string
+	path      string
+	root      string
+	readOnly  []string
+	goRoot    string
+	goCache   string
+	languages map[string]language
+	jobs      chan job
+}
+
+// New проверяет окружение и запускает пул. Языки без установленного инструментария пропускаются
+// с предупреждением в логе.
+func New(config Config) (*Runner, error) {
+	if config.Workers <= 0 {
+		return nil, fmt.Errorf("sandbox needs at least one worker")
+	}
+	if err := checkPlatform(); err != nil {
+		return nil, err
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	if config.Dir == "" {
+		config.Dir = filepath.Join(os.TempDir(), "algorithms-sandbox")
+	}
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	r := &Runner{
+		config:    config,
+		self:      self,
+		path:      os.Getenv("PATH"),
+		root:      filepath.Join(config.Dir, "root"),
+		languages: map[string]language{},
+		jobs:      make(chan job, config.QueueSize),
+	}
+	// Пустой каталог, на который каждый запуск монтирует свой корень
+	if err := os.MkdirAll(r.root, 0o755); err != nil {
+		return nil, err
+	}
+	// Кэш сборки Go общий для всех запусков, иначе каждый запуск заново компилирует стандартную
+	// библиотеку. Наполняет его только сам инструментарий при старте, см. warmGoCache.
+	r.goCache = filepath.Join(config.Dir, "go-cache")
+	if err := os.MkdirAll(r.goCache, 0o755); err != nil {
+		return nil, err
+	}
+	if err := r.own(r.goCache); err != nil {
+		return nil, err
+	}
+
+	for _, lang := range languages(config) {
+		path, err := exec.LookPath(lang.tool)
+		if err != nil {
+			log.Printf("WARNING: %s is not found, running %s code is disabled", lang.tool, lang.name)
+			continue
+		}
+		lang.tool = path
+		r.languages[lang.name] = lang
+	}
+	if lang, ok := r.languages["Go"]; ok {
+		// Go ищет стандартную библиотеку рядом со своим исполняемым файлом через /proc, которого
+		// в песочнице нет, поэтому GOROOT передаётся явно
+		out, err := exec.Command(lang.tool, "env", "GOROOT").Output()
+		if err != nil {
+			return nil, fmt.Errorf("%s env GOROOT: %w", lang.tool, err)
+		}
+		r.goRoot = strings.TrimSpace(string(out))
+	}
+	r.readOnly = r.toolchainPaths()
+
+	// Пробный запуск: без прав на namespace (например, в контейнере без CAP_SYS_ADMIN) песочница
+	// не изолирует код, и лучше узнать об этом при старте, чем на каждом запросе
+	if err := r.check(language{}); err != nil {
+		return nil, fmt.Errorf("sandbox does not work on this host: %w", err)
+	}
+	if lang, ok := r.languages["Go"]; ok {
+		err := r.warmGoCache(lang)
+		if err == nil {
+			// Кэш виден запускам через overlay, а overlay в user namespace умеет не каждое ядро
+			err = r.check(lang)
+		}
+		if err != nil {
+			log.Printf("WARNING: %v, running Go code is disabled", err)
+			delete(r.languages, "Go")
+		}
+	}
+
+	for i := 0; i < config.Workers; i++ {
+		go r.work()
+	}
+	return r, nil
+}
+
+// toolchainPaths перечисляет, что из файлов хоста видно в песочнице: системные каталоги
+// с библиотеками и каталоги, в которые установлен инструментарий языков
+func (r *Runner) toolchainPaths() []string {
+	paths := append([]string(nil), systemPaths...)
+	for _, lang := range r.languages {
+		paths = append(paths, installDir(lang.tool))
+	}
+	if r.goRoot != "" {
+		paths = append(paths, r.goRoot)
+	}
+
+	// Каталог внутри уже привязанного привязывать незачем
+	sort.Strings(paths)
+	var result []string
+	for _, path := range paths {
+		if _, err := os.Lstat(path); err != nil {
+			continue
+		}
+		if n := len(result); n > 0 && (path == result[n-1] || strings.HasPrefix(path, result[n-1]+"/")) {
+			continue
+		}
+		result = append(result, path)
+	}
+	return result
+}
+
+// check запускает пустую программу в том же корне, что и код на языке lang
+func (r *Runner) check(lang language) error {
+	dir, err := os.MkdirTemp(r.config.Dir, "check-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if err := r.own(dir); err != nil {
+		return err
+	}
+	result, err := r.execute(context.Background(), r.layout(dir, lang), []string{"true"}, []string{"PATH=" + r.path}, "", 10*time.Second, 64<<20)
+	if err != nil {
+		return err
+	}
+	if result.Status != StatusOK {
+		return fmt.Errorf("true exited with %d: %s", result.ExitCode, result.Stderr)
+	}
+	return nil
+}
+
+// warmGoCache собирает в общий кэш пакеты стандартной библиотеки из goCachePackages. Код запусков
+// в этот кэш не пишет: он виден им через overlay, и всё записанное уходит во временный слой,
+// который удаляется вместе с запуском. Так один запуск не может подменить результаты сборки
+// для других.
+func (r *Runner) warmGoCache(lang language) error {
+	dir, err := os.MkdirTemp(r.config.Dir, "go-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if err := r.own(dir); err != nil {
+		return err
+	}
+
+	log.Println("Building common Go packages into the sandbox cache")
+	cmd := exec.Command(lang.tool, append([]string{"build"}, goCachePackages...)...)
+	cmd.Dir = dir
+	cmd.Env = r.env(dir, lang)
+	cmd.SysProcAttr = r.toolchainUser()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("build the Go cache: %w: %s", err, out)
+	}
+	return nil
+}
+
+// layout — корень запуска с рабочим каталогом dir
+func (r *Runner) layout(dir string, lang language) layout {
+	l := layout{Root: r.root, Dir: dir, ReadOnly: r.readOnly}
+	if lang.name == "Go" {
+		l.Overlays = []string{r.goCache}
+	}
+	return l
+}
+
+// Languages возвращает языки, которые можно запускать
+func (r *Runner) Languages() []string {
+	names := make([]string, 0, len(r.languages))
+	for name := range r.languages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run ставит запуск в очередь и ждёт результата. Если ctx отменён, запуск прерывается,
+// а если ещё не начался — не выполняется вовсе.
+func (r *Runner) Run(ctx context.Context, languageName, code, stdin string) (Result, error) {
+	results, err := r.RunBatch(ctx, languageName, code, []string{stdin})
+	if err != nil {
+		return Result{}, err
+	}
+	return results[0], nil
+}
+
+// RunBatch компилирует код один раз и выполняет его на каждом из входов по очереди, занимая
+// один обработчик пула. Ограничения действуют на каждое выполнение отдельно. Если код не
+// скомпилировался, результат компиляции повторяется для всех входов.
+func (r *Runner) RunBatch(ctx context.Context, languageName, code string, stdins []string) ([]Result, error) {
+	return r.submit(ctx, languageName, code, stdins, false)
+}
+
+// RunSeries работает как RunBatch, но останавливается на первом неудачном выполнении: остальные
+// входы получают статус StatusSkipped. Так замеры на растущих входах не ждут заведомого превышения лимитов.
+func (r *Runner) RunSeries(ctx context.Context, languageName, code string, stdins []string) ([]Result, error) {
+	return r.submit(ctx, languageName, code, stdins, true)
+}
+
+func (r *Runner) submit(ctx context.Context, languageName, code string, stdins []string, series bool) ([]Result, error) {
+	lang, ok := r.languages[languageName]
+	if !ok {
+		return nil, ErrUnsupported
+	}
+
+	j := job{ctx: ctx, language: lang, code: code, stdins: stdins, series: series, done: make(chan outcome, 1)}
+	select {
+	case r.jobs <- j:
+	default:
+		return nil, ErrBusy
+	}
+
+	select {
+	case o := <-j.done:
+		return o.results, o.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (r *Runner) work() {
+	for j := range r.jobs {
+		if j.ctx.Err() != nil {
+			j.done <- outcome{err: j.ctx.Err()}
+			continue
+		}
+		results, err := r.run(j.ctx, j.language, j.code, j.stdins, j.series)
+		j.done <- outcome{results: results, err: err}
+	}
+}
+
+// run компилирует и выполняет код в новом временном каталоге
+func (r *Runner) run(ctx context.Context, lang language, code string, stdins []string, series bool) ([]Result, error) {
+	dir, err := os.MkdirTemp(r.config.Dir, "run-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	if err := r.own(dir); err != nil {
+		return nil, err
+	}
+	source := filepath.Join(dir, lang.source)
+	if err := os.WriteFile(source, []byte(code), 0o644); err != nil {
+		return nil, err
+	}
+	if err := r.own(source); err != nil {
+		return nil, err
+	}
+
+	env := r.env(dir, lang)
+	root := r.layout(dir, lang)
+	results := make([]Result, len(stdins))
+	var compileTime int64
+	if lang.compile != nil {
+		compiled, err := r.execute(ctx, root, lang.compile(lang.tool), env, "", r.config.CompileTimeLimit, compileMemoryLimit)
+		if err != nil {
+			return nil, err
+		}
+		if compiled.Status != StatusOK {
+			compiled.Language = lang.name
+			compiled.CompileOutput = compiled.Stdout + compiled.Stderr
+			compiled.Stdout, compiled.Stderr = "", ""
+			compiled.StdoutTruncated, compiled.StderrTruncated = false, false
+			compiled.Usage = Usage{CompileTimeMS: compiled.Usage.WallTimeMS}
+			if compiled.Status != StatusTimeLimit {
+				compiled.Status = StatusCompileError
+			}
+			for i := range results {
+				results[i] = compiled
+			}
+			return results, nil
+		}
+		compileTime = compiled.Usage.WallTimeMS
+	}
+
+	for i, stdin := range stdins {
+		result, err := r.execute(ctx, root, lang.run(lang.tool), env, stdin, r.config.TimeLimit, r.config.MemoryLimit)
+		if err != nil {
+			return nil, err
+		}
+		result.Language = lang.name
+		result.Usage.CompileTimeMS = compileTime
+		if result.Status == StatusRuntimeError && outOfMemory(result.Stderr) {
+			result.Status = StatusMemoryLimit
+		}
+		results[i] = result
+		if series && result.Status != StatusOK {
+			for j := i + 1; j < len(results); j++ {
+				results[j] = Result{Language: lang.name, Status: StatusSkipped}
+			}
+			break
+		}
+	}
+	return results, nil
+}
+
+// env — полное окружение запуска: переменные сервера в песочницу не попадают
+func (r *Runner) env(dir string, lang language) []string {
+	env := []string{
+		"PATH=" + r.path,
+		"HOME=" + dir,
+		"TMPDIR=" + dir,
+		"LANG=C.UTF-8",
+	}
+	if lang.name == "Go" {
+		env = append(env,
+			"GOROOT="+r.goRoot,
+			"GOCACHE="+r.goCache,
+			"GOPATH="+filepath.Join(dir, "gopath"),
+			"GOENV=off",
+			"GOTOOLCHAIN=local",
+			"GOPROXY=off",
+			"CGO_ENABLED=0",
+		)
+	}
+	return env
+}
+
+// execute запускает argv через ExecCommand в корне root с ограничениями и собирает результат. Ошибка
+// возвращается, только если запуск не удалось провести; ошибки самой программы попадают в Result.
+func (r *Runner) execute(ctx context.Context, root layout, argv, env []string, stdin string, timeLimit time.Duration, memoryLimit int64) (Result, error) {
+	runCtx, cancel := context.WithTimeout(ctx, timeLimit)
+	defer cancel()
+
+	// Процессорное время ограничено той же величиной, что и общее, с округлением вверх до секунды
+	cpuSeconds := int64((timeLimit + time.Second - 1) / time.Second)
+	rootJSON, err := json.Marshal(root)
+	if err != nil {
+		return Result{}, err
+	}
+	args := append([]string{
+		ExecCommand,
+		strconv.FormatInt(cpuSeconds, 10),
+		strconv.FormatInt(memoryLimit, 10),
+		strconv.Itoa(fileSizeLimit),
+		strconv.Itoa(processLimit),
+		string(rootJSON),
+		"--",
+	}, argv...)
+
+	cmd := exec.CommandContext(runCtx, r.self, args...)
+	cmd.Dir = root.Dir
+	cmd.Env = env
+	cmd.Stdin = strings.NewReader(stdin)
+	stdout := &limitedBuffer{limit: r.config.OutputLimit}
+	stderr := &limitedBuffer{limit: r.config.OutputLimit}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	cmd.SysProcAttr = r.isolation()
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err = cmd.Run()
+	wall := time.Since(start)
+
+	if cmd.ProcessState == nil {
+		return Result{}, fmt.Errorf("start sandbox: %w", err)
+	}
+	// Запрос отменил клиент, а не истекло время
+	if ctx.Err() != nil {
+		return Result{}, ctx.Err()
+	}
+
+	result := Result{
+		Status:          StatusOK,
+		ExitCode:        cmd.ProcessState.ExitCode(),
+		Stdout:          stdout.String(),
+		Stderr:          stderr.String(),
+		StdoutTruncated: stdout.truncated,
+		StderrTruncated: stderr.truncated,
+		Usage:           usage(cmd.ProcessState, wall),
+	}
+	signal := terminatedBy(cmd.ProcessState)
+	result.Signal = signal
+	switch {
+	case runCtx.Err() != nil || signal == "SIGXCPU":
+		result.Status = StatusTimeLimit
+	case result.ExitCode == ExitCode && strings.HasPrefix(result.Stderr, ExecCommand+":"):
+		return Result{}, errors.New(strings.TrimSpace(result.Stderr))
+	case result.ExitCode != 0:
+		result.Status = StatusRuntimeError
+	}
+	return result, nil
+}
+
+// own передаёт файл пользователю песочницы, если код запускается от отдельного uid
+func (r *Runner) own(path string) error {
+	if os.Getuid() != 0 {
+		return nil
+	}
+	return os.Chown(path, r.config.UID, r.config.GID)
+}
+
+// outOfMemory узнаёт по stderr, что программе не хватило памяти: при rlimit на память выделение
+// просто завершается ошибкой, и каждая среда выполнения сообщает о ней по-своему
+func outOfMemory(stderr string) bool {
+	for _, marker := range []string{"MemoryError", "std::bad_alloc", "runtime: out of memory", "cannot allocate memory"} {
+		if strings.Contains(stderr, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// limitedBuffer сохраняет первые limit байт и молча отбрасывает остальное, чтобы программа
+// не блокировалась на записи в закрытый канал. bytes.Buffer не встроен: exec копирует вывод
+// через io.Copy, и его ReadFrom обошёл бы ограничение.
+type limitedBuffer struct {
+	buffer    bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buffer.Len(); len(p) > room {
+		if room > 0 {
+			b.buffer.Write(p[:room])
+		}
+		b.truncated = true
+		return len(p), nil
+	}
+	return b.buffer.Write(p)
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buffer.String()
+}
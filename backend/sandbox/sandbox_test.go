@@ -0,0 +1,284 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// Песочница запускает свой исполняемый файл с ExecCommand, в тестах это сам тестовый бинарник
+func TestMain(m *testing.M) {
+	if len(os.Args) > 1 && os.Args[1] == ExecCommand {
+		fmt.Fprintln(os.Stderr, Exec(os.Args[2:]))
+		os.Exit(ExitCode)
+	}
+	if dir := os.Getenv(testCopyEnv); dir != "" {
+		code := m.Run()
+		os.RemoveAll(dir)
+		os.Exit(code)
+	}
+	if os.Getuid() == 0 {
+		reexecReadable()
+	}
+	os.Exit(m.Run())
+}
+
+// testCopyEnv — каталог с копией тестового бинарника, которую запустил reexecReadable
+const testCopyEnv = "SANDBOX_TEST_COPY"
+
+// reexecReadable перезапускает тесты из копии бинарника в открытом каталоге. Под root код выполняется
+// от Config.UID, а go test кладёт бинарник в каталог, доступный только root. Запускать же код от root
+// нельзя: на root не действует ограничение на число процессов. Если копия не удалась, тесты
+// выполняются как есть и пропускаются в newRunner.
+func reexecReadable() {
+	self, err := os.Executable()
+	if err != nil {
+		return
+	}
+	binary, err := os.ReadFile(self)
+	if err != nil {
+		return
+	}
+	dir, err := os.MkdirTemp("", "sandbox-test-")
+	if err != nil {
+		return
+	}
+	path := filepath.Join(dir, filepath.Base(self))
+	if os.Chmod(dir, 0o755) != nil || os.WriteFile(path, binary, 0o755) != nil {
+		os.RemoveAll(dir)
+		return
+	}
+	syscall.Exec(path, os.Args, append(os.Environ(), testCopyEnv+"="+dir))
+	os.RemoveAll(dir)
+}
+
+// newRunner запускает песочницу для C++ или пропускает тест, если на хосте нет компилятора
+// или прав на namespace
+func newRunner(t *testing.T, config Config) *Runner {
+	t.Helper()
+	if _, err := exec.LookPath("g++"); err != nil {
+		t.Skip("g++ is not installed")
+	}
+	// Каталог запусков должен быть доступен пользователю песочницы
+	dir, err := os.MkdirTemp("", "sandbox-runs-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	if err := os.Chmod(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	config.Workers, config.QueueSize = 1, 16
+	config.CompileTimeLimit = time.Minute
+	config.Dir = dir
+	config.UID, config.GID = 65534, 65534
+	config.Cxx = "g++"
+	r, err := New(config)
+	if err != nil {
+		t.Skipf("sandbox is not available: %v", err)
+	}
+	return r
+}
+
+func TestLimits(t *testing.T) {
+	r := newRunner(t, Config{TimeLimit: 2 * time.Second, MemoryLimit: 64 << 20, OutputLimit: 1 << 10})
+
+	tests := []struct {
+		name   string
+		code   string
+		stdin  string
+		status string
+		check  func(t *testing.T, result Result)
+	}{
+		{
+			name: "echo",
+			code: `#include <iostream>
+int main() { int a, b; std::cin >> a >> b; std::cout << a + b; }`,
+			stdin:  "2 3",
+			status: StatusOK,
+			check: func(t *testing.T, result Result) {
+				if result.Stdout != "5" {
+					t.Errorf("stdout = %q, want 5", result.Stdout)
+				}
+			},
+		},
+		{
+			name:   "compile error",
+			code:   `int main() { return undefined; }`,
+			status: StatusCompileError,
+			check: func(t *testing.T, result Result) {
+				if !strings.Contains(result.CompileOutput, "undefined") {
+					t.Errorf("compile output = %q", result.CompileOutput)
+				}
+			},
+		},
+		{
+			name:   "time limit",
+			code:   `int main() { volatile unsigned long i = 0; for (;;) i++; }`,
+			status: StatusTimeLimit,
+			check: func(t *testing.T, result Result) {
+				if result.Usage.WallTime > 5*time.Second {
+					t.Errorf("run took %v with a 2s limit", result.Usage.WallTime)
+				}
+			},
+		},
+		{
+			name: "memory limit",
+			code: `#include <vector>
+int main() { std::vector<char> v(256 << 20, 1); return v[v.size() - 1] - 1; }`,
+			status: StatusMemoryLimit,
+		},
+		{
+			name: "output limit",
+			code: `#include <cstdio>
+int main() { for (int i = 0; i < 100000; i++) std::puts("0123456789"); }`,
+			status: StatusOK,
+			check: func(t *testing.T, result Result) {
+				if !result.StdoutTruncated || len(result.Stdout) != 1<<10 {
+					t.Errorf("stdout has %d bytes, truncated %v; want 1024, true", len(result.Stdout), result.StdoutTruncated)
+				}
+			},
+		},
+		{
+			name: "file size limit",
+			// Программа — init своего PID namespace, и SIGXFSZ ядро ей не доставляет, но записи
+			// сверх предела завершаются ошибкой
+			code: `#include <cstdio>
+#include <fcntl.h>
+#include <sys/stat.h>
+#include <unistd.h>
+#include <vector>
+int main() {
+	std::vector<char> block(1 << 20, 'x');
+	int fd = open("big", O_WRONLY | O_CREAT | O_TRUNC, 0644);
+	for (int i = 0; i < 80; i++) write(fd, block.data(), block.size());
+	close(fd);
+	struct stat st;
+	stat("big", &st);
+	std::printf("%lld", (long long)st.st_size);
+}`,
+			status: StatusOK,
+			check: func(t *testing.T, result Result) {
+				if want := fmt.Sprint(fileSizeLimit); result.Stdout != want {
+					t.Errorf("file has %s bytes, want %s", result.Stdout, want)
+				}
+			},
+		},
+		{
+			name: "process limit",
+			code: `#include <cstdio>
+#include <unistd.h>
+int main() {
+	int forks = 0;
+	for (; forks < 1000; forks++) {
+		pid_t pid = fork();
+		if (pid < 0) break;
+		if (pid == 0) { pause(); _exit(0); }
+	}
+	std::printf("%d", forks);
+}`,
+			status: StatusOK,
+			check: func(t *testing.T, result Result) {
+				var forks int
+				fmt.Sscan(result.Stdout, &forks)
+				if forks == 0 || forks >= processLimit {
+					t.Errorf("forked %q processes, want fewer than %d", result.Stdout, processLimit)
+				}
+			},
+		},
+		{
+			name: "no network",
+			code: `#include <arpa/inet.h>
+#include <cstdio>
+#include <sys/socket.h>
+int main() {
+	int fd = socket(AF_INET, SOCK_STREAM, 0);
+	sockaddr_in addr{};
+	addr.sin_family = AF_INET;
+	addr.sin_port = htons(80);
+	inet_pton(AF_INET, "1.1.1.1", &addr.sin_addr);
+	std::puts(fd >= 0 && connect(fd, (sockaddr *)&addr, sizeof addr) == 0 ? "connected" : "blocked");
+}`,
+			status: StatusOK,
+			check: func(t *testing.T, result Result) {
+				if result.Stdout != "blocked\n" {
+					t.Errorf("stdout = %q, want blocked", result.Stdout)
+				}
+			},
+		},
+		{
+			name: "host files",
+			code: `#include <cstdio>
+int main() { std::puts(std::fopen("/etc/passwd", "r") ? "visible" : "hidden"); }`,
+			status: StatusOK,
+			check: func(t *testing.T, result Result) {
+				if result.Stdout != "hidden\n" {
+					t.Errorf("stdout = %q, want hidden", result.Stdout)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := r.Run(context.Background(), "C++", tt.code, tt.stdin)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != tt.status {
+				t.Fatalf("status = %s, want %s; exit code %d, signal %q, stdout %q, stderr %q, compile output %q",
+					result.Status, tt.status, result.ExitCode, result.Signal, result.Stdout, result.Stderr, result.CompileOutput)
+			}
+			if tt.check != nil {
+				tt.check(t, result)
+			}
+		})
+	}
+}
+
+func TestRunSeriesStopsAtFirstFailure(t *testing.T) {
+	r := newRunner(t, Config{TimeLimit: 2 * time.Second, MemoryLimit: 64 << 20, OutputLimit: 1 << 10})
+
+	code := `#include <iostream>
+int main() { int n; std::cin >> n; return n; }`
+	results, err := r.RunSeries(context.Background(), "C++", code, []string{"0", "1", "0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{StatusOK, StatusRuntimeError, StatusSkipped}
+	for i, result := range results {
+		if result.Status != want[i] {
+			t.Errorf("run %d: status = %s, want %s", i, result.Status, want[i])
+		}
+	}
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	tests := []struct {
+		writes    []string
+		want      string
+		truncated bool
+	}{
+		{[]string{"abc", "de"}, "abcde", false},
+		{[]string{"abc", "def"}, "abcde", true},
+		{[]string{"abcdefgh"}, "abcde", true},
+		{[]string{"abcde", "f", "g"}, "abcde", true},
+	}
+	for _, tt := range tests {
+		b := &limitedBuffer{limit: 5}
+		for _, w := range tt.writes {
+			if n, err := b.Write([]byte(w)); n != len(w) || err != nil {
+				t.Errorf("Write(%q) = %d, %v", w, n, err)
+			}
+		}
+		if b.String() != tt.want || b.truncated != tt.truncated {
+			t.Errorf("%q: got %q, truncated %v; want %q, %v", tt.writes, b.String(), b.truncated, tt.want, tt.truncated)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gorilla/mux"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// Не обновляем last_used_at сессии на каждый запрос, чтобы не писать в базу постоянно
+const sessionTouchInterval = time.Minute
+
+type Session = storage.Session
+
+func generateRefreshToken() (string, error) {
+	token := make([]byte, 32)
+	_, err := rand.Read(token)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// В базе хранятся только хеши refresh-токенов и токенов подтверждения email
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// issueAccessToken подписывает короткоживущий JWT, привязанный к сессии.
+// Сессию продлевает refresh-токен, который меняется при каждом обновлении.
+func issueAccessToken(user User, sessionID int) (string, time.Time, error) {
+	now := time.Now()
+	expirationTime := now.Add(time.Duration(cfg.Auth.AccessTokenTTL))
+	claims := &Claims{
+		Username:  user.Username,
+		UserID:    user.ID,
+		Role:      user.Role,
+		SessionID: sessionID,
+		StandardClaims: jwt.StandardClaims{
+			IssuedAt:  now.Unix(),
+			ExpiresAt: expirationTime.Unix(),
+		},
+	}
+
+	tokenString, err := keys.sign(claims)
+	return tokenString, expirationTime, err
+}
+
+// createSession открывает новую сессию и возвращает её id и первый refresh-токен
+func createSession(userID int, r *http.Request) (int, string, error) {
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		return 0, "", err
+	}
+
+	session := Session{
+		UserID:    userID,
+		UserAgent: r.UserAgent(),
+		IP:        clientIP(r),
+		ExpiresAt: time.Now().Add(time.Duration(cfg.Auth.RefreshTokenTTL)),
+	}
+	err = store.CreateSession(r.Context(), &session, hashToken(refreshToken))
+	return session.ID, refreshToken, err
+}
+
+// writeTokens отдаёт клиенту пару токенов в формате ответа Login
+func writeTokens(w http.ResponseWriter, message string, user User, sessionID int, refreshToken string) {
+	accessToken, expiresAt, err := issueAccessToken(user, sessionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":       message,
+		"token":         accessToken,
+		"expires_at":    expiresAt,
+		"refresh_token": refreshToken,
+		"userID":        strconv.Itoa(user.ID),
+	})
+}
+
+// checkSession проверяет, что сессия токена не отозвана и пароль не менялся после его выпуска
+func checkSession(ctx context.Context, claims *Claims) (bool, error) {
+	if claims.SessionID == 0 {
+		return false, nil
+	}
+
+	session, err := store.GetSession(ctx, claims.SessionID, claims.UserID)
+	if err == storage.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	if session.RevokedAt != nil || !session.ExpiresAt.After(now) {
+		return false, nil
+	}
+
+	user, err := store.GetUser(ctx, claims.UserID)
+	if err == storage.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if user.PasswordChangedAt != nil && claims.IssuedAt < user.PasswordChangedAt.Unix() {
+		return false, nil
+	}
+
+	return true, store.TouchSession(ctx, claims.SessionID, now, now.Add(-sessionTouchInterval))
+}
+
+// RefreshToken обменивает refresh-токен на новую пару токенов. Старый refresh-токен одноразовый:
+// повторное предъявление означает утечку, и вся сессия отзывается.
+func RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if body.RefreshToken == "" {
+		http.Error(w, "Refresh token must be provided", http.StatusBadRequest)
+		return
+	}
+
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	session, user, err := store.RotateRefreshToken(r.Context(), hashToken(body.RefreshToken), hashToken(refreshToken),
+		time.Now().Add(time.Duration(cfg.Auth.RefreshTokenTTL)), clientIP(r), r.UserAgent())
+	switch err {
+	case nil:
+	case storage.ErrNotFound:
+		http.Error(w, "Invalid refresh token", http.StatusUnauthorized)
+		return
+	case storage.ErrTokenReused:
+		http.Error(w, "Refresh token has already been used, session revoked", http.StatusUnauthorized)
+		return
+	case storage.ErrTokenExpired:
+		http.Error(w, "Refresh token expired or revoked", http.StatusUnauthorized)
+		return
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeTokens(w, "Token refreshed", user, session.ID, refreshToken)
+}
+
+// Logout завершает текущую сессию
+func Logout(w http.ResponseWriter, r *http.Request) {
+	err := store.RevokeSession(r.Context(), r.Context().Value("sessionID").(int), r.Context().Value("userID").(int), time.Now())
+	if err != nil && err != storage.ErrNotFound {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Logged out"})
+}
+
+// LogoutAll завершает все сессии пользователя на всех устройствах
+func LogoutAll(w http.ResponseWriter, r *http.Request) {
+	if err := store.RevokeUserSessions(r.Context(), r.Context().Value("userID").(int), time.Now()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Logged out from all devices"})
+}
+
+// GetSessions возвращает активные сессии пользователя
+func GetSessions(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+	currentSessionID := r.Context().Value("sessionID").(int)
+
+	sessions, err := store.ListActiveSessions(r.Context(), userID, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+
+	json.NewEncoder(w).Encode(sessions)
+}
+
+// RevokeSession завершает одну из сессий пользователя
+func RevokeSession(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid ID parameter", http.StatusBadRequest)
+		return
+	}
+
+	err = store.RevokeSession(r.Context(), id, r.Context().Value("userID").(int), time.Now())
+	if err == storage.ErrNotFound {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Session revoked"})
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// loginTokens входит под именем username и возвращает access- и refresh-токены
+func loginTokens(t *testing.T, username, password string) (string, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	Login(rec, httptest.NewRequest("POST", "/login", strings.NewReader(`{"username": "`+username+`", "password": "`+password+`"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login: %d %s", rec.Code, rec.Body)
+	}
+	return decodeTokens(t, rec)
+}
+
+func decodeTokens(t *testing.T, rec *httptest.ResponseRecorder) (string, string) {
+	t.Helper()
+	var body struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	return body.Token, body.RefreshToken
+}
+
+// refresh предъявляет refresh-токен и возвращает ответ
+func refresh(refreshToken string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	RefreshToken(rec, httptest.NewRequest("POST", "/refresh", strings.NewReader(`{"refresh_token": "`+refreshToken+`"}`)))
+	return rec
+}
+
+// authenticated вызывает handler за Authenticate с access-токеном token и возвращает код ответа
+func authenticated(handler http.HandlerFunc, method, token string) int {
+	req := httptest.NewRequest(method, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	Authenticate(handler).ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	for _, driver := range testDrivers {
+		setupTest(t)
+		useStore(t, driver)
+		newTestUser(t, "alice", "password")
+
+		access1, refresh1 := loginTokens(t, "alice", "password")
+		if code := authenticated(GetSessions, "GET", access1); code != http.StatusOK {
+			t.Fatalf("%s: fresh access token: %d", driver, code)
+		}
+
+		rec := refresh(refresh1)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: refresh: %d %s", driver, rec.Code, rec.Body)
+		}
+		_, refresh2 := decodeTokens(t, rec)
+		if refresh2 == "" || refresh2 == refresh1 {
+			t.Fatalf("%s: refresh token was not rotated", driver)
+		}
+
+		rec = refresh(refresh2)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: second refresh: %d %s", driver, rec.Code, rec.Body)
+		}
+		access3, refresh3 := decodeTokens(t, rec)
+
+		// Повторное предъявление использованного токена означает утечку: сессия отзывается целиком
+		steps := []struct {
+			name     string
+			code     int
+			wantCode int
+		}{
+			{"reuse of the first token", refresh(refresh1).Code, http.StatusUnauthorized},
+			{"latest token after reuse", refresh(refresh3).Code, http.StatusUnauthorized},
+			{"access token after reuse", authenticated(GetSessions, "GET", access3), http.StatusUnauthorized},
+			{"unknown token", refresh("0123456789abcdef").Code, http.StatusUnauthorized},
+			{"empty token", refresh("").Code, http.StatusBadRequest},
+		}
+		for _, step := range steps {
+			if step.code != step.wantCode {
+				t.Errorf("%s, %s: got %d, want %d", driver, step.name, step.code, step.wantCode)
+			}
+		}
+	}
+}
+
+func TestRefreshTokenErrors(t *testing.T) {
+	for _, driver := range testDrivers {
+		setupTest(t)
+		useStore(t, driver)
+		newTestUser(t, "alice", "password")
+
+		_, refresh1 := loginTokens(t, "alice", "password")
+		refresh(refresh1)
+		if body := refresh(refresh1).Body.String(); !strings.Contains(body, "already been used") {
+			t.Errorf("%s: reused token answer %q", driver, body)
+		}
+
+		cfg.Auth.RefreshTokenTTL = Duration(-time.Minute)
+		_, expired := loginTokens(t, "alice", "password")
+		if body := refresh(expired).Body.String(); !strings.Contains(body, "expired or revoked") {
+			t.Errorf("%s: expired token answer %q", driver, body)
+		}
+
+		if body := refresh("0123456789abcdef").Body.String(); !strings.Contains(body, "Invalid refresh token") {
+			t.Errorf("%s: unknown token answer %q", driver, body)
+		}
+	}
+}
+
+func TestLogout(t *testing.T) {
+	for _, driver := range testDrivers {
+		setupTest(t)
+		useStore(t, driver)
+		newTestUser(t, "alice", "password")
+
+		laptop, laptopRefresh := loginTokens(t, "alice", "password")
+		phone, _ := loginTokens(t, "alice", "password")
+		tablet, _ := loginTokens(t, "alice", "password")
+
+		// Выход завершает только текущую сессию, и её refresh-токен больше не обменивается
+		if code := authenticated(Logout, "POST", laptop); code != http.StatusOK {
+			t.Fatalf("%s: logout: %d", driver, code)
+		}
+		if code := authenticated(GetSessions, "GET", laptop); code != http.StatusUnauthorized {
+			t.Errorf("%s: access token after logout: %d, want 401", driver, code)
+		}
+		if code := refresh(laptopRefresh).Code; code != http.StatusUnauthorized {
+			t.Errorf("%s: refresh after logout: %d, want 401", driver, code)
+		}
+		if code := authenticated(GetSessions, "GET", phone); code != http.StatusOK {
+			t.Errorf("%s: other session after logout: %d, want 200", driver, code)
+		}
+
+		if code := authenticated(LogoutAll, "POST", phone); code != http.StatusOK {
+			t.Fatalf("%s: logout everywhere: %d", driver, code)
+		}
+		if code := authenticated(GetSessions, "GET", tablet); code != http.StatusUnauthorized {
+			t.Errorf("%s: session after logout everywhere: %d, want 401", driver, code)
+		}
+	}
+}
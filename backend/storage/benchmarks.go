@@ -0,0 +1,70 @@
+package storage
+
+import "time"
+
+// Состояние замера. Замер выполняется в фоне; прерванный перезапуском сервера начинается заново.
+const (
+	BenchmarkPending = "pending"
+	BenchmarkRunning = "running"
+	BenchmarkDone    = "done"
+	BenchmarkFailed  = "failed"
+)
+
+// Ограничения замера: число алгоритмов, размеров и повторов, а также всех запусков вместе,
+// чтобы один замер не занимал песочницу часами
+const (
+	MaxBenchmarkAlgorithms  = 10
+	MaxBenchmarkSizes       = 12
+	MaxBenchmarkRepetitions = 10
+	MaxBenchmarkRuns        = 300
+)
+
+// Таблица benchmarks: замер нескольких алгоритмов на входах растущего размера. Входы порождает
+// генератор Generator из пакета benchmark с начальным значением Seed, одинаковым для всех алгоритмов.
+type Benchmark struct {
+	ID          int                  `json:"id"`
+	UserID      int                  `json:"user_id"`
+	Generator   string               `json:"generator"`
+	Sizes       []int                `json:"sizes"`
+	Repetitions int                  `json:"repetitions"`
+	Seed        int64                `json:"seed"`
+	Status      string               `json:"status"`
+	Error       string               `json:"error,omitempty"`
+	CreatedAt   time.Time            `json:"created_at"`
+	StartedAt   *time.Time           `json:"started_at,omitempty"`
+	FinishedAt  *time.Time           `json:"finished_at,omitempty"`
+	Algorithms  []BenchmarkAlgorithm `json:"algorithms"`
+}
+
+// Таблица benchmark_algorithms: алгоритм в замере и оценки роста по его результатам. Название,
+// язык и заявленные оценки копируются на момент замера, чтобы их можно было сравнить и после правок.
+type BenchmarkAlgorithm struct {
+	AlgorithmID         int    `json:"algorithm_id"`
+	Title               string `json:"title"`
+	ProgrammingLanguage string `json:"programming_language"`
+	Revision            int    `json:"revision"`
+	// Status — pending, done или failed, если замерить не удалось совсем; причина — в Error.
+	// Размеры, на которых программа превысила лимиты, видны по статусам замеров.
+	Status          string `json:"status"`
+	Error           string `json:"error,omitempty"`
+	TimeComplexity  string `json:"time_complexity,omitempty"`
+	SpaceComplexity string `json:"space_complexity,omitempty"`
+	// Оценки по замерам; пустые, если успешных размеров не хватило
+	FittedTimeComplexity  string                 `json:"fitted_time_complexity,omitempty"`
+	TimeExponent          float64                `json:"time_exponent,omitempty"`
+	FittedSpaceComplexity string                 `json:"fitted_space_complexity,omitempty"`
+	SpaceExponent         float64                `json:"space_exponent,omitempty"`
+	Measurements          []BenchmarkMeasurement `json:"measurements"`
+	// Совпадают ли оценки по замерам с заявленными; заполняются при ответе, если есть обе
+	TimeMatches  *bool `json:"time_matches,omitempty"`
+	SpaceMatches *bool `json:"space_matches,omitempty"`
+}
+
+// Таблица benchmark_measurements: медианы повторов на одном размере
+type BenchmarkMeasurement struct {
+	Size       int     `json:"size"`
+	Status     string  `json:"status"`
+	WallTimeMS float64 `json:"wall_time_ms"`
+	CPUTimeMS  float64 `json:"cpu_time_ms"`
+	MemoryKB   int64   `json:"memory_kb"`
+}
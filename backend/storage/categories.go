@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultCategories — корневые категории, с которыми начинается новая база (миграция 0019)
+var DefaultCategories = []string{
+	"Sorting", "Searching", "Graphs", "Trees", "Dynamic programming",
+	"Greedy algorithms", "Strings", "Mathematics", "Geometry", "Data structures",
+}
+
+// CategorySlug нормализует имя категории: нижний регистр, одиночные пробелы и без конечной «s»
+// множественного числа, так что «Graphs», «graph» и «graphs » дают один slug. То же правило
+// применяет миграция 0009 к старым значениям topic.
+func CategorySlug(name string) string {
+	slug := strings.ToLower(strings.Join(strings.Fields(name), " "))
+	if utf8.RuneCountInString(slug) > 3 && strings.HasSuffix(slug, "s") && !strings.HasSuffix(slug, "ss") {
+		slug = strings.TrimSuffix(slug, "s")
+	}
+	return slug
+}
+
+// CategoryTree собирает дерево из плоского списка категорий; дети упорядочены по имени
+func CategoryTree(categories []Category) []Category {
+	sorted := append([]Category(nil), categories...)
+	sort.SliceStable(sorted, func(i, j int) bool { return strings.ToLower(sorted[i].Name) < strings.ToLower(sorted[j].Name) })
+
+	children := map[int][]Category{}
+	var roots []Category = []Category{}
+	for _, category := range sorted {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+		} else {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		}
+	}
+
+	var attach func(category Category) Category
+	attach = func(category Category) Category {
+		for _, child := range children[category.ID] {
+			category.Children = append(category.Children, attach(child))
+		}
+		return category
+	}
+	for i := range roots {
+		roots[i] = attach(roots[i])
+	}
+	return roots
+}
+
+// CategorySubtree возвращает id категории и всех её потомков
+func CategorySubtree(categories []Category, id int) map[int]bool {
+	subtree := map[int]bool{id: true}
+	for grew := true; grew; {
+		grew = false
+		for _, category := range categories {
+			if category.ParentID != nil && subtree[*category.ParentID] && !subtree[category.ID] {
+				subtree[category.ID] = true
+				grew = true
+			}
+		}
+	}
+	return subtree
+}
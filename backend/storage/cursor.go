@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor — позиция в выборке ListAlgorithms: ключ сортировки последнего отданного алгоритма.
+// Следующая страница начинается строго после него, поэтому вставки и удаления между запросами
+// не сдвигают выдачу, как это бывает с OFFSET.
+type Cursor struct {
+	Sort      string    `json:"s,omitempty"`
+	ID        int       `json:"id"`
+	CreatedAt time.Time `json:"t"`
+	Rating    float64   `json:"r,omitempty"`
+	Rank      float64   `json:"k,omitempty"`
+	// Ключ сортировки по метаданным, см. SortValue
+	Value float64 `json:"v,omitempty"`
+}
+
+// CursorAt строит курсор, указывающий на algorithm в выборке с порядком sort
+func CursorAt(algorithm Algorithm, sort string) Cursor {
+	return Cursor{Sort: sort, ID: algorithm.ID, CreatedAt: algorithm.CreatedAt, Rating: algorithm.Rating, Rank: algorithm.Rank,
+		Value: SortValue(algorithm, sort)}
+}
+
+// Encode упаковывает курсор в непрозрачную для клиента строку
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(value string) (Cursor, error) {
+	var c Cursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || json.Unmarshal(data, &c) != nil || c.ID <= 0 {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// Follows сообщает, идёт ли algorithm после курсора в порядке c.Sort. Повторяет условия,
+// которые sqlstore добавляет в WHERE, для хранилищ, сортирующих в Go.
+func (c Cursor) Follows(algorithm Algorithm) bool {
+	switch c.Sort {
+	case SortNewest:
+		return algorithm.CreatedAt.Before(c.CreatedAt) || algorithm.CreatedAt.Equal(c.CreatedAt) && algorithm.ID < c.ID
+	case SortOldest:
+		return algorithm.CreatedAt.After(c.CreatedAt) || algorithm.CreatedAt.Equal(c.CreatedAt) && algorithm.ID > c.ID
+	case SortMostPopular:
+		return algorithm.Rating < c.Rating || algorithm.Rating == c.Rating && algorithm.ID > c.ID
+	case SortRelevance:
+		return algorithm.Rank < c.Rank || algorithm.Rank == c.Rank && algorithm.ID > c.ID
+	}
+	if desc, ok := MetadataSorts[c.Sort]; ok {
+		value := SortValue(algorithm, c.Sort)
+		if desc {
+			return value < c.Value || value == c.Value && algorithm.ID > c.ID
+		}
+		return value > c.Value || value == c.Value && algorithm.ID > c.ID
+	}
+	return algorithm.ID > c.ID
+}
+
+// Paginate вырезает из отсортированной выборки страницу по курсору и лимиту фильтра
+func Paginate(algorithms []Algorithm, filter AlgorithmFilter) []Algorithm {
+	if filter.After != nil {
+		start := 0
+		for start < len(algorithms) && !filter.After.Follows(algorithms[start]) {
+			start++
+		}
+		algorithms = algorithms[start:]
+	}
+	if filter.Limit > 0 && len(algorithms) > filter.Limit {
+		algorithms = algorithms[:filter.Limit]
+	}
+	return algorithms
+}
@@ -0,0 +1,302 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// insertRevision записывает новую ревизию с номером на единицу больше последнего. Вызывается под мьютексом.
+func (s *Store) insertRevision(algorithm storage.Algorithm, authorID int, message string) int {
+	history := s.revisions[algorithm.ID]
+	revision := storage.Revision{
+		AlgorithmID:         algorithm.ID,
+		Revision:            len(history) + 1,
+		Title:               algorithm.Title,
+		Code:                algorithm.Code,
+		Topic:               algorithm.Topic,
+		CategoryID:          algorithm.CategoryID,
+		ProgrammingLanguage: algorithm.ProgrammingLanguage,
+		AuthorID:            authorID,
+		Message:             message,
+		CreatedAt:           now(),
+	}
+	s.revisions[algorithm.ID] = append(history, revision)
+	return revision.Revision
+}
+
+// withAuthor подставляет имя автора ревизии и итог её проверки на момент чтения, как JOIN в SQL
+func (s *Store) withAuthor(revision storage.Revision) storage.Revision {
+	revision.AuthorUsername = s.users[revision.AuthorID].Username
+	revision.Verification = s.testRuns[revision.AlgorithmID][revision.Revision].Status
+	return revision
+}
+
+func (s *Store) CreateAlgorithm(ctx context.Context, algorithm *storage.Algorithm, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	algorithm.ID = s.nextID("algorithms")
+	algorithm.CreatedAt = now()
+	algorithm.Tags = s.linkTags(algorithm.Tags)
+	algorithm.Prerequisites = prerequisiteList(algorithm.Prerequisites)
+	s.algorithms[algorithm.ID] = *algorithm
+	s.insertRevision(*algorithm, algorithm.UserID, message)
+	return nil
+}
+
+func (s *Store) GetAlgorithm(ctx context.Context, id int, trashed bool) (storage.Algorithm, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	algorithm, ok := s.algorithms[id]
+	if !ok || (algorithm.DeletedAt != nil) != trashed {
+		return storage.Algorithm{}, storage.ErrNotFound
+	}
+	return algorithm, nil
+}
+
+func (s *Store) ListAlgorithms(ctx context.Context, filter storage.AlgorithmFilter) ([]storage.Algorithm, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	terms := storage.SearchTerms(filter.Query)
+	var subtree map[int]bool
+	if filter.CategoryID != 0 {
+		subtree = storage.CategorySubtree(s.categoryList(), filter.CategoryID)
+	}
+	var algorithms []storage.Algorithm = []storage.Algorithm{}
+	for _, algorithm := range s.algorithms {
+		if len(terms) > 0 {
+			highlight, rank, ok := storage.MatchTerms(algorithm.Title, algorithm.Code, terms)
+			if !ok {
+				continue
+			}
+			algorithm.Highlight, algorithm.Rank = &highlight, rank
+		}
+		if filter.OmitCode {
+			algorithm.Code, algorithm.Description = "", ""
+		}
+		// Оценка кода отдаётся только с одним алгоритмом, как в sqlstore
+		algorithm.Analysis = nil
+		switch {
+		case algorithm.DeletedAt != nil,
+			filter.ViewerID != 0 && !algorithm.Approved && algorithm.UserID != filter.ViewerID,
+			filter.ID != 0 && algorithm.ID != filter.ID,
+			filter.UserID != 0 && algorithm.UserID != filter.UserID,
+			filter.Title != "" && !containsFold(algorithm.Title, filter.Title),
+			filter.Topic != "" && !containsFold(algorithm.Topic, filter.Topic),
+			subtree != nil && (algorithm.CategoryID == nil || !subtree[*algorithm.CategoryID]),
+			len(filter.Tags) > 0 && !hasTags(algorithm.Tags, filter.Tags, filter.TagMode),
+			filter.ProgrammingLanguage != "" && !containsFold(algorithm.ProgrammingLanguage, filter.ProgrammingLanguage),
+			filter.ModerationStatus != "" && algorithm.ModerationStatus != filter.ModerationStatus,
+			filter.DifficultyMin != 0 && (algorithm.Difficulty == 0 || int(algorithm.Difficulty) < filter.DifficultyMin),
+			filter.DifficultyMax != 0 && (algorithm.Difficulty == 0 || int(algorithm.Difficulty) > filter.DifficultyMax),
+			filter.TimeComplexity != "" && algorithm.TimeComplexity != filter.TimeComplexity,
+			filter.SpaceComplexity != "" && algorithm.SpaceComplexity != filter.SpaceComplexity,
+			filter.MaxTimeRank != nil && (algorithm.TimeComplexity == "" || storage.ComplexityRank(algorithm.TimeComplexity) > *filter.MaxTimeRank),
+			filter.MaxSpaceRank != nil && (algorithm.SpaceComplexity == "" || storage.ComplexityRank(algorithm.SpaceComplexity) > *filter.MaxSpaceRank),
+			filter.PrerequisiteID != 0 && !containsInt(algorithm.Prerequisites, filter.PrerequisiteID),
+			filter.Verified != nil && (algorithm.VerificationStatus == storage.VerificationPassed) != *filter.Verified:
+			continue
+		}
+		algorithms = append(algorithms, algorithm)
+	}
+
+	less := func(i, j int) bool { return algorithms[i].ID < algorithms[j].ID }
+	switch filter.Sort {
+	case storage.SortNewest:
+		less = func(i, j int) bool {
+			if !algorithms[i].CreatedAt.Equal(algorithms[j].CreatedAt) {
+				return algorithms[i].CreatedAt.After(algorithms[j].CreatedAt)
+			}
+			return algorithms[i].ID > algorithms[j].ID
+		}
+	case storage.SortOldest:
+		less = func(i, j int) bool {
+			if !algorithms[i].CreatedAt.Equal(algorithms[j].CreatedAt) {
+				return algorithms[i].CreatedAt.Before(algorithms[j].CreatedAt)
+			}
+			return algorithms[i].ID < algorithms[j].ID
+		}
+	case storage.SortMostPopular:
+		less = func(i, j int) bool {
+			if algorithms[i].Rating != algorithms[j].Rating {
+				return algorithms[i].Rating > algorithms[j].Rating
+			}
+			return algorithms[i].ID < algorithms[j].ID
+		}
+	case storage.SortRelevance:
+		less = func(i, j int) bool {
+			if algorithms[i].Rank != algorithms[j].Rank {
+				return algorithms[i].Rank > algorithms[j].Rank
+			}
+			return algorithms[i].ID < algorithms[j].ID
+		}
+	case storage.SortEasiest, storage.SortHardest, storage.SortFastest, storage.SortLeastMemory:
+		desc := storage.MetadataSorts[filter.Sort]
+		less = func(i, j int) bool {
+			a, b := storage.SortValue(algorithms[i], filter.Sort), storage.SortValue(algorithms[j], filter.Sort)
+			if a != b {
+				return a < b != desc
+			}
+			return algorithms[i].ID < algorithms[j].ID
+		}
+	}
+	sort.Slice(algorithms, less)
+	return storage.Paginate(algorithms, filter), nil
+}
+
+func (s *Store) CountAlgorithms(ctx context.Context, filter storage.AlgorithmFilter) (int, error) {
+	filter.Limit, filter.After = 0, nil
+	algorithms, err := s.ListAlgorithms(ctx, filter)
+	return len(algorithms), err
+}
+
+func (s *Store) ReviseAlgorithm(ctx context.Context, id, authorID int, message string, update func(*storage.Algorithm) error) (storage.Algorithm, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.algorithms[id]
+	if !ok || current.DeletedAt != nil {
+		return storage.Algorithm{}, 0, storage.ErrNotFound
+	}
+
+	algorithm := current
+	if err := update(&algorithm); err != nil {
+		return storage.Algorithm{}, 0, err
+	}
+	algorithm.ID, algorithm.UserID, algorithm.CreatedAt, algorithm.DeletedAt = current.ID, current.UserID, current.CreatedAt, nil
+	algorithm.Approved = false
+	algorithm.ModerationStatus = storage.ModerationPending
+	algorithm.ModerationComment = ""
+	algorithm.VerificationStatus = ""
+	algorithm.Tags = s.linkTags(algorithm.Tags)
+	algorithm.Prerequisites = prerequisiteList(algorithm.Prerequisites)
+
+	s.algorithms[id] = algorithm
+	revision := s.insertRevision(algorithm, authorID, message)
+	return algorithm, revision, nil
+}
+
+func (s *Store) ModerateAlgorithm(ctx context.Context, id, moderatorID int, status, comment string) (storage.Algorithm, storage.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	algorithm, ok := s.algorithms[id]
+	if !ok || algorithm.DeletedAt != nil {
+		return storage.Algorithm{}, storage.User{}, storage.ErrNotFound
+	}
+	author, ok := s.users[algorithm.UserID]
+	if !ok {
+		return storage.Algorithm{}, storage.User{}, storage.ErrNotFound
+	}
+
+	algorithm.ModerationStatus = status
+	algorithm.Approved = status == storage.ModerationApproved
+	algorithm.ModerationComment = comment
+	s.algorithms[id] = algorithm
+	return algorithm, author.User, nil
+}
+
+func (s *Store) TrashAlgorithm(ctx context.Context, id int, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	algorithm, ok := s.algorithms[id]
+	if !ok || algorithm.DeletedAt != nil {
+		return storage.ErrNotFound
+	}
+	at = at.UTC()
+	algorithm.DeletedAt = &at
+	s.algorithms[id] = algorithm
+	return nil
+}
+
+func (s *Store) RestoreAlgorithm(ctx context.Context, id int) (storage.Algorithm, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	algorithm, ok := s.algorithms[id]
+	if !ok || algorithm.DeletedAt == nil {
+		return storage.Algorithm{}, storage.ErrNotFound
+	}
+	algorithm.DeletedAt = nil
+	s.algorithms[id] = algorithm
+	return algorithm, nil
+}
+
+func (s *Store) ListTrash(ctx context.Context, userID int) ([]storage.Algorithm, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var trash []storage.Algorithm = []storage.Algorithm{}
+	for _, algorithm := range s.algorithms {
+		if algorithm.DeletedAt != nil && (userID == 0 || algorithm.UserID == userID) {
+			algorithm.Analysis = nil
+			trash = append(trash, algorithm)
+		}
+	}
+	sort.Slice(trash, func(i, j int) bool { return trash[i].DeletedAt.After(*trash[j].DeletedAt) })
+	return trash, nil
+}
+
+func (s *Store) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for id, algorithm := range s.algorithms {
+		if algorithm.DeletedAt != nil && algorithm.DeletedAt.Before(before) {
+			delete(s.algorithms, id)
+			delete(s.revisions, id)
+			delete(s.ratings, id)
+			for commentID, comment := range s.comments {
+				if comment.AlgorithmID == id {
+					delete(s.comments, commentID)
+				}
+			}
+			purged++
+		}
+	}
+	// Как ON DELETE CASCADE в algorithm_prerequisites
+	for id, algorithm := range s.algorithms {
+		var kept []int
+		for _, prerequisite := range algorithm.Prerequisites {
+			if _, ok := s.algorithms[prerequisite]; ok {
+				kept = append(kept, prerequisite)
+			}
+		}
+		algorithm.Prerequisites = prerequisiteList(kept)
+		s.algorithms[id] = algorithm
+	}
+	return purged, nil
+}
+
+func (s *Store) ListRevisions(ctx context.Context, algorithmID int) ([]storage.Revision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history := s.revisions[algorithmID]
+	var revisions []storage.Revision = []storage.Revision{}
+	for i := len(history) - 1; i >= 0; i-- {
+		revision := s.withAuthor(history[i])
+		revision.Code = ""
+		revisions = append(revisions, revision)
+	}
+	return revisions, nil
+}
+
+func (s *Store) GetRevision(ctx context.Context, algorithmID, revision int) (storage.Revision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	algorithm, ok := s.algorithms[algorithmID]
+	history := s.revisions[algorithmID]
+	if !ok || algorithm.DeletedAt != nil || revision < 1 || revision > len(history) {
+		return storage.Revision{}, storage.ErrNotFound
+	}
+	return s.withAuthor(history[revision-1]), nil
+}
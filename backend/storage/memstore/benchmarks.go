@@ -0,0 +1,128 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+func (s *Store) CreateBenchmark(ctx context.Context, benchmark *storage.Benchmark) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	benchmark.ID = s.nextID("benchmarks")
+	benchmark.Status = storage.BenchmarkPending
+	benchmark.CreatedAt = now()
+	for i := range benchmark.Algorithms {
+		benchmark.Algorithms[i].Status = storage.BenchmarkPending
+		benchmark.Algorithms[i].Measurements = []storage.BenchmarkMeasurement{}
+	}
+	s.benchmarks[benchmark.ID] = copyBenchmark(*benchmark, true)
+	return nil
+}
+
+func (s *Store) GetBenchmark(ctx context.Context, id int) (storage.Benchmark, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	benchmark, ok := s.benchmarks[id]
+	if !ok {
+		return storage.Benchmark{}, storage.ErrNotFound
+	}
+	return copyBenchmark(benchmark, true), nil
+}
+
+func (s *Store) ListBenchmarks(ctx context.Context, userID int) ([]storage.Benchmark, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	benchmarks := []storage.Benchmark{}
+	for _, benchmark := range s.benchmarks {
+		if benchmark.UserID == userID {
+			benchmarks = append(benchmarks, copyBenchmark(benchmark, false))
+		}
+	}
+	sort.Slice(benchmarks, func(i, j int) bool { return benchmarks[i].ID > benchmarks[j].ID })
+	return benchmarks, nil
+}
+
+func (s *Store) ListUnfinishedBenchmarks(ctx context.Context) ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []int
+	for id, benchmark := range s.benchmarks {
+		if benchmark.Status == storage.BenchmarkPending || benchmark.Status == storage.BenchmarkRunning {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+func (s *Store) StartBenchmark(ctx context.Context, id int, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	benchmark, ok := s.benchmarks[id]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	benchmark.Status = storage.BenchmarkRunning
+	benchmark.StartedAt = &at
+	s.benchmarks[id] = benchmark
+	return nil
+}
+
+func (s *Store) SaveBenchmarkAlgorithm(ctx context.Context, benchmarkID int, algorithm storage.BenchmarkAlgorithm) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	benchmark, ok := s.benchmarks[benchmarkID]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	for i, current := range benchmark.Algorithms {
+		if current.AlgorithmID == algorithm.AlgorithmID {
+			// Название, язык и заявленные оценки записываются только при создании замера
+			algorithm.Title, algorithm.ProgrammingLanguage = current.Title, current.ProgrammingLanguage
+			algorithm.TimeComplexity, algorithm.SpaceComplexity = current.TimeComplexity, current.SpaceComplexity
+			algorithm.Measurements = append([]storage.BenchmarkMeasurement{}, algorithm.Measurements...)
+			benchmark.Algorithms[i] = algorithm
+			return nil
+		}
+	}
+	return storage.ErrNotFound
+}
+
+func (s *Store) FinishBenchmark(ctx context.Context, id int, status, errorMessage string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	benchmark, ok := s.benchmarks[id]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	benchmark.Status, benchmark.Error, benchmark.FinishedAt = status, errorMessage, &at
+	s.benchmarks[id] = benchmark
+	return nil
+}
+
+// copyBenchmark копирует срезы замера; без measurements результаты по размерам не копируются,
+// как в ListBenchmarks у sqlstore
+func copyBenchmark(benchmark storage.Benchmark, measurements bool) storage.Benchmark {
+	benchmark.Sizes = append([]int{}, benchmark.Sizes...)
+	algorithms := make([]storage.BenchmarkAlgorithm, len(benchmark.Algorithms))
+	for i, algorithm := range benchmark.Algorithms {
+		if measurements {
+			algorithm.Measurements = append([]storage.BenchmarkMeasurement{}, algorithm.Measurements...)
+		} else {
+			algorithm.Measurements = []storage.BenchmarkMeasurement{}
+		}
+		algorithms[i] = algorithm
+	}
+	benchmark.Algorithms = algorithms
+	return benchmark
+}
@@ -0,0 +1,110 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// categoryList возвращает категории, упорядоченные как в SQL. Вызывается под мьютексом.
+func (s *Store) categoryList() []storage.Category {
+	var categories []storage.Category = []storage.Category{}
+	for _, category := range s.categories {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		a, b := strings.ToLower(categories[i].Name), strings.ToLower(categories[j].Name)
+		return a < b || a == b && categories[i].ID < categories[j].ID
+	})
+	return categories
+}
+
+// checkSiblingSlug возвращает ErrDuplicate, если у родителя уже есть другая категория с тем же slug
+func (s *Store) checkSiblingSlug(category storage.Category) error {
+	for _, other := range s.categories {
+		sameParent := (other.ParentID == nil && category.ParentID == nil) ||
+			(other.ParentID != nil && category.ParentID != nil && *other.ParentID == *category.ParentID)
+		if other.ID != category.ID && sameParent && other.Slug == category.Slug {
+			return storage.ErrDuplicate
+		}
+	}
+	return nil
+}
+
+func (s *Store) ListCategories(ctx context.Context) ([]storage.Category, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.categoryList(), nil
+}
+
+func (s *Store) GetCategory(ctx context.Context, id int) (storage.Category, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	category, ok := s.categories[id]
+	if !ok {
+		return storage.Category{}, storage.ErrNotFound
+	}
+	return category, nil
+}
+
+func (s *Store) CreateCategory(ctx context.Context, category *storage.Category) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	category.Slug = storage.CategorySlug(category.Name)
+	if err := s.checkSiblingSlug(*category); err != nil {
+		return err
+	}
+	category.ID = s.nextID("categories")
+	category.Children = nil
+	s.categories[category.ID] = *category
+	return nil
+}
+
+func (s *Store) UpdateCategory(ctx context.Context, category storage.Category) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.categories[category.ID]; !ok {
+		return storage.ErrNotFound
+	}
+	category.Slug = storage.CategorySlug(category.Name)
+	if err := s.checkSiblingSlug(category); err != nil {
+		return err
+	}
+	category.Children = nil
+	s.categories[category.ID] = category
+
+	for id, algorithm := range s.algorithms {
+		if algorithm.CategoryID != nil && *algorithm.CategoryID == category.ID {
+			algorithm.Topic = category.Name
+			s.algorithms[id] = algorithm
+		}
+	}
+	return nil
+}
+
+func (s *Store) DeleteCategory(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.categories[id]; !ok {
+		return storage.ErrNotFound
+	}
+	for _, category := range s.categories {
+		if category.ParentID != nil && *category.ParentID == id {
+			return storage.ErrInUse
+		}
+	}
+	for _, algorithm := range s.algorithms {
+		if algorithm.CategoryID != nil && *algorithm.CategoryID == id {
+			return storage.ErrInUse
+		}
+	}
+	delete(s.categories, id)
+	return nil
+}
@@ -0,0 +1,118 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// withCommentAuthor подставляет имя автора комментария на момент чтения, как JOIN в SQL
+func (s *Store) withCommentAuthor(comment storage.Comment) storage.Comment {
+	comment.AuthorUsername = s.users[comment.UserID].Username
+	return comment
+}
+
+// rootOf возвращает id корня ветки комментария
+func (s *Store) rootOf(comment storage.Comment) int {
+	for comment.ParentID != nil {
+		comment = s.comments[*comment.ParentID]
+	}
+	return comment.ID
+}
+
+func (s *Store) CreateComment(ctx context.Context, comment *storage.Comment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.algorithms[comment.AlgorithmID]; !ok {
+		return storage.ErrNotFound
+	}
+	if comment.ParentID != nil {
+		if _, ok := s.comments[*comment.ParentID]; !ok {
+			return storage.ErrNotFound
+		}
+	}
+
+	comment.ID = s.nextID("algorithm_comments")
+	comment.CreatedAt = now()
+	stored := *comment
+	stored.AuthorUsername, stored.Replies = "", nil
+	s.comments[comment.ID] = stored
+	return nil
+}
+
+func (s *Store) GetComment(ctx context.Context, id int) (storage.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comment, ok := s.comments[id]
+	if !ok {
+		return storage.Comment{}, storage.ErrNotFound
+	}
+	return s.withCommentAuthor(comment), nil
+}
+
+func (s *Store) ListComments(ctx context.Context, filter storage.CommentFilter) ([]storage.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var roots []storage.Comment = []storage.Comment{}
+	for _, comment := range s.comments {
+		if comment.AlgorithmID != filter.AlgorithmID || comment.ParentID != nil || comment.ID <= filter.AfterID {
+			continue
+		}
+		if filter.InlineOnly && comment.LineStart == nil {
+			continue
+		}
+		if filter.Revision != 0 && (comment.Revision == nil || *comment.Revision != filter.Revision) {
+			continue
+		}
+		roots = append(roots, s.withCommentAuthor(comment))
+	}
+	sort.Slice(roots, func(i, j int) bool { return roots[i].ID < roots[j].ID })
+	if filter.Limit > 0 && len(roots) > filter.Limit {
+		roots = roots[:filter.Limit]
+	}
+
+	inPage := map[int]bool{}
+	for _, root := range roots {
+		inPage[root.ID] = true
+	}
+	var replies []storage.Comment
+	for _, comment := range s.comments {
+		if comment.ParentID != nil && inPage[s.rootOf(comment)] {
+			replies = append(replies, s.withCommentAuthor(comment))
+		}
+	}
+	sort.Slice(replies, func(i, j int) bool { return replies[i].ID < replies[j].ID })
+	return storage.NestReplies(roots, replies), nil
+}
+
+func (s *Store) UpdateComment(ctx context.Context, id int, body string, editedAt time.Time) (storage.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comment, ok := s.comments[id]
+	if !ok || comment.Deleted {
+		return storage.Comment{}, storage.ErrNotFound
+	}
+	editedAt = editedAt.UTC()
+	comment.Body, comment.EditedAt = body, &editedAt
+	s.comments[id] = comment
+	return s.withCommentAuthor(comment), nil
+}
+
+func (s *Store) DeleteComment(ctx context.Context, id, deletedBy int, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comment, ok := s.comments[id]
+	if !ok || comment.Deleted {
+		return storage.ErrNotFound
+	}
+	comment.Deleted, comment.DeletedBy = true, &deletedBy
+	s.comments[id] = comment
+	return nil
+}
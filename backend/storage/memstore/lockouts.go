@@ -0,0 +1,126 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+func (s *Store) ClaimLoginAttempt(ctx context.Context, scope, subject string, at, resetBefore time.Time,
+	delay func(failures int) time.Duration) (storage.LoginThrottle, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := [2]string{scope, subject}
+	throttle, ok := s.logins[key]
+	if !ok {
+		throttle.Scope, throttle.Subject = scope, subject
+	}
+	if next := throttle.NextAttemptAt(resetBefore, delay); next.After(at) {
+		return throttle.LoginThrottle, next.Sub(at), nil
+	}
+	if throttle.LastFailureAt.Before(resetBefore) {
+		throttle.Failures = 0
+	}
+	throttle.Failures++
+	// Одновременная попытка могла записать время позже нашего
+	if at.After(throttle.LastFailureAt) {
+		throttle.LastFailureAt = at
+	}
+	s.logins[key] = throttle
+	return throttle.LoginThrottle, 0, nil
+}
+
+func (s *Store) RefundLoginAttempt(ctx context.Context, scope, subject string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := [2]string{scope, subject}
+	throttle, ok := s.logins[key]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	if throttle.Failures <= 1 && throttle.LockedUntil == nil {
+		delete(s.logins, key)
+		return nil
+	}
+	throttle.Failures = max(throttle.Failures-1, 0)
+	s.logins[key] = throttle
+	return nil
+}
+
+func (s *Store) LockLogin(ctx context.Context, scope, subject string, until time.Time, unlockTokenHash string, message *storage.OutboxEmail) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := [2]string{scope, subject}
+	throttle, ok := s.logins[key]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	throttle.LockedUntil = &until
+	throttle.unlockTokenHash = unlockTokenHash
+	s.logins[key] = throttle
+	if message != nil {
+		s.enqueueEmail(message)
+	}
+	return nil
+}
+
+func (s *Store) UnlockLogin(ctx context.Context, unlockTokenHash string, at time.Time) (storage.LoginThrottle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, throttle := range s.logins {
+		if unlockTokenHash != "" && throttle.unlockTokenHash == unlockTokenHash && throttle.LockedUntil != nil && throttle.LockedUntil.After(at) {
+			delete(s.logins, key)
+			return throttle.LoginThrottle, nil
+		}
+	}
+	return storage.LoginThrottle{}, storage.ErrNotFound
+}
+
+func (s *Store) ClearLoginThrottle(ctx context.Context, scope, subject string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := [2]string{scope, subject}
+	if _, ok := s.logins[key]; !ok {
+		return storage.ErrNotFound
+	}
+	delete(s.logins, key)
+	return nil
+}
+
+func (s *Store) ListLoginThrottles(ctx context.Context, now, resetBefore time.Time, limit int) ([]storage.LoginThrottle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	throttles := []storage.LoginThrottle{}
+	for _, throttle := range s.logins {
+		if !throttle.LastFailureAt.Before(resetBefore) || throttle.LockedUntil != nil && throttle.LockedUntil.After(now) {
+			throttles = append(throttles, throttle.LoginThrottle)
+		}
+	}
+	sort.Slice(throttles, func(i, j int) bool { return throttles[i].LastFailureAt.After(throttles[j].LastFailureAt) })
+	if len(throttles) > limit {
+		throttles = throttles[:limit]
+	}
+	return throttles, nil
+}
+
+func (s *Store) PurgeLoginThrottles(ctx context.Context, before, now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for key, throttle := range s.logins {
+		if throttle.LastFailureAt.Before(before) && (throttle.LockedUntil == nil || !throttle.LockedUntil.After(now)) {
+			delete(s.logins, key)
+			purged++
+		}
+	}
+	return purged, nil
+}
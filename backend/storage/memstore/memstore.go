@@ -0,0 +1,469 @@
+// Package memstore хранит данные в памяти процесса. Подходит для разработки и демонстрации:
+// сервер запускается без базы данных, но всё теряется при перезапуске.
+package memstore
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+type verificationToken struct {
+	userID    int
+	email     string
+	username  string
+	createdAt time.Time
+	expiresAt time.Time
+	used      bool
+}
+
+type resetToken struct {
+	user      storage.User
+	createdAt time.Time
+}
+
+type loginThrottle struct {
+	storage.LoginThrottle
+	unlockTokenHash string
+}
+
+type refreshToken struct {
+	sessionID int
+	expiresAt time.Time
+	used      bool
+}
+
+// Store — реализация storage.Store на map под одним мьютексом. Методы возвращают копии,
+// поэтому изменения у вызывающего не попадают в хранилище в обход методов.
+type Store struct {
+	mu sync.Mutex
+
+	lastID map[string]int
+
+	users         map[int]storage.UserRecord
+	algorithms    map[int]storage.Algorithm
+	revisions     map[int][]storage.Revision
+	categories    map[int]storage.Category
+	tags          map[string]string // имя тега → имя основного тега, у основных пусто
+	ratings       map[int]map[int]int
+	comments      map[int]storage.Comment
+	testCases     map[int]storage.TestCase
+	testRuns      map[int]map[int]storage.Verification // алгоритм → ревизия → результат прогона тестов
+	benchmarks    map[int]storage.Benchmark
+	outbox        map[int]storage.OutboxEmail
+	verifications map[string]verificationToken // хеш токена → токен
+	resets        map[string]resetToken
+	sessions      map[int]storage.Session
+	refreshTokens map[string]refreshToken
+	logins        map[[2]string]loginThrottle // {scope, subject} → счётчик неудачных входов
+}
+
+var _ storage.Store = (*Store)(nil)
+
+// New возвращает пустое хранилище, в котором есть только стандартные категории
+func New() *Store {
+	s := &Store{
+		lastID:        map[string]int{},
+		users:         map[int]storage.UserRecord{},
+		algorithms:    map[int]storage.Algorithm{},
+		revisions:     map[int][]storage.Revision{},
+		categories:    map[int]storage.Category{},
+		tags:          map[string]string{},
+		ratings:       map[int]map[int]int{},
+		comments:      map[int]storage.Comment{},
+		testCases:     map[int]storage.TestCase{},
+		testRuns:      map[int]map[int]storage.Verification{},
+		benchmarks:    map[int]storage.Benchmark{},
+		outbox:        map[int]storage.OutboxEmail{},
+		verifications: map[string]verificationToken{},
+		resets:        map[string]resetToken{},
+		sessions:      map[int]storage.Session{},
+		refreshTokens: map[string]refreshToken{},
+		logins:        map[[2]string]loginThrottle{},
+	}
+	for _, name := range storage.DefaultCategories {
+		category := storage.Category{Name: name}
+		s.CreateCategory(context.Background(), &category)
+	}
+	return s
+}
+
+func (s *Store) Close() error {
+	return nil
+}
+
+// nextID выдаёт id так же, как последовательность в SQL: возрастающие и без повторов
+func (s *Store) nextID(table string) int {
+	s.lastID[table]++
+	return s.lastID[table]
+}
+
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// Users
+
+func (s *Store) UsernameExists(ctx context.Context, username string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.users {
+		if user.Username == username {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *Store) ConfirmedEmailExists(ctx context.Context, email string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.users {
+		if user.Email == email && user.Confirmed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *Store) CreateUser(ctx context.Context, user *storage.User, passwordHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.createUser(user, passwordHash)
+	return nil
+}
+
+func (s *Store) RegisterUser(ctx context.Context, user *storage.User, passwordHash, verificationTokenHash string, tokenExpiresAt time.Time, message *storage.OutboxEmail) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.createUser(user, passwordHash)
+	s.saveVerificationToken(*user, verificationTokenHash, tokenExpiresAt)
+	s.enqueueEmail(message)
+	return nil
+}
+
+func (s *Store) createUser(user *storage.User, passwordHash string) {
+	user.ID = s.nextID("users")
+	if user.Role == "" {
+		user.Role = storage.DefaultRole
+	}
+	if user.Locale == "" {
+		user.Locale = storage.DefaultLocale
+	}
+	record := storage.UserRecord{User: *user, PasswordHash: passwordHash}
+	record.Password = ""
+	s.users[user.ID] = record
+}
+
+func (s *Store) GetUser(ctx context.Context, id int) (storage.UserRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return storage.UserRecord{}, storage.ErrNotFound
+	}
+	return user, nil
+}
+
+func (s *Store) GetUserByUsername(ctx context.Context, username string) (storage.UserRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return storage.UserRecord{}, storage.ErrNotFound
+}
+
+func (s *Store) ListUsers(ctx context.Context) ([]storage.UserRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var users []storage.UserRecord = []storage.UserRecord{}
+	for _, user := range s.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (s *Store) ConfirmUser(ctx context.Context, tokenHash string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	verification, ok := s.verifications[tokenHash]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	if verification.used {
+		return storage.ErrTokenReused
+	}
+	if !verification.expiresAt.After(at) {
+		return storage.ErrTokenExpired
+	}
+	user, ok := s.users[verification.userID]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	user.Confirmed = true
+	s.users[user.ID] = user
+	verification.used = true
+	s.verifications[tokenHash] = verification
+
+	for hash, other := range s.verifications {
+		if other.email == verification.email && !other.used {
+			delete(s.verifications, hash)
+		}
+	}
+	for id, other := range s.users {
+		if other.Email == verification.email && !other.Confirmed {
+			delete(s.users, id)
+			for hash, token := range s.verifications {
+				if token.userID == id {
+					delete(s.verifications, hash)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Store) UpdatePassword(ctx context.Context, userID int, passwordHash string, changedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	changedAt = changedAt.UTC()
+	user.PasswordHash = passwordHash
+	user.PasswordChangedAt = &changedAt
+	s.users[userID] = user
+	return nil
+}
+
+func (s *Store) SetRole(ctx context.Context, userID int, role string) (storage.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return storage.User{}, storage.ErrNotFound
+	}
+	user.Role = role
+	s.users[userID] = user
+	return user.User, nil
+}
+
+func (s *Store) SetLocale(ctx context.Context, userID int, locale string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	user.Locale = locale
+	s.users[userID] = user
+	return nil
+}
+
+// Tokens
+
+func (s *Store) SaveVerificationToken(ctx context.Context, user storage.User, tokenHash string, expiresAt time.Time, message *storage.OutboxEmail) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.saveVerificationToken(user, tokenHash, expiresAt)
+	s.enqueueEmail(message)
+	return nil
+}
+
+func (s *Store) saveVerificationToken(user storage.User, tokenHash string, expiresAt time.Time) {
+	for existing, verification := range s.verifications {
+		if verification.userID == user.ID && !verification.used {
+			delete(s.verifications, existing)
+		}
+	}
+	s.verifications[tokenHash] = verificationToken{userID: user.ID, email: user.Email, username: user.Username,
+		createdAt: time.Now(), expiresAt: expiresAt}
+}
+
+func (s *Store) LastVerificationTokenAt(ctx context.Context, userID int) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var last time.Time
+	for _, verification := range s.verifications {
+		if verification.userID == userID && verification.createdAt.After(last) {
+			last = verification.createdAt
+		}
+	}
+	if last.IsZero() {
+		return time.Time{}, storage.ErrNotFound
+	}
+	return last, nil
+}
+
+func (s *Store) SaveResetToken(ctx context.Context, user storage.User, token string, createdAt time.Time, message *storage.OutboxEmail) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for existing, reset := range s.resets {
+		if reset.user.ID == user.ID && reset.user.Email == user.Email {
+			delete(s.resets, existing)
+		}
+	}
+	s.resets[token] = resetToken{user: user, createdAt: createdAt}
+	s.enqueueEmail(message)
+	return nil
+}
+
+func (s *Store) FindResetToken(ctx context.Context, token, username, email string, notBefore time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reset, ok := s.resets[token]
+	if !ok || reset.user.Username != username || reset.user.Email != email || !reset.createdAt.After(notBefore) {
+		return 0, storage.ErrNotFound
+	}
+	return reset.user.ID, nil
+}
+
+func (s *Store) DeleteResetToken(ctx context.Context, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.resets, token)
+	return nil
+}
+
+// Sessions
+
+func (s *Store) CreateSession(ctx context.Context, session *storage.Session, refreshTokenHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = now()
+	}
+	session.ID = s.nextID("sessions")
+	session.LastUsedAt = session.CreatedAt
+	s.sessions[session.ID] = *session
+	s.refreshTokens[refreshTokenHash] = refreshToken{sessionID: session.ID, expiresAt: session.ExpiresAt}
+	return nil
+}
+
+func (s *Store) GetSession(ctx context.Context, id, userID int) (storage.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok || session.UserID != userID {
+		return storage.Session{}, storage.ErrNotFound
+	}
+	return session, nil
+}
+
+func (s *Store) TouchSession(ctx context.Context, id int, now, staleBefore time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if ok && session.LastUsedAt.Before(staleBefore) {
+		session.LastUsedAt = now
+		s.sessions[id] = session
+	}
+	return nil
+}
+
+func (s *Store) RotateRefreshToken(ctx context.Context, oldHash, newHash string, expiresAt time.Time, ip, userAgent string) (storage.Session, storage.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.refreshTokens[oldHash]
+	if !ok {
+		return storage.Session{}, storage.User{}, storage.ErrNotFound
+	}
+	session := s.sessions[token.sessionID]
+	user := s.users[session.UserID].User
+	current := now()
+
+	if token.used {
+		if session.RevokedAt == nil {
+			session.RevokedAt = &current
+			s.sessions[session.ID] = session
+		}
+		return session, user, storage.ErrTokenReused
+	}
+	if session.RevokedAt != nil || !token.expiresAt.After(current) {
+		return session, user, storage.ErrTokenExpired
+	}
+
+	token.used = true
+	s.refreshTokens[oldHash] = token
+	s.refreshTokens[newHash] = refreshToken{sessionID: session.ID, expiresAt: expiresAt}
+
+	session.ExpiresAt = expiresAt
+	session.LastUsedAt = current
+	session.IP = ip
+	session.UserAgent = userAgent
+	s.sessions[session.ID] = session
+	return session, user, nil
+}
+
+func (s *Store) ListActiveSessions(ctx context.Context, userID int, now time.Time) ([]storage.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var sessions []storage.Session = []storage.Session{}
+	for _, session := range s.sessions {
+		if session.UserID == userID && session.RevokedAt == nil && session.ExpiresAt.After(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt) })
+	return sessions, nil
+}
+
+func (s *Store) RevokeSession(ctx context.Context, id, userID int, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok || session.UserID != userID || session.RevokedAt != nil {
+		return storage.ErrNotFound
+	}
+	session.RevokedAt = &at
+	s.sessions[id] = session
+	return nil
+}
+
+func (s *Store) RevokeUserSessions(ctx context.Context, userID int, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, session := range s.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &at
+			s.sessions[id] = session
+		}
+	}
+	return nil
+}
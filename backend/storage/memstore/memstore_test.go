@@ -0,0 +1,12 @@
+package memstore
+
+import (
+	"testing"
+
+	"AlgorithmsOnlineLibrary/storage"
+	"AlgorithmsOnlineLibrary/storage/storagetest"
+)
+
+func TestStore(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Store { return New() })
+}
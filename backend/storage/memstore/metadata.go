@@ -0,0 +1,26 @@
+package memstore
+
+import "sort"
+
+// prerequisiteList убирает повторы и упорядочивает предварительные требования по id, как loadPrerequisites в sqlstore
+func prerequisiteList(ids []int) []int {
+	seen := map[int]bool{}
+	list := []int{}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			list = append(list, id)
+		}
+	}
+	sort.Ints(list)
+	return list
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+func (s *Store) enqueueEmail(email *storage.OutboxEmail) {
+	email.ID = s.nextID("email_outbox")
+	email.Status = storage.EmailPending
+	email.Attempts = 0
+	email.CreatedAt = now()
+	email.NextAttemptAt = email.CreatedAt
+	s.outbox[email.ID] = *email
+}
+
+func (s *Store) EnqueueEmail(ctx context.Context, email *storage.OutboxEmail) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.enqueueEmail(email)
+	return nil
+}
+
+func (s *Store) ClaimEmails(ctx context.Context, now, leaseUntil time.Time, limit int) ([]storage.OutboxEmail, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []storage.OutboxEmail
+	for _, email := range s.outbox {
+		if email.Status == storage.EmailPending && !email.NextAttemptAt.After(now) {
+			due = append(due, email)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextAttemptAt.Equal(due[j].NextAttemptAt) {
+			return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	for i := range due {
+		due[i].Attempts++
+		due[i].NextAttemptAt = leaseUntil
+		s.outbox[due[i].ID] = due[i]
+	}
+	return due, nil
+}
+
+func (s *Store) MarkEmailSent(ctx context.Context, id int, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	email, ok := s.outbox[id]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	email.Status = storage.EmailSent
+	email.SentAt = &at
+	email.LastError = ""
+	email.Body, email.HTMLBody = "", ""
+	s.outbox[id] = email
+	return nil
+}
+
+func (s *Store) MarkEmailFailed(ctx context.Context, id int, errorMessage string, retryAt *time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	email, ok := s.outbox[id]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	email.LastError = errorMessage
+	if retryAt == nil {
+		email.Status = storage.EmailDead
+	} else {
+		email.NextAttemptAt = *retryAt
+	}
+	s.outbox[id] = email
+	return nil
+}
+
+func (s *Store) GetEmail(ctx context.Context, id int) (storage.OutboxEmail, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	email, ok := s.outbox[id]
+	if !ok {
+		return storage.OutboxEmail{}, storage.ErrNotFound
+	}
+	return email, nil
+}
+
+func (s *Store) ListEmails(ctx context.Context, status string, limit int) ([]storage.OutboxEmail, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	emails := []storage.OutboxEmail{}
+	for _, email := range s.outbox {
+		if status == "" || email.Status == status {
+			emails = append(emails, email)
+		}
+	}
+	sort.Slice(emails, func(i, j int) bool { return emails[i].ID > emails[j].ID })
+	if len(emails) > limit {
+		emails = emails[:limit]
+	}
+	return emails, nil
+}
+
+func (s *Store) ResendEmail(ctx context.Context, id int, at time.Time) (storage.OutboxEmail, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	email, ok := s.outbox[id]
+	if !ok || email.Status != storage.EmailDead {
+		return storage.OutboxEmail{}, storage.ErrNotFound
+	}
+	email.Status = storage.EmailPending
+	email.Attempts = 0
+	email.NextAttemptAt = at
+	email.SentAt = nil
+	s.outbox[id] = email
+	return email, nil
+}
+
+func (s *Store) PurgeSentEmails(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for id, email := range s.outbox {
+		if email.Status == storage.EmailSent && email.SentAt.Before(before) {
+			delete(s.outbox, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
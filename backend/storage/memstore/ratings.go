@@ -0,0 +1,67 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// updateRatingSummary пересчитывает агрегаты оценок алгоритма. Вызывается под мьютексом.
+func (s *Store) updateRatingSummary(algorithm storage.Algorithm) storage.RatingSummary {
+	sum := 0
+	for _, score := range s.ratings[algorithm.ID] {
+		sum += score
+	}
+
+	summary := storage.RatingSummary{AlgorithmID: algorithm.ID, Count: len(s.ratings[algorithm.ID])}
+	if summary.Count > 0 {
+		summary.Average = float64(sum) / float64(summary.Count)
+	}
+	summary.Rating = storage.RatingScore(sum, summary.Count)
+
+	algorithm.Rating, algorithm.RatingAverage, algorithm.RatingCount = summary.Rating, summary.Average, summary.Count
+	s.algorithms[algorithm.ID] = algorithm
+	return summary
+}
+
+func (s *Store) RateAlgorithm(ctx context.Context, algorithmID, userID, score int, at time.Time) (storage.RatingSummary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	algorithm, ok := s.algorithms[algorithmID]
+	if !ok || algorithm.DeletedAt != nil {
+		return storage.RatingSummary{}, storage.ErrNotFound
+	}
+	if s.ratings[algorithmID] == nil {
+		s.ratings[algorithmID] = map[int]int{}
+	}
+	s.ratings[algorithmID][userID] = score
+	return s.updateRatingSummary(algorithm), nil
+}
+
+func (s *Store) DeleteRating(ctx context.Context, algorithmID, userID int) (storage.RatingSummary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	algorithm, ok := s.algorithms[algorithmID]
+	if !ok || algorithm.DeletedAt != nil {
+		return storage.RatingSummary{}, storage.ErrNotFound
+	}
+	if _, ok := s.ratings[algorithmID][userID]; !ok {
+		return storage.RatingSummary{}, storage.ErrNotFound
+	}
+	delete(s.ratings[algorithmID], userID)
+	return s.updateRatingSummary(algorithm), nil
+}
+
+func (s *Store) GetRating(ctx context.Context, algorithmID, userID int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	score, ok := s.ratings[algorithmID][userID]
+	if !ok {
+		return 0, storage.ErrNotFound
+	}
+	return score, nil
+}
@@ -0,0 +1,153 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// canonical возвращает основное имя тега. Вызывается под мьютексом.
+func (s *Store) canonical(name string) string {
+	if target := s.tags[name]; target != "" {
+		return target
+	}
+	return name
+}
+
+// linkTags создаёт недостающие теги и возвращает основные имена без повторов по алфавиту.
+// Вызывается под мьютексом.
+func (s *Store) linkTags(names []string) []string {
+	seen := map[string]bool{}
+	var linked []string = []string{}
+	for _, name := range names {
+		if _, ok := s.tags[name]; !ok {
+			s.tags[name] = ""
+		}
+		name = s.canonical(name)
+		if !seen[name] {
+			seen[name] = true
+			linked = append(linked, name)
+		}
+	}
+	sort.Strings(linked)
+	return linked
+}
+
+// hasTags проверяет фильтр по тегам: все wanted или, в режиме TagsAny, хотя бы один
+func hasTags(tags, wanted []string, mode string) bool {
+	matched := 0
+	for _, tag := range wanted {
+		for _, t := range tags {
+			if t == tag {
+				matched++
+				break
+			}
+		}
+	}
+	if mode == storage.TagsAny {
+		return matched > 0
+	}
+	return matched == len(wanted)
+}
+
+func (s *Store) ResolveTags(ctx context.Context, names []string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := map[string]bool{}
+	resolved := []string{}
+	for _, name := range names {
+		name = s.canonical(name)
+		if !seen[name] {
+			seen[name] = true
+			resolved = append(resolved, name)
+		}
+	}
+	return resolved, nil
+}
+
+func (s *Store) TagCounts(ctx context.Context, prefix string, limit int) ([]storage.TagCount, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Основные теги, у которых имя или один из синонимов начинается с prefix
+	matches := map[string]bool{}
+	for name := range s.tags {
+		if strings.HasPrefix(name, prefix) {
+			matches[s.canonical(name)] = true
+		}
+	}
+
+	counts := map[string]int{}
+	for _, algorithm := range s.algorithms {
+		if algorithm.DeletedAt != nil || !algorithm.Approved {
+			continue
+		}
+		for _, tag := range algorithm.Tags {
+			if matches[tag] {
+				counts[tag]++
+			}
+		}
+	}
+
+	var result []storage.TagCount = []storage.TagCount{}
+	for name, count := range counts {
+		result = append(result, storage.TagCount{Name: name, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Count > result[j].Count || result[i].Count == result[j].Count && result[i].Name < result[j].Name
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (s *Store) AddTagSynonym(ctx context.Context, tag, synonym string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tags[tag]; !ok {
+		s.tags[tag] = ""
+	}
+	target := s.canonical(tag)
+
+	current, exists := s.tags[synonym]
+	if synonym == target {
+		return storage.ErrDuplicate
+	}
+
+	// Основной тег сливается с target: алгоритмы и синонимы переходят к нему
+	if exists && current == "" {
+		for name, t := range s.tags {
+			if t == synonym {
+				s.tags[name] = target
+			}
+		}
+		for id, algorithm := range s.algorithms {
+			for i, t := range algorithm.Tags {
+				if t == synonym {
+					algorithm.Tags = append(append([]string{}, algorithm.Tags[:i]...), algorithm.Tags[i+1:]...)
+					algorithm.Tags = s.linkTags(append(algorithm.Tags, target))
+					s.algorithms[id] = algorithm
+					break
+				}
+			}
+		}
+	}
+	s.tags[synonym] = target
+	return nil
+}
+
+func (s *Store) RemoveTagSynonym(ctx context.Context, synonym string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tags[synonym] == "" {
+		return storage.ErrNotFound
+	}
+	delete(s.tags, synonym)
+	return nil
+}
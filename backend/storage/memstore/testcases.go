@@ -0,0 +1,84 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+func (s *Store) ListTestCases(ctx context.Context, algorithmID int, includeHidden bool) ([]storage.TestCase, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var testCases []storage.TestCase = []storage.TestCase{}
+	for _, testCase := range s.testCases {
+		if testCase.AlgorithmID == algorithmID && (includeHidden || !testCase.Hidden) {
+			testCases = append(testCases, testCase)
+		}
+	}
+	sort.Slice(testCases, func(i, j int) bool { return testCases[i].ID < testCases[j].ID })
+	return testCases, nil
+}
+
+func (s *Store) CreateTestCase(ctx context.Context, testCase *storage.TestCase) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.algorithms[testCase.AlgorithmID]; !ok {
+		return storage.ErrNotFound
+	}
+	testCase.ID = s.nextID("algorithm_test_cases")
+	testCase.CreatedAt = now()
+	s.testCases[testCase.ID] = *testCase
+	return nil
+}
+
+func (s *Store) UpdateTestCase(ctx context.Context, testCase *storage.TestCase) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.testCases[testCase.ID]
+	if !ok || current.AlgorithmID != testCase.AlgorithmID {
+		return storage.ErrNotFound
+	}
+	testCase.CreatedAt = current.CreatedAt
+	s.testCases[testCase.ID] = *testCase
+	return nil
+}
+
+func (s *Store) DeleteTestCase(ctx context.Context, algorithmID, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if testCase, ok := s.testCases[id]; !ok || testCase.AlgorithmID != algorithmID {
+		return storage.ErrNotFound
+	}
+	delete(s.testCases, id)
+	return nil
+}
+
+func (s *Store) SaveVerification(ctx context.Context, verification storage.Verification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	algorithm, ok := s.algorithms[verification.AlgorithmID]
+	if !ok {
+		return nil
+	}
+	if verification.Total == 0 {
+		delete(s.testRuns[verification.AlgorithmID], verification.Revision)
+		verification.Status = ""
+	} else {
+		if s.testRuns[verification.AlgorithmID] == nil {
+			s.testRuns[verification.AlgorithmID] = map[int]storage.Verification{}
+		}
+		s.testRuns[verification.AlgorithmID][verification.Revision] = verification
+	}
+	// Статус ревизии, которая уже не последняя, в алгоритм не попадает
+	if len(s.revisions[verification.AlgorithmID]) == verification.Revision {
+		algorithm.VerificationStatus = verification.Status
+		s.algorithms[verification.AlgorithmID] = algorithm
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"AlgorithmsOnlineLibrary/complexity"
+)
+
+// Ограничения метаданных алгоритма: длина описания в Markdown и число предварительных требований
+const (
+	MaxDescriptionLength = 20000
+	MaxPrerequisites     = 20
+)
+
+// Difficulty — сложность алгоритма от MinDifficulty до MaxDifficulty; 0 — не указана.
+// В JSON принимается число или уровень easy, medium, hard.
+type Difficulty int
+
+const (
+	MinDifficulty = 1
+	MaxDifficulty = 10
+)
+
+// Уровни сложности и соответствующие им диапазоны. Уровень из запроса сохраняется серединой диапазона.
+var difficultyLevels = map[string][2]int{
+	"easy":   {1, 3},
+	"medium": {4, 6},
+	"hard":   {7, 10},
+}
+
+// DifficultyRange переводит значение фильтра — уровень или число — в диапазон сложности
+func DifficultyRange(value string) (min, max int, err error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if bounds, ok := difficultyLevels[value]; ok {
+		return bounds[0], bounds[1], nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < MinDifficulty || n > MaxDifficulty {
+		return 0, 0, fmt.Errorf("difficulty must be easy, medium, hard or a number from %d to %d", MinDifficulty, MaxDifficulty)
+	}
+	return n, n, nil
+}
+
+func (d *Difficulty) UnmarshalJSON(data []byte) error {
+	var level string
+	if err := json.Unmarshal(data, &level); err == nil {
+		// Пустая строка, как и 0, снимает сложность
+		if level == "" {
+			*d = 0
+			return nil
+		}
+		min, max, err := DifficultyRange(level)
+		if err != nil {
+			return err
+		}
+		*d = Difficulty((min + max) / 2)
+		return nil
+	}
+
+	var n int
+	if err := json.Unmarshal(data, &n); err != nil || n != 0 && (n < MinDifficulty || n > MaxDifficulty) {
+		return fmt.Errorf("difficulty must be easy, medium, hard or a number from %d to %d", MinDifficulty, MaxDifficulty)
+	}
+	*d = Difficulty(n)
+	return nil
+}
+
+// Порядки сортировки по метаданным
+const (
+	SortEasiest     = "easiest"
+	SortHardest     = "hardest"
+	SortFastest     = "fastest"
+	SortLeastMemory = "least_memory"
+)
+
+// NoComplexityRank — ранг алгоритмов без оценки: при сортировке они идут после всех оценённых
+const NoComplexityRank = 1e13
+
+// MetadataSorts сообщает для порядков по метаданным, идут ли значения по убыванию
+var MetadataSorts = map[string]bool{
+	SortEasiest:     false,
+	SortHardest:     true,
+	SortFastest:     false,
+	SortLeastMemory: false,
+}
+
+// SortValue — ключ сортировки algorithm в порядке по метаданным. Алгоритмы без значения
+// получают ключ, который ставит их в конец выборки, как COALESCE в sqlstore.
+func SortValue(algorithm Algorithm, sort string) float64 {
+	switch sort {
+	case SortEasiest:
+		if algorithm.Difficulty == 0 {
+			return MaxDifficulty + 1
+		}
+		return float64(algorithm.Difficulty)
+	case SortHardest:
+		return float64(algorithm.Difficulty)
+	case SortFastest:
+		return ComplexityRank(algorithm.TimeComplexity)
+	case SortLeastMemory:
+		return ComplexityRank(algorithm.SpaceComplexity)
+	}
+	return 0
+}
+
+// ComplexityRank возвращает ранг роста оценки или NoComplexityRank, если оценки нет
+func ComplexityRank(expr string) float64 {
+	if expr == "" {
+		return NoComplexityRank
+	}
+	_, rank, err := complexity.Parse(expr)
+	if err != nil {
+		return NoComplexityRank
+	}
+	return rank
+}
+
+// Analysis — оценка кода анализатором на момент последнего изменения кода: метрики и
+// предложенные сложность и оценки времени и памяти
+type Analysis struct {
+	Analyzer       string     `json:"analyzer"`
+	Lines          int        `json:"lines"`
+	LoopDepth      int        `json:"loop_depth"`
+	Recursive      bool       `json:"recursive"`
+	Cyclomatic     int        `json:"cyclomatic_complexity"`
+	DataStructures []string   `json:"data_structures"`
+	Difficulty     Difficulty `json:"difficulty"`
+	// Нормализованные оценки, см. complexity.Parse
+	TimeComplexity  string    `json:"time_complexity"`
+	SpaceComplexity string    `json:"space_complexity"`
+	AnalyzedAt      time.Time `json:"analyzed_at"`
+}
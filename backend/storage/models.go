@@ -0,0 +1,293 @@
+package storage
+
+import "time"
+
+// Таблица Users: id, username, password_hash, email, role, locale.
+// Locale — язык писем пользователю.
+type User struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	Password string `json:"password,omitempty"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+	Locale   string `json:"locale,omitempty"`
+}
+
+// UserRecord — пользователь вместе со служебными полями, которые не отдаются клиенту
+type UserRecord struct {
+	User
+	PasswordHash      string     `json:"-"`
+	Confirmed         bool       `json:"confirmed"`
+	PasswordChangedAt *time.Time `json:"-"`
+}
+
+// Таблица Algorithms: id, title, description, code, user_id, category_id, rating, approved.
+// Topic — копия имени категории для клиентов, которые о категориях не знают.
+// Описание, сложность, оценки сложности и предварительные требования — метаданные алгоритма,
+// в истории ревизий они не хранятся.
+type Algorithm struct {
+	ID                  int        `json:"id"`
+	Title               string     `json:"title"`
+	Code                string     `json:"code"`
+	UserID              int        `json:"user_id"`
+	Topic               string     `json:"topic"`
+	CategoryID          *int       `json:"category_id"`
+	ProgrammingLanguage string     `json:"programming_language"`
+	CreatedAt           time.Time  `json:"created_at"`
+	DeletedAt           *time.Time `json:"deleted_at,omitempty"`
+	Approved            bool       `json:"approved"`
+	ModerationStatus    string     `json:"moderation_status"`
+	ModerationComment   string     `json:"moderation_comment,omitempty"`
+	// Описание в Markdown; HTML заполняется только в ответах с одним алгоритмом
+	Description     string     `json:"description"`
+	DescriptionHTML string     `json:"description_html,omitempty"`
+	Difficulty      Difficulty `json:"difficulty,omitempty"`
+	// Нормализованные оценки вида O(n log n), см. complexity.Parse
+	TimeComplexity  string `json:"time_complexity,omitempty"`
+	SpaceComplexity string `json:"space_complexity,omitempty"`
+	// Алгоритмы, которые стоит изучить до этого, по возрастанию id
+	Prerequisites []int `json:"prerequisites"`
+	// Предложение анализатора кода; заполняется только в ответах с одним алгоритмом
+	Analysis *Analysis `json:"analysis,omitempty"`
+	// Итог прогона тестов последней ревизии: VerificationPassed, VerificationFailed или пусто,
+	// если её ещё не проверяли или тестов нет
+	VerificationStatus string `json:"verification_status,omitempty"`
+	// Rating — байесовская оценка для сортировки most_popular, см. RatingScore
+	Rating        float64 `json:"rating"`
+	RatingAverage float64 `json:"rating_average"`
+	RatingCount   int     `json:"rating_count"`
+	// Основные теги в алфавитном порядке
+	Tags []string `json:"tags"`
+	// Заполняются только при поиске по AlgorithmFilter.Query
+	Highlight *Highlight `json:"highlight,omitempty"`
+	Rank      float64    `json:"-"`
+}
+
+// RatingSummary — оценки алгоритма после изменения: среднее, число голосов и оценка для сортировки
+type RatingSummary struct {
+	AlgorithmID int     `json:"algorithm_id"`
+	Rating      float64 `json:"rating"`
+	Average     float64 `json:"rating_average"`
+	Count       int     `json:"rating_count"`
+}
+
+// Допустимые оценки алгоритма
+const (
+	MinRatingScore = 1
+	MaxRatingScore = 5
+)
+
+// Байесовское среднее считает, что у каждого алгоритма уже есть ratingPriorWeight голосов
+// со средней оценкой ratingPriorMean. Поэтому одна пятёрка не поднимает алгоритм выше
+// десятков четвёрок, а с ростом числа голосов оценка приближается к настоящему среднему.
+const (
+	ratingPriorMean   = 3.0
+	ratingPriorWeight = 5
+)
+
+// RatingScore возвращает оценку для сортировки по сумме и числу голосов.
+// Алгоритмы без оценок получают 0 и идут после всех оценённых.
+func RatingScore(sum, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return (ratingPriorMean*ratingPriorWeight + float64(sum)) / float64(ratingPriorWeight+count)
+}
+
+// Таблица algorithm_revisions: неизменяемые снимки алгоритма после каждого изменения
+type Revision struct {
+	AlgorithmID         int       `json:"algorithm_id"`
+	Revision            int       `json:"revision"`
+	Title               string    `json:"title"`
+	Code                string    `json:"code,omitempty"`
+	Topic               string    `json:"topic"`
+	CategoryID          *int      `json:"category_id"`
+	ProgrammingLanguage string    `json:"programming_language"`
+	AuthorID            int       `json:"author_id"`
+	AuthorUsername      string    `json:"author_username"`
+	Message             string    `json:"message"`
+	CreatedAt           time.Time `json:"created_at"`
+	// Итог прогона тестов на коде этой ревизии, если её проверяли
+	Verification string `json:"verification,omitempty"`
+}
+
+// TagCount — тег и число опубликованных алгоритмов с ним
+type TagCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+	// Weight — размер в облаке тегов от 1 до 5; заполняется только для облака
+	Weight int `json:"weight,omitempty"`
+}
+
+// Таблица categories: дерево категорий алгоритмов
+type Category struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	ParentID *int   `json:"parent_id"`
+	// Slug — нормализованное имя, уникальное среди категорий с тем же родителем, см. CategorySlug
+	Slug     string     `json:"slug"`
+	Children []Category `json:"children,omitempty"`
+}
+
+// Таблица algorithm_comments: комментарии к алгоритму. Ответы образуют дерево, а корневой
+// комментарий может быть привязан к диапазону строк кода конкретной ревизии.
+type Comment struct {
+	ID             int    `json:"id"`
+	AlgorithmID    int    `json:"algorithm_id"`
+	ParentID       *int   `json:"parent_id"`
+	UserID         int    `json:"user_id"`
+	AuthorUsername string `json:"author_username"`
+	// Исходный Markdown; HTML строится при отдаче клиенту
+	Body     string `json:"body"`
+	BodyHTML string `json:"body_html"`
+	// Привязка к строкам LineStart..LineEnd (с единицы) ревизии Revision; у обычных комментариев nil
+	Revision  *int       `json:"revision,omitempty"`
+	LineStart *int       `json:"line_start,omitempty"`
+	LineEnd   *int       `json:"line_end,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+	// Удалённые комментарии остаются в дереве, чтобы не терялись ответы на них, но без текста
+	Deleted   bool      `json:"deleted"`
+	DeletedBy *int      `json:"-"`
+	Replies   []Comment `json:"replies,omitempty"`
+}
+
+// CommentFilter описывает страницу веток комментариев для ListComments
+type CommentFilter struct {
+	AlgorithmID int
+	// Только ветки, привязанные к строкам кода, и, если задано, к этой ревизии
+	InlineOnly bool
+	Revision   int
+	// Ветки с корнем после AfterID, не больше Limit (0 — без ограничения)
+	AfterID int
+	Limit   int
+}
+
+// Таблица sessions: одна строка на вход пользователя с устройства
+type Session struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"-"`
+	UserAgent  string     `json:"device"`
+	IP         string     `json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"-"`
+	Current    bool       `json:"current"`
+}
+
+// Счётчики неудачных входов ведутся по имени пользователя и по IP-адресу (колонка scope)
+const (
+	LoginScopeAccount = "account"
+	LoginScopeIP      = "ip"
+)
+
+// Таблица login_throttles: неудачные попытки входа подряд. Subject — имя пользователя, в том числе
+// несуществующее, или IP-адрес.
+type LoginThrottle struct {
+	Scope         string     `json:"scope"`
+	Subject       string     `json:"subject"`
+	Failures      int        `json:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+}
+
+// NextAttemptAt возвращает, когда можно сделать следующую попытку входа: не раньше конца блокировки
+// и паузы delay(Failures) после последней неудачи. Неудачи раньше resetBefore уже забыты.
+func (t LoginThrottle) NextAttemptAt(resetBefore time.Time, delay func(failures int) time.Duration) time.Time {
+	var next time.Time
+	if t.LockedUntil != nil {
+		next = *t.LockedUntil
+	}
+	if pause := delay(t.Failures); pause > 0 && !t.LastFailureAt.Before(resetBefore) {
+		if after := t.LastFailureAt.Add(pause); after.After(next) {
+			next = after
+		}
+	}
+	return next
+}
+
+// Статусы модерации алгоритма (колонка moderation_status). Публично виден только approved.
+const (
+	ModerationPending          = "pending"
+	ModerationApproved         = "approved"
+	ModerationRejected         = "rejected"
+	ModerationChangesRequested = "changes_requested"
+)
+
+// Роль пользователя, у которого она не задана в базе
+const DefaultRole = "user"
+
+// DefaultLocale — язык писем пользователей, которые его не выбрали
+const DefaultLocale = "en"
+
+// Порядок сортировки в AlgorithmFilter; по умолчанию — по id
+const (
+	SortNewest      = "newest"
+	SortOldest      = "oldest"
+	SortMostPopular = "most_popular"
+	// По релевантности запросу AlgorithmFilter.Query; без запроса — по умолчанию
+	SortRelevance = "relevance"
+)
+
+// AlgorithmFilter описывает выборку для ListAlgorithms. Пустые поля не ограничивают выборку.
+type AlgorithmFilter struct {
+	// Если задан, неодобренные алгоритмы попадают в выборку, только когда их автор — ViewerID.
+	// Нулевое значение не ограничивает выборку по статусу модерации.
+	ViewerID int
+	ID       int
+	UserID   int
+	// Поиск подстроки без учёта регистра
+	Title               string
+	Topic               string
+	ProgrammingLanguage string
+	ModerationStatus    string
+	// Категория вместе со всеми подкатегориями
+	CategoryID int
+	// Основные имена тегов; TagMode — TagsAll (по умолчанию) или TagsAny
+	Tags    []string
+	TagMode string
+	// Сложность от DifficultyMin до DifficultyMax включительно; 0 — без границы
+	DifficultyMin int
+	DifficultyMax int
+	// Нормализованная оценка целиком или наибольший ранг роста (nil — без границы)
+	TimeComplexity  string
+	SpaceComplexity string
+	MaxTimeRank     *float64
+	MaxSpaceRank    *float64
+	// Алгоритмы, у которых PrerequisiteID в предварительных требованиях
+	PrerequisiteID int
+	// true — только алгоритмы, последняя ревизия которых прошла тесты, false — все остальные
+	Verified *bool
+	// Полнотекстовый запрос по названию, описанию и коду
+	Query string
+	Sort  string
+	// Страница: не больше Limit алгоритмов (0 — без ограничения), идущих после курсора After
+	Limit int
+	After *Cursor
+	// Не загружать код и описание: списки показывают только заголовки
+	OmitCode bool
+}
+
+// NestReplies раскладывает ответы по веткам корневых комментариев. replies — все ответы
+// этих веток в порядке создания, на любой глубине.
+func NestReplies(roots, replies []Comment) []Comment {
+	children := map[int][]Comment{}
+	for _, reply := range replies {
+		children[*reply.ParentID] = append(children[*reply.ParentID], reply)
+	}
+
+	var attach func(comment Comment) Comment
+	attach = func(comment Comment) Comment {
+		for _, child := range children[comment.ID] {
+			comment.Replies = append(comment.Replies, attach(child))
+		}
+		return comment
+	}
+
+	threads := make([]Comment, len(roots))
+	for i, root := range roots {
+		threads[i] = attach(root)
+	}
+	return threads
+}
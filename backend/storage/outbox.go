@@ -0,0 +1,38 @@
+package storage
+
+import "time"
+
+// Состояние письма в очереди: ждёт отправки, отправлено или брошено после всех попыток
+const (
+	EmailPending = "pending"
+	EmailSent    = "sent"
+	EmailDead    = "dead"
+)
+
+// Назначение письма; совпадает с именем его шаблона в пакете mailer
+const (
+	EmailVerification    = "verification"
+	EmailPasswordReset   = "password_reset"
+	EmailModeration      = "moderation"
+	EmailPasswordChanged = "password_changed"
+	EmailAccountLocked   = "account_locked"
+)
+
+// Таблица email_outbox: готовое к отправке письмо. Текст содержит одноразовые токены, поэтому
+// в ответы API не попадает.
+type OutboxEmail struct {
+	ID        int    `json:"id"`
+	Kind      string `json:"kind"`
+	Recipient string `json:"recipient"`
+	Subject   string `json:"subject"`
+	Body      string `json:"-"`
+	// HTMLBody — HTML-версия; пустая, если письмо только текстовое
+	HTMLBody string `json:"-"`
+	Status   string `json:"status"`
+	// Attempts — сколько раз письмо брали в отправку, LastError — ошибка последней неудачной
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+}
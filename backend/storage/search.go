@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Highlight — найденные фрагменты с подсветкой совпадений. Текст уже экранирован для HTML,
+// совпадения обёрнуты в <mark>, поэтому фронтенд может вставлять его как есть.
+type Highlight struct {
+	Title   string `json:"title"`
+	Snippet string `json:"snippet,omitempty"`
+}
+
+// Сколько символов текста показывать вокруг первого совпадения
+const (
+	snippetBefore = 60
+	snippetAfter  = 140
+)
+
+// SearchTerms разбивает запрос на слова для хранилищ без полнотекстового поиска
+func SearchTerms(query string) []string {
+	return strings.Fields(strings.ToLower(query))
+}
+
+// MarkHighlights экранирует text для HTML и заменяет маркеры start и stop на <mark> и </mark>
+func MarkHighlights(text, start, stop string) string {
+	text = html.EscapeString(text)
+	text = strings.ReplaceAll(text, html.EscapeString(start), "<mark>")
+	return strings.ReplaceAll(text, html.EscapeString(stop), "</mark>")
+}
+
+// MatchTerms проверяет, что каждое слово запроса встречается в названии или тексте, и строит подсветку.
+// Ранг — число вхождений, совпадения в названии весят втрое больше, как вес A против C в PostgreSQL.
+func MatchTerms(title, body string, terms []string) (Highlight, float64, bool) {
+	titleRunes, bodyRunes := []rune(title), []rune(body)
+	titleLower, bodyLower := lowerRunes(titleRunes), lowerRunes(bodyRunes)
+
+	var rank float64
+	for _, term := range terms {
+		needle := []rune(term)
+		inTitle, inBody := countRunes(titleLower, needle), countRunes(bodyLower, needle)
+		if inTitle+inBody == 0 {
+			return Highlight{}, 0, false
+		}
+		rank += float64(3*inTitle + inBody)
+	}
+
+	highlight := Highlight{Title: markRunes(titleRunes, titleLower, terms)}
+	if first := firstMatch(bodyLower, terms); first >= 0 {
+		from, to := first-snippetBefore, first+snippetAfter
+		if from < 0 {
+			from = 0
+		}
+		if to > len(bodyRunes) {
+			to = len(bodyRunes)
+		}
+		highlight.Snippet = markRunes(bodyRunes[from:to], bodyLower[from:to], terms)
+		if from > 0 {
+			highlight.Snippet = "…" + highlight.Snippet
+		}
+		if to < len(bodyRunes) {
+			highlight.Snippet += "…"
+		}
+	}
+	return highlight, rank, true
+}
+
+// lowerRunes приводит руны к нижнему регистру по одной, чтобы индексы совпадали с исходным текстом
+func lowerRunes(text []rune) []rune {
+	lower := make([]rune, len(text))
+	for i, r := range text {
+		lower[i] = unicode.ToLower(r)
+	}
+	return lower
+}
+
+func indexRunes(text, needle []rune, from int) int {
+	for i := from; i+len(needle) <= len(text); i++ {
+		if string(text[i:i+len(needle)]) == string(needle) {
+			return i
+		}
+	}
+	return -1
+}
+
+func countRunes(text, needle []rune) int {
+	count := 0
+	for i := indexRunes(text, needle, 0); i >= 0; i = indexRunes(text, needle, i+len(needle)) {
+		count++
+	}
+	return count
+}
+
+func firstMatch(text []rune, terms []string) int {
+	first := -1
+	for _, term := range terms {
+		if i := indexRunes(text, []rune(term), 0); i >= 0 && (first < 0 || i < first) {
+			first = i
+		}
+	}
+	return first
+}
+
+// markRunes оборачивает в <mark> все вхождения слов запроса; lower — тот же текст в нижнем регистре
+func markRunes(text, lower []rune, terms []string) string {
+	marked := make([]bool, len(text))
+	for _, term := range terms {
+		needle := []rune(term)
+		for i := indexRunes(lower, needle, 0); i >= 0; i = indexRunes(lower, needle, i+len(needle)) {
+			for j := i; j < i+len(needle); j++ {
+				marked[j] = true
+			}
+		}
+	}
+
+	var b strings.Builder
+	for i, r := range text {
+		if marked[i] && (i == 0 || !marked[i-1]) {
+			b.WriteString("<mark>")
+		}
+		b.WriteString(html.EscapeString(string(r)))
+		if marked[i] && (i == len(text)-1 || !marked[i+1]) {
+			b.WriteString("</mark>")
+		}
+	}
+	return b.String()
+}
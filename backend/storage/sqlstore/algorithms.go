@@ -0,0 +1,510 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+const algorithmColumns = "id, title, code, COALESCE(description, ''), user_id, topic, category_id, programming_language, created_at, deleted_at, approved, moderation_status, " +
+	"COALESCE(moderation_comment, ''), COALESCE(difficulty, 0), COALESCE(time_complexity, ''), COALESCE(space_complexity, ''), COALESCE(rating, 0), rating_average, rating_count, " +
+	"COALESCE(verification_status, '')"
+
+// scanAlgorithm читает столбцы algorithmColumns; extra получает столбцы, выбранные после них
+func scanAlgorithm(row interface{ Scan(...interface{}) error }, extra ...interface{}) (storage.Algorithm, error) {
+	var algorithm storage.Algorithm
+	var deletedAt sql.NullTime
+	var categoryID sql.NullInt64
+	dest := []interface{}{&algorithm.ID, &algorithm.Title, &algorithm.Code, &algorithm.Description, &algorithm.UserID, &algorithm.Topic, &categoryID,
+		&algorithm.ProgrammingLanguage, &algorithm.CreatedAt, &deletedAt, &algorithm.Approved, &algorithm.ModerationStatus, &algorithm.ModerationComment,
+		&algorithm.Difficulty, &algorithm.TimeComplexity, &algorithm.SpaceComplexity, &algorithm.Rating, &algorithm.RatingAverage, &algorithm.RatingCount,
+		&algorithm.VerificationStatus}
+	err := row.Scan(append(dest, extra...)...)
+	algorithm.DeletedAt = timePtr(deletedAt)
+	algorithm.CategoryID = intPtr(categoryID)
+	return algorithm, err
+}
+
+func (s *Store) scanAlgorithms(rows *sql.Rows, err error) ([]storage.Algorithm, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var algorithms []storage.Algorithm = []storage.Algorithm{}
+	for rows.Next() {
+		algorithm, err := scanAlgorithm(rows)
+		if err != nil {
+			return nil, err
+		}
+		algorithms = append(algorithms, algorithm)
+	}
+	return algorithms, rows.Err()
+}
+
+// Маркеры, которыми ts_headline отмечает совпадения. Управляющие символы не встречаются в тексте
+// алгоритмов, поэтому после экранирования их можно безопасно заменить на <mark>.
+const (
+	headlineStart = "\x02"
+	headlineStop  = "\x03"
+)
+
+// searchQuery — запрос сразу на английском и русском: слова стеммируются обоими словарями,
+// а документ проиндексирован обоими, так что язык запроса угадывать не нужно
+const searchQuery = "(websearch_to_tsquery('english', $%[1]d) || websearch_to_tsquery('russian', $%[1]d))"
+
+// headlineConfig выбирает словарь для подсветки: ts_headline разбирает текст одним словарём
+func headlineConfig(query string) string {
+	for _, r := range query {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return "russian"
+		}
+	}
+	return "english"
+}
+
+// searchPostgres дописывает к выборке полнотекстовый поиск с опечатками по названию, ранг и подсветку.
+// Возвращает столбцы для SELECT и выражение ранга для ORDER BY.
+func searchPostgres(query string, where func(string, interface{}), arg func(interface{}) int) (columns, rank string) {
+	q := arg(query)
+	tsquery := fmt.Sprintf(searchQuery, q)
+	where(fmt.Sprintf("(search_vector @@ %s OR $%%[1]d <%%%% title)", tsquery), query)
+
+	rank = fmt.Sprintf("(ts_rank_cd(search_vector, %s) + word_similarity($%d, title))", tsquery, q)
+	config := arg(headlineConfig(query))
+	options := arg("StartSel=" + headlineStart + ", StopSel=" + headlineStop + ", MaxFragments=2, MaxWords=25, MinWords=8, FragmentDelimiter=\" … \"")
+	columns = fmt.Sprintf(", %[1]s, ts_headline($%[2]d::regconfig, title, %[3]s, $%[4]d), "+
+		"ts_headline($%[2]d::regconfig, COALESCE(NULLIF(description, ''), code, ''), %[3]s, $%[4]d)", rank, config, tsquery, options)
+	return columns, rank
+}
+
+// insertRevision записывает новую ревизию с номером на единицу больше последнего
+func (s *Store) insertRevision(ctx context.Context, tx *sql.Tx, algorithm storage.Algorithm, authorID int, message string) (int, error) {
+	var revision int
+	err := s.queryRow(ctx, tx, "INSERT INTO algorithm_revisions(algorithm_id, revision, title, code, topic, category_id, programming_language, user_id, message, created_at) "+
+		"SELECT $1, COALESCE(MAX(revision), 0) + 1, $2, $3, $4, $5, $6, $7, $8, $9 FROM algorithm_revisions WHERE algorithm_id = $1 RETURNING revision",
+		algorithm.ID, algorithm.Title, algorithm.Code, algorithm.Topic, algorithm.CategoryID, algorithm.ProgrammingLanguage, authorID, message, now()).Scan(&revision)
+	return revision, err
+}
+
+func (s *Store) CreateAlgorithm(ctx context.Context, algorithm *storage.Algorithm, message string) error {
+	algorithm.CreatedAt = now()
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		args := append([]interface{}{algorithm.Title, algorithm.Code, algorithm.Description, algorithm.UserID, algorithm.Topic, algorithm.CategoryID,
+			algorithm.ProgrammingLanguage, algorithm.Approved, algorithm.ModerationStatus, algorithm.CreatedAt}, metadataArgs(*algorithm)...)
+		err := s.queryRow(ctx, tx, "INSERT INTO algorithms(title, code, description, user_id, topic, category_id, programming_language, approved, moderation_status, created_at, "+
+			"difficulty, time_complexity, time_complexity_rank, space_complexity, space_complexity_rank) "+
+			"VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id", args...).Scan(&algorithm.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.saveTags(ctx, tx, algorithm); err != nil {
+			return err
+		}
+		if err := s.savePrerequisites(ctx, tx, algorithm); err != nil {
+			return err
+		}
+		if err := s.saveAnalysis(ctx, tx, *algorithm); err != nil {
+			return err
+		}
+
+		_, err = s.insertRevision(ctx, tx, *algorithm, algorithm.UserID, message)
+		return err
+	})
+}
+
+func (s *Store) GetAlgorithm(ctx context.Context, id int, trashed bool) (storage.Algorithm, error) {
+	query := "SELECT " + algorithmColumns + " FROM algorithms WHERE id = $1 AND deleted_at IS NULL"
+	if trashed {
+		query = "SELECT " + algorithmColumns + " FROM algorithms WHERE id = $1 AND deleted_at IS NOT NULL"
+	}
+	algorithm, err := scanAlgorithm(s.queryRow(ctx, s.db, query, id))
+	if err != nil {
+		return algorithm, mapErr(err)
+	}
+	if err := s.loadOneLinks(ctx, s.db, &algorithm); err != nil {
+		return algorithm, err
+	}
+	return algorithm, s.loadAnalysis(ctx, s.db, &algorithm)
+}
+
+// algorithmQuery — выборка ListAlgorithms и CountAlgorithms: условия WHERE с аргументами,
+// а при поиске ещё дополнительные столбцы и выражение ранга
+type algorithmQuery struct {
+	where   string
+	args    []interface{}
+	columns string
+	rank    string
+	// Слова запроса для SQLite, где ранг и подсветка считаются в Go
+	terms []string
+}
+
+func (q *algorithmQuery) arg(value interface{}) int {
+	q.args = append(q.args, value)
+	return len(q.args)
+}
+
+func (q *algorithmQuery) and(condition string, value interface{}) {
+	q.where += fmt.Sprintf(" AND "+condition, q.arg(value))
+}
+
+func (s *Store) buildAlgorithmQuery(filter storage.AlgorithmFilter) *algorithmQuery {
+	q := &algorithmQuery{where: " FROM algorithms WHERE deleted_at IS NULL"}
+
+	if filter.ViewerID != 0 {
+		q.and("(approved = true OR user_id = $%d)", filter.ViewerID)
+	}
+	if filter.ID != 0 {
+		q.and("id = $%d", filter.ID)
+	}
+	if filter.UserID != 0 {
+		q.and("user_id = $%d", filter.UserID)
+	}
+	if filter.Title != "" {
+		q.and("title ILIKE $%d", "%"+filter.Title+"%")
+	}
+	if filter.Topic != "" {
+		q.and("topic ILIKE $%d", "%"+filter.Topic+"%")
+	}
+	if filter.CategoryID != 0 {
+		q.and("category_id IN (WITH RECURSIVE subtree(id) AS (SELECT id FROM categories WHERE id = $%d "+
+			"UNION ALL SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id) SELECT id FROM subtree)", filter.CategoryID)
+	}
+	if len(filter.Tags) > 0 {
+		first := len(q.args) + 1
+		q.args = append(q.args, stringArgs(filter.Tags)...)
+		tagged := "SELECT link.algorithm_id FROM algorithm_tags link JOIN tags t ON t.id = link.tag_id WHERE t.name IN (" + placeholders(first, len(filter.Tags)) + ")"
+		// Для режима «все» алгоритм должен быть связан с каждым из тегов; имена в фильтре не повторяются
+		if filter.TagMode != storage.TagsAny {
+			tagged += fmt.Sprintf(" GROUP BY link.algorithm_id HAVING COUNT(*) = %d", len(filter.Tags))
+		}
+		q.where += " AND id IN (" + tagged + ")"
+	}
+	if filter.ProgrammingLanguage != "" {
+		q.and("programming_language ILIKE $%d", "%"+filter.ProgrammingLanguage+"%")
+	}
+	if filter.ModerationStatus != "" {
+		q.and("moderation_status = $%d", filter.ModerationStatus)
+	}
+	if filter.DifficultyMin != 0 {
+		q.and("difficulty >= $%d", filter.DifficultyMin)
+	}
+	if filter.DifficultyMax != 0 {
+		q.and("difficulty <= $%d", filter.DifficultyMax)
+	}
+	if filter.TimeComplexity != "" {
+		q.and("time_complexity = $%d", filter.TimeComplexity)
+	}
+	if filter.SpaceComplexity != "" {
+		q.and("space_complexity = $%d", filter.SpaceComplexity)
+	}
+	if filter.MaxTimeRank != nil {
+		q.and("time_complexity_rank <= $%d", *filter.MaxTimeRank)
+	}
+	if filter.MaxSpaceRank != nil {
+		q.and("space_complexity_rank <= $%d", *filter.MaxSpaceRank)
+	}
+	if filter.PrerequisiteID != 0 {
+		q.and("id IN (SELECT algorithm_id FROM algorithm_prerequisites WHERE prerequisite_id = $%d)", filter.PrerequisiteID)
+	}
+	if filter.Verified != nil {
+		if *filter.Verified {
+			q.and("verification_status = $%d", storage.VerificationPassed)
+		} else {
+			q.and("(verification_status IS NULL OR verification_status <> $%d)", storage.VerificationPassed)
+		}
+	}
+
+	// В SQLite нет полнотекстового индекса: каждое слово ищется подстрокой, ранг и подсветка считаются в Go
+	if filter.Query != "" {
+		if s.dialect == Postgres {
+			q.columns, q.rank = searchPostgres(filter.Query, q.and, q.arg)
+		} else {
+			q.terms = storage.SearchTerms(filter.Query)
+			for _, term := range q.terms {
+				q.and("(title ILIKE $%[1]d OR COALESCE(description, '') ILIKE $%[1]d OR COALESCE(code, '') ILIKE $%[1]d)", "%"+term+"%")
+			}
+			q.columns = ", COALESCE(NULLIF(description, ''), code, '')"
+		}
+	}
+	return q
+}
+
+// rankedInGo сообщает, что порядок по релевантности, а значит и страницу, считает Go, а не SQL
+func (q *algorithmQuery) rankedInGo(filter storage.AlgorithmFilter) bool {
+	return filter.Sort == storage.SortRelevance && q.terms != nil
+}
+
+func (s *Store) ListAlgorithms(ctx context.Context, filter storage.AlgorithmFilter) ([]storage.Algorithm, error) {
+	algorithms, err := s.listAlgorithms(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return algorithms, s.loadLinks(ctx, s.db, algorithms)
+}
+
+func (s *Store) listAlgorithms(ctx context.Context, filter storage.AlgorithmFilter) ([]storage.Algorithm, error) {
+	q := s.buildAlgorithmQuery(filter)
+	rankedInGo := q.rankedInGo(filter)
+
+	// Ключ сортировки всегда заканчивается на id, так что курсор однозначно задаёт начало страницы
+	if after := filter.After; after != nil && !rankedInGo {
+		switch {
+		case filter.Sort == storage.SortRelevance && q.rank != "":
+			q.where += fmt.Sprintf(" AND (%[1]s < $%[2]d OR (%[1]s = $%[2]d AND id > $%[3]d))", q.rank, q.arg(after.Rank), q.arg(after.ID))
+		case filter.Sort == storage.SortNewest:
+			q.where += fmt.Sprintf(" AND (created_at < $%[1]d OR (created_at = $%[1]d AND id < $%[2]d))", q.arg(after.CreatedAt), q.arg(after.ID))
+		case filter.Sort == storage.SortOldest:
+			q.where += fmt.Sprintf(" AND (created_at > $%[1]d OR (created_at = $%[1]d AND id > $%[2]d))", q.arg(after.CreatedAt), q.arg(after.ID))
+		case filter.Sort == storage.SortMostPopular:
+			q.where += fmt.Sprintf(" AND (COALESCE(rating, 0) < $%[1]d OR (COALESCE(rating, 0) = $%[1]d AND id > $%[2]d))", q.arg(after.Rating), q.arg(after.ID))
+		case metadataOrder[filter.Sort] != "":
+			op := ">"
+			if storage.MetadataSorts[filter.Sort] {
+				op = "<"
+			}
+			q.where += fmt.Sprintf(" AND (%[1]s %[2]s $%[3]d OR (%[1]s = $%[3]d AND id > $%[4]d))", metadataOrder[filter.Sort], op, q.arg(after.Value), q.arg(after.ID))
+		default:
+			q.and("id > $%d", after.ID)
+		}
+	}
+
+	columns := algorithmColumns
+	if filter.OmitCode {
+		columns = strings.Replace(columns, "title, code, COALESCE(description, ''),", "title, '', '',", 1)
+	}
+	query := "SELECT " + columns + q.columns + q.where
+
+	switch {
+	case filter.Sort == storage.SortRelevance && q.rank != "":
+		query += " ORDER BY " + q.rank + " DESC, id"
+	case filter.Sort == storage.SortNewest:
+		query += " ORDER BY created_at DESC, id DESC"
+	case filter.Sort == storage.SortOldest:
+		query += " ORDER BY created_at, id"
+	case filter.Sort == storage.SortMostPopular:
+		query += " ORDER BY COALESCE(rating, 0) DESC, id"
+	case metadataOrder[filter.Sort] != "":
+		query += " ORDER BY " + metadataOrder[filter.Sort]
+		if storage.MetadataSorts[filter.Sort] {
+			query += " DESC"
+		}
+		query += ", id"
+	default:
+		query += " ORDER BY id"
+	}
+	if filter.Limit > 0 && !rankedInGo {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	if filter.Query == "" {
+		return s.scanAlgorithms(s.query(ctx, s.db, query, q.args...))
+	}
+	algorithms, err := s.scanSearchResults(ctx, query, q.args, q.terms)
+	if err != nil || !rankedInGo {
+		return algorithms, err
+	}
+	sort.SliceStable(algorithms, func(i, j int) bool { return algorithms[i].Rank > algorithms[j].Rank })
+	return storage.Paginate(algorithms, filter), nil
+}
+
+func (s *Store) CountAlgorithms(ctx context.Context, filter storage.AlgorithmFilter) (int, error) {
+	q := s.buildAlgorithmQuery(filter)
+	var count int
+	err := s.queryRow(ctx, s.db, "SELECT COUNT(*)"+q.where, q.args...).Scan(&count)
+	return count, err
+}
+
+// scanSearchResults читает выборку с подсветкой. terms непусты для SQLite, где подсветка строится в Go.
+func (s *Store) scanSearchResults(ctx context.Context, query string, args []interface{}, terms []string) ([]storage.Algorithm, error) {
+	rows, err := s.query(ctx, s.db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var algorithms []storage.Algorithm = []storage.Algorithm{}
+	for rows.Next() {
+		var highlight storage.Highlight
+		var algorithm storage.Algorithm
+		var rank float64
+		if terms == nil {
+			algorithm, err = scanAlgorithm(rows, &rank, &highlight.Title, &highlight.Snippet)
+			algorithm.Rank = rank
+			highlight.Title = storage.MarkHighlights(highlight.Title, headlineStart, headlineStop)
+			highlight.Snippet = storage.MarkHighlights(highlight.Snippet, headlineStart, headlineStop)
+		} else {
+			var body string
+			algorithm, err = scanAlgorithm(rows, &body)
+			highlight, algorithm.Rank, _ = storage.MatchTerms(algorithm.Title, body, terms)
+		}
+		if err != nil {
+			return nil, err
+		}
+		algorithm.Highlight = &highlight
+		algorithms = append(algorithms, algorithm)
+	}
+	return algorithms, rows.Err()
+}
+
+func (s *Store) ReviseAlgorithm(ctx context.Context, id, authorID int, message string, update func(*storage.Algorithm) error) (storage.Algorithm, int, error) {
+	var algorithm storage.Algorithm
+	var revision int
+
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		current, err := scanAlgorithm(s.queryRow(ctx, tx, "SELECT "+algorithmColumns+" FROM algorithms WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", id))
+		if err != nil {
+			return mapErr(err)
+		}
+		if err := s.loadOneLinks(ctx, tx, &current); err != nil {
+			return err
+		}
+
+		// Алгоритмы, созданные до появления истории ревизий, сначала получают ревизию с текущим
+		// состоянием, чтобы первая правка не затёрла его безвозвратно
+		var exists bool
+		err = s.queryRow(ctx, tx, "SELECT EXISTS(SELECT 1 FROM algorithm_revisions WHERE algorithm_id = $1)", id).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			if _, err := s.insertRevision(ctx, tx, current, current.UserID, "Initial version"); err != nil {
+				return err
+			}
+		}
+
+		algorithm = current
+		if err := update(&algorithm); err != nil {
+			return err
+		}
+		algorithm.ID, algorithm.UserID, algorithm.CreatedAt = current.ID, current.UserID, current.CreatedAt
+		algorithm.Approved = false
+		algorithm.ModerationStatus = storage.ModerationPending
+		algorithm.ModerationComment = ""
+		algorithm.VerificationStatus = ""
+
+		args := append([]interface{}{algorithm.Title, algorithm.Code, algorithm.Description, algorithm.Topic, algorithm.CategoryID, algorithm.ProgrammingLanguage,
+			algorithm.ModerationStatus, id}, metadataArgs(algorithm)...)
+		_, err = s.exec(ctx, tx, "UPDATE algorithms SET title = $1, code = $2, description = $3, topic = $4, category_id = $5, programming_language = $6, "+
+			"approved = false, moderation_status = $7, moderation_comment = NULL, verification_status = NULL, difficulty = $9, time_complexity = $10, time_complexity_rank = $11, "+
+			"space_complexity = $12, space_complexity_rank = $13 WHERE id = $8", args...)
+		if err != nil {
+			return err
+		}
+
+		if err := s.saveTags(ctx, tx, &algorithm); err != nil {
+			return err
+		}
+		if err := s.savePrerequisites(ctx, tx, &algorithm); err != nil {
+			return err
+		}
+		if err := s.saveAnalysis(ctx, tx, algorithm); err != nil {
+			return err
+		}
+
+		revision, err = s.insertRevision(ctx, tx, algorithm, authorID, message)
+		return err
+	})
+	return algorithm, revision, err
+}
+
+func (s *Store) ModerateAlgorithm(ctx context.Context, id, moderatorID int, status, comment string) (storage.Algorithm, storage.User, error) {
+	var algorithm storage.Algorithm
+	var author storage.User
+
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		algorithm, err = scanAlgorithm(s.queryRow(ctx, tx, "UPDATE algorithms SET moderation_status = $1, approved = $2, moderation_comment = NULLIF($3, ''), "+
+			"moderated_by = $4, moderated_at = $5 WHERE id = $6 AND deleted_at IS NULL RETURNING "+algorithmColumns,
+			status, status == storage.ModerationApproved, comment, moderatorID, now(), id))
+		if err != nil {
+			return mapErr(err)
+		}
+		if err := s.loadOneLinks(ctx, tx, &algorithm); err != nil {
+			return err
+		}
+
+		err = s.queryRow(ctx, tx, "SELECT id, username, email, COALESCE(role, 'user'), locale FROM users WHERE id = $1", algorithm.UserID).
+			Scan(&author.ID, &author.Username, &author.Email, &author.Role, &author.Locale)
+		return mapErr(err)
+	})
+	return algorithm, author, err
+}
+
+func (s *Store) TrashAlgorithm(ctx context.Context, id int, at time.Time) error {
+	return s.execOne(ctx, s.db, "UPDATE algorithms SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL", at, id)
+}
+
+func (s *Store) RestoreAlgorithm(ctx context.Context, id int) (storage.Algorithm, error) {
+	algorithm, err := scanAlgorithm(s.queryRow(ctx, s.db, "UPDATE algorithms SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL RETURNING "+algorithmColumns, id))
+	if err != nil {
+		return algorithm, mapErr(err)
+	}
+	return algorithm, s.loadOneLinks(ctx, s.db, &algorithm)
+}
+
+func (s *Store) ListTrash(ctx context.Context, userID int) ([]storage.Algorithm, error) {
+	query := "SELECT " + algorithmColumns + " FROM algorithms WHERE deleted_at IS NOT NULL"
+	var args []interface{}
+	if userID != 0 {
+		query += " AND user_id = $1"
+		args = append(args, userID)
+	}
+	query += " ORDER BY deleted_at DESC"
+
+	algorithms, err := s.scanAlgorithms(s.query(ctx, s.db, query, args...))
+	if err != nil {
+		return nil, err
+	}
+	return algorithms, s.loadLinks(ctx, s.db, algorithms)
+}
+
+func (s *Store) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.exec(ctx, s.db, "DELETE FROM algorithms WHERE deleted_at IS NOT NULL AND deleted_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (s *Store) ListRevisions(ctx context.Context, algorithmID int) ([]storage.Revision, error) {
+	rows, err := s.query(ctx, s.db, "SELECT r.algorithm_id, r.revision, r.title, r.topic, r.category_id, r.programming_language, r.user_id, COALESCE(u.username, ''), r.message, r.created_at, "+
+		"COALESCE(v.status, '') FROM algorithm_revisions r LEFT JOIN users u ON u.id = r.user_id "+
+		"LEFT JOIN algorithm_verifications v ON v.algorithm_id = r.algorithm_id AND v.revision = r.revision WHERE r.algorithm_id = $1 ORDER BY r.revision DESC", algorithmID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []storage.Revision = []storage.Revision{}
+	for rows.Next() {
+		var rev storage.Revision
+		var categoryID sql.NullInt64
+		err := rows.Scan(&rev.AlgorithmID, &rev.Revision, &rev.Title, &rev.Topic, &categoryID, &rev.ProgrammingLanguage, &rev.AuthorID, &rev.AuthorUsername, &rev.Message, &rev.CreatedAt, &rev.Verification)
+		if err != nil {
+			return nil, err
+		}
+		rev.CategoryID = intPtr(categoryID)
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+func (s *Store) GetRevision(ctx context.Context, algorithmID, revision int) (storage.Revision, error) {
+	var rev storage.Revision
+	var categoryID sql.NullInt64
+	err := s.queryRow(ctx, s.db, "SELECT r.algorithm_id, r.revision, r.title, r.code, r.topic, r.category_id, r.programming_language, r.user_id, COALESCE(u.username, ''), r.message, r.created_at, "+
+		"COALESCE(v.status, '') FROM algorithm_revisions r JOIN algorithms a ON a.id = r.algorithm_id LEFT JOIN users u ON u.id = r.user_id "+
+		"LEFT JOIN algorithm_verifications v ON v.algorithm_id = r.algorithm_id AND v.revision = r.revision "+
+		"WHERE r.algorithm_id = $1 AND r.revision = $2 AND a.deleted_at IS NULL", algorithmID, revision).
+		Scan(&rev.AlgorithmID, &rev.Revision, &rev.Title, &rev.Code, &rev.Topic, &categoryID, &rev.ProgrammingLanguage, &rev.AuthorID, &rev.AuthorUsername, &rev.Message, &rev.CreatedAt, &rev.Verification)
+	rev.CategoryID = intPtr(categoryID)
+	return rev, mapErr(err)
+}
@@ -0,0 +1,47 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// saveAnalysis заменяет оценку кода алгоритма; без algorithm.Analysis прежняя оценка просто удаляется,
+// потому что к новому коду она не относится
+func (s *Store) saveAnalysis(ctx context.Context, tx *sql.Tx, algorithm storage.Algorithm) error {
+	if _, err := s.exec(ctx, tx, "DELETE FROM algorithm_analysis WHERE algorithm_id = $1", algorithm.ID); err != nil {
+		return err
+	}
+	a := algorithm.Analysis
+	if a == nil {
+		return nil
+	}
+	_, err := s.exec(ctx, tx, "INSERT INTO algorithm_analysis(algorithm_id, analyzer, lines, loop_depth, recursive, cyclomatic, data_structures, "+
+		"difficulty, time_complexity, space_complexity, analyzed_at) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)",
+		algorithm.ID, a.Analyzer, a.Lines, a.LoopDepth, a.Recursive, a.Cyclomatic, strings.Join(a.DataStructures, ","),
+		int(a.Difficulty), a.TimeComplexity, a.SpaceComplexity, a.AnalyzedAt)
+	return err
+}
+
+// loadAnalysis заполняет Analysis алгоритма, если код уже оценён
+func (s *Store) loadAnalysis(ctx context.Context, db querier, algorithm *storage.Algorithm) error {
+	var a storage.Analysis
+	var structures string
+	err := s.queryRow(ctx, db, "SELECT analyzer, lines, loop_depth, recursive, cyclomatic, data_structures, difficulty, time_complexity, space_complexity, analyzed_at "+
+		"FROM algorithm_analysis WHERE algorithm_id = $1", algorithm.ID).
+		Scan(&a.Analyzer, &a.Lines, &a.LoopDepth, &a.Recursive, &a.Cyclomatic, &structures, &a.Difficulty, &a.TimeComplexity, &a.SpaceComplexity, &a.AnalyzedAt)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	a.DataStructures = []string{}
+	if structures != "" {
+		a.DataStructures = strings.Split(structures, ",")
+	}
+	algorithm.Analysis = &a
+	return nil
+}
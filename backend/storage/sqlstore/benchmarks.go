@@ -0,0 +1,223 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+const benchmarkColumns = "id, user_id, generator, sizes, repetitions, seed, status, error, created_at, started_at, finished_at"
+
+const benchmarkAlgorithmColumns = "benchmark_algorithms.algorithm_id, title, programming_language, revision, benchmark_algorithms.status, " +
+	"benchmark_algorithms.error, time_complexity, space_complexity, fitted_time_complexity, time_exponent, fitted_space_complexity, space_exponent"
+
+// scanBenchmarkAlgorithm читает столбцы benchmarkAlgorithmColumns; extra получает столбцы, выбранные после них
+func scanBenchmarkAlgorithm(row interface{ Scan(...interface{}) error }, extra ...interface{}) (storage.BenchmarkAlgorithm, error) {
+	var algorithm storage.BenchmarkAlgorithm
+	dest := []interface{}{&algorithm.AlgorithmID, &algorithm.Title, &algorithm.ProgrammingLanguage, &algorithm.Revision, &algorithm.Status,
+		&algorithm.Error, &algorithm.TimeComplexity, &algorithm.SpaceComplexity, &algorithm.FittedTimeComplexity, &algorithm.TimeExponent,
+		&algorithm.FittedSpaceComplexity, &algorithm.SpaceExponent}
+	err := row.Scan(append(dest, extra...)...)
+	algorithm.Measurements = []storage.BenchmarkMeasurement{}
+	return algorithm, err
+}
+
+func (s *Store) CreateBenchmark(ctx context.Context, benchmark *storage.Benchmark) error {
+	benchmark.Status = storage.BenchmarkPending
+	benchmark.CreatedAt = now()
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		err := s.queryRow(ctx, tx, "INSERT INTO benchmarks(user_id, generator, sizes, repetitions, seed, status, created_at) "+
+			"VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING id", benchmark.UserID, benchmark.Generator, formatSizes(benchmark.Sizes),
+			benchmark.Repetitions, benchmark.Seed, benchmark.Status, benchmark.CreatedAt).Scan(&benchmark.ID)
+		if err != nil {
+			return err
+		}
+		for i := range benchmark.Algorithms {
+			algorithm := &benchmark.Algorithms[i]
+			algorithm.Status = storage.BenchmarkPending
+			algorithm.Measurements = []storage.BenchmarkMeasurement{}
+			_, err := s.exec(ctx, tx, "INSERT INTO benchmark_algorithms(benchmark_id, position, algorithm_id, title, programming_language, "+
+				"status, time_complexity, space_complexity) VALUES($1, $2, $3, $4, $5, $6, $7, $8)", benchmark.ID, i, algorithm.AlgorithmID,
+				algorithm.Title, algorithm.ProgrammingLanguage, algorithm.Status, algorithm.TimeComplexity, algorithm.SpaceComplexity)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Store) GetBenchmark(ctx context.Context, id int) (storage.Benchmark, error) {
+	benchmark, err := scanBenchmark(s.queryRow(ctx, s.db, "SELECT "+benchmarkColumns+" FROM benchmarks WHERE id = $1", id))
+	if err != nil {
+		return benchmark, mapErr(err)
+	}
+
+	rows, err := s.query(ctx, s.db, "SELECT "+benchmarkAlgorithmColumns+" FROM benchmark_algorithms WHERE benchmark_id = $1 ORDER BY position", id)
+	if err != nil {
+		return benchmark, err
+	}
+	defer rows.Close()
+	index := map[int]int{}
+	for rows.Next() {
+		algorithm, err := scanBenchmarkAlgorithm(rows)
+		if err != nil {
+			return benchmark, err
+		}
+		index[algorithm.AlgorithmID] = len(benchmark.Algorithms)
+		benchmark.Algorithms = append(benchmark.Algorithms, algorithm)
+	}
+	if err := rows.Err(); err != nil {
+		return benchmark, err
+	}
+
+	measurements, err := s.query(ctx, s.db, "SELECT algorithm_id, size, status, wall_time_ms, cpu_time_ms, memory_kb "+
+		"FROM benchmark_measurements WHERE benchmark_id = $1 ORDER BY size", id)
+	if err != nil {
+		return benchmark, err
+	}
+	defer measurements.Close()
+	for measurements.Next() {
+		var algorithmID int
+		var measurement storage.BenchmarkMeasurement
+		err := measurements.Scan(&algorithmID, &measurement.Size, &measurement.Status, &measurement.WallTimeMS, &measurement.CPUTimeMS,
+			&measurement.MemoryKB)
+		if err != nil {
+			return benchmark, err
+		}
+		if i, ok := index[algorithmID]; ok {
+			benchmark.Algorithms[i].Measurements = append(benchmark.Algorithms[i].Measurements, measurement)
+		}
+	}
+	return benchmark, measurements.Err()
+}
+
+func (s *Store) ListBenchmarks(ctx context.Context, userID int) ([]storage.Benchmark, error) {
+	rows, err := s.query(ctx, s.db, "SELECT "+benchmarkColumns+" FROM benchmarks WHERE user_id = $1 ORDER BY id DESC", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	benchmarks := []storage.Benchmark{}
+	index := map[int]int{}
+	for rows.Next() {
+		benchmark, err := scanBenchmark(rows)
+		if err != nil {
+			return nil, err
+		}
+		index[benchmark.ID] = len(benchmarks)
+		benchmarks = append(benchmarks, benchmark)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Результаты по размерам списку не нужны, только итоги алгоритмов
+	algorithms, err := s.query(ctx, s.db, "SELECT "+benchmarkAlgorithmColumns+", benchmark_id FROM benchmark_algorithms "+
+		"JOIN benchmarks ON benchmarks.id = benchmark_algorithms.benchmark_id WHERE benchmarks.user_id = $1 ORDER BY position", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer algorithms.Close()
+	for algorithms.Next() {
+		var benchmarkID int
+		algorithm, err := scanBenchmarkAlgorithm(algorithms, &benchmarkID)
+		if err != nil {
+			return nil, err
+		}
+		if i, ok := index[benchmarkID]; ok {
+			benchmarks[i].Algorithms = append(benchmarks[i].Algorithms, algorithm)
+		}
+	}
+	return benchmarks, algorithms.Err()
+}
+
+func (s *Store) ListUnfinishedBenchmarks(ctx context.Context) ([]int, error) {
+	rows, err := s.query(ctx, s.db, "SELECT id FROM benchmarks WHERE status IN ($1, $2) ORDER BY id",
+		storage.BenchmarkPending, storage.BenchmarkRunning)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (s *Store) StartBenchmark(ctx context.Context, id int, at time.Time) error {
+	return s.execOne(ctx, s.db, "UPDATE benchmarks SET status = $1, started_at = $2 WHERE id = $3", storage.BenchmarkRunning, at, id)
+}
+
+func (s *Store) SaveBenchmarkAlgorithm(ctx context.Context, benchmarkID int, algorithm storage.BenchmarkAlgorithm) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		err := s.execOne(ctx, tx, "UPDATE benchmark_algorithms SET revision = $1, status = $2, error = $3, fitted_time_complexity = $4, "+
+			"time_exponent = $5, fitted_space_complexity = $6, space_exponent = $7 WHERE benchmark_id = $8 AND algorithm_id = $9",
+			algorithm.Revision, algorithm.Status, algorithm.Error, algorithm.FittedTimeComplexity, algorithm.TimeExponent,
+			algorithm.FittedSpaceComplexity, algorithm.SpaceExponent, benchmarkID, algorithm.AlgorithmID)
+		if err != nil {
+			return err
+		}
+		_, err = s.exec(ctx, tx, "DELETE FROM benchmark_measurements WHERE benchmark_id = $1 AND algorithm_id = $2", benchmarkID, algorithm.AlgorithmID)
+		if err != nil {
+			return err
+		}
+		for _, measurement := range algorithm.Measurements {
+			_, err := s.exec(ctx, tx, "INSERT INTO benchmark_measurements(benchmark_id, algorithm_id, size, status, wall_time_ms, cpu_time_ms, memory_kb) "+
+				"VALUES($1, $2, $3, $4, $5, $6, $7)", benchmarkID, algorithm.AlgorithmID, measurement.Size, measurement.Status,
+				measurement.WallTimeMS, measurement.CPUTimeMS, measurement.MemoryKB)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Store) FinishBenchmark(ctx context.Context, id int, status, errorMessage string, at time.Time) error {
+	return s.execOne(ctx, s.db, "UPDATE benchmarks SET status = $1, error = $2, finished_at = $3 WHERE id = $4", status, errorMessage, at, id)
+}
+
+func scanBenchmark(row interface{ Scan(...interface{}) error }) (storage.Benchmark, error) {
+	var benchmark storage.Benchmark
+	var sizes string
+	var startedAt, finishedAt sql.NullTime
+	err := row.Scan(&benchmark.ID, &benchmark.UserID, &benchmark.Generator, &sizes, &benchmark.Repetitions, &benchmark.Seed,
+		&benchmark.Status, &benchmark.Error, &benchmark.CreatedAt, &startedAt, &finishedAt)
+	if err != nil {
+		return benchmark, err
+	}
+	benchmark.Sizes = parseSizes(sizes)
+	benchmark.StartedAt, benchmark.FinishedAt = timePtr(startedAt), timePtr(finishedAt)
+	benchmark.Algorithms = []storage.BenchmarkAlgorithm{}
+	return benchmark, nil
+}
+
+func formatSizes(sizes []int) string {
+	values := make([]string, len(sizes))
+	for i, size := range sizes {
+		values[i] = strconv.Itoa(size)
+	}
+	return strings.Join(values, ",")
+}
+
+func parseSizes(value string) []int {
+	sizes := []int{}
+	for _, part := range strings.Split(value, ",") {
+		if size, err := strconv.Atoi(part); err == nil {
+			sizes = append(sizes, size)
+		}
+	}
+	return sizes
+}
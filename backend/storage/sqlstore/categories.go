@@ -0,0 +1,99 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+const categoryColumns = "id, name, parent_id, slug"
+
+func scanCategory(row interface{ Scan(...interface{}) error }) (storage.Category, error) {
+	var category storage.Category
+	var parentID sql.NullInt64
+	err := row.Scan(&category.ID, &category.Name, &parentID, &category.Slug)
+	category.ParentID = intPtr(parentID)
+	return category, err
+}
+
+func (s *Store) ListCategories(ctx context.Context) ([]storage.Category, error) {
+	rows, err := s.query(ctx, s.db, "SELECT "+categoryColumns+" FROM categories ORDER BY LOWER(name), id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []storage.Category = []storage.Category{}
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
+
+func (s *Store) GetCategory(ctx context.Context, id int) (storage.Category, error) {
+	category, err := scanCategory(s.queryRow(ctx, s.db, "SELECT "+categoryColumns+" FROM categories WHERE id = $1", id))
+	return category, mapErr(err)
+}
+
+// checkSiblingSlug возвращает ErrDuplicate, если у родителя уже есть другая категория с тем же slug
+func (s *Store) checkSiblingSlug(ctx context.Context, tx *sql.Tx, category storage.Category) error {
+	parentID := 0
+	if category.ParentID != nil {
+		parentID = *category.ParentID
+	}
+	var exists bool
+	err := s.queryRow(ctx, tx, "SELECT EXISTS(SELECT 1 FROM categories WHERE COALESCE(parent_id, 0) = $1 AND slug = $2 AND id <> $3)",
+		parentID, category.Slug, category.ID).Scan(&exists)
+	if err == nil && exists {
+		err = storage.ErrDuplicate
+	}
+	return err
+}
+
+func (s *Store) CreateCategory(ctx context.Context, category *storage.Category) error {
+	category.Slug = storage.CategorySlug(category.Name)
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := s.checkSiblingSlug(ctx, tx, *category); err != nil {
+			return err
+		}
+		return s.queryRow(ctx, tx, "INSERT INTO categories(name, parent_id, slug) VALUES($1, $2, $3) RETURNING id",
+			category.Name, category.ParentID, category.Slug).Scan(&category.ID)
+	})
+}
+
+func (s *Store) UpdateCategory(ctx context.Context, category storage.Category) error {
+	category.Slug = storage.CategorySlug(category.Name)
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := s.checkSiblingSlug(ctx, tx, category); err != nil {
+			return err
+		}
+		err := s.execOne(ctx, tx, "UPDATE categories SET name = $1, parent_id = $2, slug = $3 WHERE id = $4",
+			category.Name, category.ParentID, category.Slug, category.ID)
+		if err != nil {
+			return err
+		}
+		_, err = s.exec(ctx, tx, "UPDATE algorithms SET topic = $1 WHERE category_id = $2", category.Name, category.ID)
+		return err
+	})
+}
+
+func (s *Store) DeleteCategory(ctx context.Context, id int) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		var inUse bool
+		err := s.queryRow(ctx, tx, "SELECT EXISTS(SELECT 1 FROM categories WHERE parent_id = $1) OR EXISTS(SELECT 1 FROM algorithms WHERE category_id = $1)", id).Scan(&inUse)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return storage.ErrInUse
+		}
+		return s.execOne(ctx, tx, "DELETE FROM categories WHERE id = $1", id)
+	})
+}
@@ -0,0 +1,124 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+const commentColumns = "c.id, c.algorithm_id, c.parent_id, c.user_id, COALESCE(u.username, ''), c.body, c.revision, c.line_start, c.line_end, " +
+	"c.created_at, c.edited_at, c.deleted_at, c.deleted_by"
+
+const commentFrom = " FROM algorithm_comments c LEFT JOIN users u ON u.id = c.user_id"
+
+func scanComment(row interface{ Scan(...interface{}) error }) (storage.Comment, error) {
+	var comment storage.Comment
+	var parentID, revision, lineStart, lineEnd, deletedBy sql.NullInt64
+	var editedAt, deletedAt sql.NullTime
+	err := row.Scan(&comment.ID, &comment.AlgorithmID, &parentID, &comment.UserID, &comment.AuthorUsername, &comment.Body,
+		&revision, &lineStart, &lineEnd, &comment.CreatedAt, &editedAt, &deletedAt, &deletedBy)
+	comment.ParentID, comment.Revision, comment.LineStart, comment.LineEnd = intPtr(parentID), intPtr(revision), intPtr(lineStart), intPtr(lineEnd)
+	comment.DeletedBy = intPtr(deletedBy)
+	comment.EditedAt = timePtr(editedAt)
+	comment.Deleted = deletedAt.Valid
+	return comment, err
+}
+
+func (s *Store) scanComments(rows *sql.Rows, err error) ([]storage.Comment, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []storage.Comment = []storage.Comment{}
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+	return comments, rows.Err()
+}
+
+func (s *Store) CreateComment(ctx context.Context, comment *storage.Comment) error {
+	comment.CreatedAt = now()
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		// root_id — корень ветки, по нему ListComments одним запросом выбирает все ответы страницы
+		var rootID *int
+		if comment.ParentID != nil {
+			var root int
+			err := s.queryRow(ctx, tx, "SELECT COALESCE(root_id, id) FROM algorithm_comments WHERE id = $1", *comment.ParentID).Scan(&root)
+			if err != nil {
+				return mapErr(err)
+			}
+			rootID = &root
+		}
+
+		return s.queryRow(ctx, tx, "INSERT INTO algorithm_comments(algorithm_id, parent_id, root_id, user_id, body, revision, line_start, line_end, created_at) "+
+			"VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id",
+			comment.AlgorithmID, comment.ParentID, rootID, comment.UserID, comment.Body, comment.Revision, comment.LineStart, comment.LineEnd,
+			comment.CreatedAt).Scan(&comment.ID)
+	})
+}
+
+func (s *Store) GetComment(ctx context.Context, id int) (storage.Comment, error) {
+	comment, err := scanComment(s.queryRow(ctx, s.db, "SELECT "+commentColumns+commentFrom+" WHERE c.id = $1", id))
+	return comment, mapErr(err)
+}
+
+func (s *Store) ListComments(ctx context.Context, filter storage.CommentFilter) ([]storage.Comment, error) {
+	query := "SELECT " + commentColumns + commentFrom + " WHERE c.algorithm_id = $1 AND c.parent_id IS NULL AND c.id > $2"
+	args := []interface{}{filter.AlgorithmID, filter.AfterID}
+	if filter.InlineOnly {
+		query += " AND c.line_start IS NOT NULL"
+	}
+	if filter.Revision != 0 {
+		args = append(args, filter.Revision)
+		query += " AND c.revision = $" + strconv.Itoa(len(args))
+	}
+	query += " ORDER BY c.id"
+	if filter.Limit > 0 {
+		query += " LIMIT " + strconv.Itoa(filter.Limit)
+	}
+
+	roots, err := s.scanComments(s.query(ctx, s.db, query, args...))
+	if err != nil || len(roots) == 0 {
+		return roots, err
+	}
+
+	placeholders := make([]string, len(roots))
+	rootIDs := make([]interface{}, len(roots))
+	for i, root := range roots {
+		placeholders[i] = "$" + strconv.Itoa(i+1)
+		rootIDs[i] = root.ID
+	}
+	replies, err := s.scanComments(s.query(ctx, s.db, "SELECT "+commentColumns+commentFrom+
+		" WHERE c.root_id IN ("+strings.Join(placeholders, ", ")+") ORDER BY c.id", rootIDs...))
+	if err != nil {
+		return nil, err
+	}
+	return storage.NestReplies(roots, replies), nil
+}
+
+func (s *Store) UpdateComment(ctx context.Context, id int, body string, editedAt time.Time) (storage.Comment, error) {
+	var comment storage.Comment
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := s.execOne(ctx, tx, "UPDATE algorithm_comments SET body = $1, edited_at = $2 WHERE id = $3 AND deleted_at IS NULL", body, editedAt, id); err != nil {
+			return err
+		}
+		var err error
+		comment, err = scanComment(s.queryRow(ctx, tx, "SELECT "+commentColumns+commentFrom+" WHERE c.id = $1", id))
+		return err
+	})
+	return comment, err
+}
+
+func (s *Store) DeleteComment(ctx context.Context, id, deletedBy int, at time.Time) error {
+	return s.execOne(ctx, s.db, "UPDATE algorithm_comments SET deleted_at = $1, deleted_by = $2 WHERE id = $3 AND deleted_at IS NULL", at, deletedBy, id)
+}
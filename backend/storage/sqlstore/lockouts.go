@@ -0,0 +1,123 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+const loginThrottleColumns = "scope, subject, failures, last_failure_at, locked_until"
+
+func scanLoginThrottle(row interface{ Scan(...interface{}) error }) (storage.LoginThrottle, error) {
+	var throttle storage.LoginThrottle
+	var lockedUntil sql.NullTime
+	err := row.Scan(&throttle.Scope, &throttle.Subject, &throttle.Failures, &throttle.LastFailureAt, &lockedUntil)
+	throttle.LockedUntil = timePtr(lockedUntil)
+	return throttle, err
+}
+
+func (s *Store) ClaimLoginAttempt(ctx context.Context, scope, subject string, at, resetBefore time.Time,
+	delay func(failures int) time.Duration) (storage.LoginThrottle, time.Duration, error) {
+	var throttle storage.LoginThrottle
+	var wait time.Duration
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		// Сначала заводим пустой счётчик: FOR UPDATE блокирует только существующую строку, и без
+		// этого первые одновременные попытки не ждали бы друг друга
+		_, err := s.exec(ctx, tx, "INSERT INTO login_throttles(scope, subject, failures, last_failure_at) VALUES($1, $2, 0, $3) "+
+			"ON CONFLICT (scope, subject) DO NOTHING", scope, subject, at)
+		if err != nil {
+			return err
+		}
+		throttle, err = scanLoginThrottle(s.queryRow(ctx, tx, "SELECT "+loginThrottleColumns+" FROM login_throttles "+
+			"WHERE scope = $1 AND subject = $2 FOR UPDATE", scope, subject))
+		if err != nil {
+			return err
+		}
+
+		if next := throttle.NextAttemptAt(resetBefore, delay); next.After(at) {
+			wait = next.Sub(at)
+			return nil
+		}
+		if throttle.LastFailureAt.Before(resetBefore) {
+			throttle.Failures = 0
+		}
+		throttle.Failures++
+		// Одновременная попытка могла записать время позже нашего
+		if at.After(throttle.LastFailureAt) {
+			throttle.LastFailureAt = at
+		}
+		return s.execOne(ctx, tx, "UPDATE login_throttles SET failures = $1, last_failure_at = $2 WHERE scope = $3 AND subject = $4",
+			throttle.Failures, throttle.LastFailureAt, scope, subject)
+	})
+	return throttle, wait, err
+}
+
+func (s *Store) RefundLoginAttempt(ctx context.Context, scope, subject string) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		result, err := s.exec(ctx, tx, "DELETE FROM login_throttles WHERE scope = $1 AND subject = $2 AND failures <= 1 AND locked_until IS NULL",
+			scope, subject)
+		if err != nil {
+			return err
+		}
+		if deleted, err := result.RowsAffected(); err != nil || deleted > 0 {
+			return err
+		}
+		return s.execOne(ctx, tx, "UPDATE login_throttles SET failures = failures - 1 WHERE scope = $1 AND subject = $2 AND failures > 0",
+			scope, subject)
+	})
+}
+
+func (s *Store) LockLogin(ctx context.Context, scope, subject string, until time.Time, unlockTokenHash string, message *storage.OutboxEmail) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		var tokenHash sql.NullString
+		if unlockTokenHash != "" {
+			tokenHash = sql.NullString{String: unlockTokenHash, Valid: true}
+		}
+		err := s.execOne(ctx, tx, "UPDATE login_throttles SET locked_until = $1, unlock_token_hash = $2 WHERE scope = $3 AND subject = $4",
+			until, tokenHash, scope, subject)
+		if err != nil || message == nil {
+			return err
+		}
+		return s.enqueueEmail(ctx, tx, message)
+	})
+}
+
+func (s *Store) UnlockLogin(ctx context.Context, unlockTokenHash string, at time.Time) (storage.LoginThrottle, error) {
+	throttle, err := scanLoginThrottle(s.queryRow(ctx, s.db, "DELETE FROM login_throttles WHERE unlock_token_hash = $1 AND locked_until > $2 "+
+		"RETURNING "+loginThrottleColumns, unlockTokenHash, at))
+	return throttle, mapErr(err)
+}
+
+func (s *Store) ClearLoginThrottle(ctx context.Context, scope, subject string) error {
+	return s.execOne(ctx, s.db, "DELETE FROM login_throttles WHERE scope = $1 AND subject = $2", scope, subject)
+}
+
+func (s *Store) ListLoginThrottles(ctx context.Context, now, resetBefore time.Time, limit int) ([]storage.LoginThrottle, error) {
+	rows, err := s.query(ctx, s.db, "SELECT "+loginThrottleColumns+" FROM login_throttles WHERE last_failure_at >= $1 OR locked_until > $2 "+
+		"ORDER BY last_failure_at DESC LIMIT $3", resetBefore, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	throttles := []storage.LoginThrottle{}
+	for rows.Next() {
+		throttle, err := scanLoginThrottle(rows)
+		if err != nil {
+			return nil, err
+		}
+		throttles = append(throttles, throttle)
+	}
+	return throttles, rows.Err()
+}
+
+func (s *Store) PurgeLoginThrottles(ctx context.Context, before, now time.Time) (int64, error) {
+	result, err := s.exec(ctx, s.db, "DELETE FROM login_throttles WHERE last_failure_at < $1 AND (locked_until IS NULL OR locked_until <= $2)",
+		before, now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,108 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// metadataOrder — выражения ORDER BY для порядков по метаданным; COALESCE ставит алгоритмы
+// без значения в конец выборки так же, как storage.SortValue
+var metadataOrder = map[string]string{
+	storage.SortEasiest:     fmt.Sprintf("COALESCE(difficulty, %d)", storage.MaxDifficulty+1),
+	storage.SortHardest:     "COALESCE(difficulty, 0)",
+	storage.SortFastest:     fmt.Sprintf("COALESCE(time_complexity_rank, %g)", float64(storage.NoComplexityRank)),
+	storage.SortLeastMemory: fmt.Sprintf("COALESCE(space_complexity_rank, %g)", float64(storage.NoComplexityRank)),
+}
+
+// metadataArgs — значения столбцов difficulty, time_complexity, time_complexity_rank,
+// space_complexity и space_complexity_rank; пустые значения записываются как NULL
+func metadataArgs(algorithm storage.Algorithm) []interface{} {
+	var difficulty interface{}
+	if algorithm.Difficulty != 0 {
+		difficulty = int(algorithm.Difficulty)
+	}
+	args := []interface{}{difficulty}
+	for _, expr := range []string{algorithm.TimeComplexity, algorithm.SpaceComplexity} {
+		if expr == "" {
+			args = append(args, nil, nil)
+		} else {
+			args = append(args, expr, storage.ComplexityRank(expr))
+		}
+	}
+	return args
+}
+
+// savePrerequisites заменяет предварительные требования алгоритма списком algorithm.Prerequisites
+func (s *Store) savePrerequisites(ctx context.Context, tx *sql.Tx, algorithm *storage.Algorithm) error {
+	if _, err := s.exec(ctx, tx, "DELETE FROM algorithm_prerequisites WHERE algorithm_id = $1", algorithm.ID); err != nil {
+		return err
+	}
+	linked := map[int]bool{}
+	prerequisites := []int{}
+	for _, id := range algorithm.Prerequisites {
+		if linked[id] {
+			continue
+		}
+		linked[id] = true
+		_, err := s.exec(ctx, tx, "INSERT INTO algorithm_prerequisites(algorithm_id, prerequisite_id) VALUES($1, $2)", algorithm.ID, id)
+		if err != nil {
+			return err
+		}
+		prerequisites = append(prerequisites, id)
+	}
+	sort.Ints(prerequisites)
+	algorithm.Prerequisites = prerequisites
+	return nil
+}
+
+// loadLinks заполняет Tags и Prerequisites у алгоритмов
+func (s *Store) loadLinks(ctx context.Context, db querier, algorithms []storage.Algorithm) error {
+	if err := s.loadTags(ctx, db, algorithms); err != nil {
+		return err
+	}
+	return s.loadPrerequisites(ctx, db, algorithms)
+}
+
+// loadOneLinks заполняет Tags и Prerequisites одного алгоритма
+func (s *Store) loadOneLinks(ctx context.Context, db querier, algorithm *storage.Algorithm) error {
+	loaded := []storage.Algorithm{*algorithm}
+	err := s.loadLinks(ctx, db, loaded)
+	algorithm.Tags, algorithm.Prerequisites = loaded[0].Tags, loaded[0].Prerequisites
+	return err
+}
+
+// loadPrerequisites заполняет Prerequisites у алгоритмов одним запросом
+func (s *Store) loadPrerequisites(ctx context.Context, db querier, algorithms []storage.Algorithm) error {
+	if len(algorithms) == 0 {
+		return nil
+	}
+
+	ids := make([]interface{}, len(algorithms))
+	index := map[int]int{}
+	for i := range algorithms {
+		algorithms[i].Prerequisites = []int{}
+		ids[i] = algorithms[i].ID
+		index[algorithms[i].ID] = i
+	}
+
+	rows, err := s.query(ctx, db, "SELECT algorithm_id, prerequisite_id FROM algorithm_prerequisites "+
+		"WHERE algorithm_id IN ("+placeholders(1, len(ids))+") ORDER BY prerequisite_id", ids...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var algorithmID, prerequisiteID int
+		if err := rows.Scan(&algorithmID, &prerequisiteID); err != nil {
+			return err
+		}
+		i := index[algorithmID]
+		algorithms[i].Prerequisites = append(algorithms[i].Prerequisites, prerequisiteID)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,256 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+// migrationLockKey — ключ advisory-блокировки PostgreSQL, под которой применяются миграции.
+// Несколько экземпляров сервера, стартующих одновременно, выполняют миграции по очереди.
+const migrationLockKey = 724913
+
+var migrationNameRe = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration — одна версия схемы из каталога migrations/<диалект>
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus описывает версию схемы и время её применения; AppliedAt == nil у ещё не применённых.
+// Missing отмечает версии, записанные в базе, но отсутствующие в этой сборке.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+	Missing   bool
+}
+
+// loadMigrations читает встроенные миграции диалекта, упорядоченные по версии
+func loadMigrations(dialect string) ([]Migration, error) {
+	dir := path.Join("migrations", dialect)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := migrationNameRe.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(migrationFiles, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %04d has conflicting names %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s must have both up and down files", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// withMigrationLock выполняет fn на отдельном соединении, удерживая блокировку миграций.
+// Таблица schema_migrations создаётся здесь же, если её ещё нет.
+func (s *Store) withMigrationLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// В SQLite у хранилища единственное соединение, так что отдельная блокировка не нужна
+	if s.dialect == Postgres {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey)
+	}
+
+	appliedAtType := "TIMESTAMPTZ"
+	if s.dialect == SQLite {
+		appliedAtType = "TIMESTAMP"
+	}
+	_, err = conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations ("+
+		"version INTEGER PRIMARY KEY, name TEXT NOT NULL, applied_at "+appliedAtType+" NOT NULL)")
+	if err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// appliedMigrations возвращает записанные в schema_migrations версии в порядке возрастания
+func (s *Store) appliedMigrations(ctx context.Context, conn *sql.Conn) ([]MigrationStatus, error) {
+	rows, err := s.query(ctx, conn, "SELECT version, name, applied_at FROM schema_migrations ORDER BY version")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var applied []MigrationStatus
+	for rows.Next() {
+		var status MigrationStatus
+		var appliedAt time.Time
+		if err := rows.Scan(&status.Version, &status.Name, &appliedAt); err != nil {
+			return nil, err
+		}
+		status.AppliedAt = &appliedAt
+		applied = append(applied, status)
+	}
+	return applied, rows.Err()
+}
+
+// runMigration выполняет SQL миграции и изменяет schema_migrations в одной транзакции,
+// поэтому упавшая миграция не оставляет схему в промежуточном состоянии
+func (s *Store) runMigration(ctx context.Context, conn *sql.Conn, script, record string, args ...interface{}) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := s.exec(ctx, tx, record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MigrateUp применяет все ещё не применённые миграции и возвращает их в порядке применения
+func (s *Store) MigrateUp(ctx context.Context) ([]Migration, error) {
+	migrations, err := loadMigrations(s.dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	err = s.withMigrationLock(ctx, func(conn *sql.Conn) error {
+		applied, err := s.appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+		isApplied := map[int]bool{}
+		for _, status := range applied {
+			isApplied[status.Version] = true
+		}
+
+		for _, m := range migrations {
+			if isApplied[m.Version] {
+				continue
+			}
+			err := s.runMigration(ctx, conn, m.Up, "INSERT INTO schema_migrations(version, name, applied_at) VALUES($1, $2, $3)",
+				m.Version, m.Name, now())
+			if err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", m.Version, m.Name, err)
+			}
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// MigrateDown откатывает steps последних применённых миграций и возвращает их в порядке отката
+func (s *Store) MigrateDown(ctx context.Context, steps int) ([]Migration, error) {
+	migrations, err := loadMigrations(s.dialect)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int]Migration{}
+	for _, m := range migrations {
+		byVersion[m.Version] = m
+	}
+
+	var done []Migration
+	err = s.withMigrationLock(ctx, func(conn *sql.Conn) error {
+		applied, err := s.appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(applied) - 1; i >= 0 && len(done) < steps; i-- {
+			m, ok := byVersion[applied[i].Version]
+			if !ok {
+				return fmt.Errorf("migration %04d_%s is applied but unknown to this build", applied[i].Version, applied[i].Name)
+			}
+			err := s.runMigration(ctx, conn, m.Down, "DELETE FROM schema_migrations WHERE version = $1", m.Version)
+			if err != nil {
+				return fmt.Errorf("revert migration %04d_%s: %w", m.Version, m.Name, err)
+			}
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// MigrationStatus возвращает все известные сборке миграции и записанные в базе версии
+func (s *Store) MigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
+	migrations, err := loadMigrations(s.dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	err = s.withMigrationLock(ctx, func(conn *sql.Conn) error {
+		applied, err := s.appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+		appliedAt := map[int]*time.Time{}
+		known := map[int]bool{}
+		for _, m := range migrations {
+			known[m.Version] = true
+		}
+		for _, status := range applied {
+			appliedAt[status.Version] = status.AppliedAt
+			if !known[status.Version] {
+				status.Missing = true
+				statuses = append(statuses, status)
+			}
+		}
+		for _, m := range migrations {
+			statuses = append(statuses, MigrationStatus{Version: m.Version, Name: m.Name, AppliedAt: appliedAt[m.Version]})
+		}
+		return nil
+	})
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, err
+}
@@ -0,0 +1,149 @@
+package sqlstore
+
+import (
+	"context"
+	"testing"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	postgres, err := loadMigrations(Postgres)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlite, err := loadMigrations(SQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Версии идут подряд с единицы и одинаковы в обоих диалектах: схема не должна расходиться
+	if len(postgres) != len(sqlite) {
+		t.Fatalf("postgres has %d migrations, sqlite has %d", len(postgres), len(sqlite))
+	}
+	for i := range postgres {
+		if postgres[i].Version != i+1 {
+			t.Errorf("postgres migration %d has version %d", i+1, postgres[i].Version)
+		}
+		if postgres[i].Version != sqlite[i].Version || postgres[i].Name != sqlite[i].Name {
+			t.Errorf("migration %d: postgres %04d_%s, sqlite %04d_%s", i+1,
+				postgres[i].Version, postgres[i].Name, sqlite[i].Version, sqlite[i].Name)
+		}
+	}
+}
+
+// userTables возвращает таблицы схемы, кроме schema_migrations и служебных таблиц SQLite
+func userTables(t *testing.T, s *Store) []string {
+	t.Helper()
+	query := "SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema() AND table_name <> 'schema_migrations'"
+	if s.dialect == SQLite {
+		query = "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name <> 'schema_migrations'"
+	}
+	rows, err := s.db.Query(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		tables = append(tables, name)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return tables
+}
+
+func appliedCount(t *testing.T, s *Store) int {
+	t.Helper()
+	statuses, err := s.MigrationStatus(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied := 0
+	for _, status := range statuses {
+		if status.AppliedAt != nil {
+			applied++
+		}
+	}
+	return applied
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		dialect string
+		open    func(t *testing.T) *Store
+	}{
+		{SQLite, openSQLite},
+		{Postgres, openPostgres},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			s := tt.open(t)
+			all, err := loadMigrations(tt.dialect)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			done, err := s.MigrateUp(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(done) != len(all) || appliedCount(t, s) != len(all) {
+				t.Fatalf("first up applied %d of %d migrations", len(done), len(all))
+			}
+			if done, err := s.MigrateUp(ctx); err != nil || len(done) != 0 {
+				t.Fatalf("second up applied %d migrations, err %v", len(done), err)
+			}
+
+			// Откат последней миграции и повторное применение
+			last := all[len(all)-1]
+			done, err = s.MigrateDown(ctx, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(done) != 1 || done[0].Version != last.Version {
+				t.Fatalf("down 1 reverted %v, want %04d", done, last.Version)
+			}
+			if done, err := s.MigrateUp(ctx); err != nil || len(done) != 1 || done[0].Version != last.Version {
+				t.Fatalf("up after down 1 applied %v, err %v", done, err)
+			}
+
+			// Полный откат удаляет все таблицы в обратном порядке
+			done, err = s.MigrateDown(ctx, len(all)+1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(done) != len(all) || done[0].Version != last.Version || done[len(done)-1].Version != 1 {
+				t.Fatalf("full down reverted %d of %d migrations", len(done), len(all))
+			}
+			if applied := appliedCount(t, s); applied != 0 {
+				t.Errorf("%d migrations still applied after full down", applied)
+			}
+			if tables := userTables(t, s); len(tables) != 0 {
+				t.Errorf("tables left after full down: %v", tables)
+			}
+
+			// Схема снова поднимается с нуля и работает
+			if done, err := s.MigrateUp(ctx); err != nil || len(done) != len(all) {
+				t.Fatalf("up after full down applied %d migrations, err %v", len(done), err)
+			}
+			categories, err := s.ListCategories(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(categories) != len(storage.DefaultCategories) {
+				t.Errorf("got %d categories after re-migration, want %d", len(categories), len(storage.DefaultCategories))
+			}
+			if err := s.CreateUser(ctx, &storage.User{Username: "alice", Email: "alice@example.com"}, "hash"); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
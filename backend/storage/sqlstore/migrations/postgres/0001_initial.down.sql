@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS password_reset_tokens;
+DROP TABLE IF EXISTS email_verification_tokens;
+DROP TABLE IF EXISTS algorithms;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS users;
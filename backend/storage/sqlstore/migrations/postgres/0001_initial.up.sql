@@ -0,0 +1,48 @@
+-- Базовая схема, с которой проект жил до появления миграций. IF NOT EXISTS позволяет
+-- применить миграцию к базе, созданной вручную по DATABASE_SCHEMA.MD.
+
+CREATE TABLE IF NOT EXISTS users (
+    id SERIAL PRIMARY KEY,
+    username VARCHAR(50) NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    email VARCHAR(100) NOT NULL,
+    role VARCHAR(10) DEFAULT 'user',
+    confirmed BOOLEAN DEFAULT false
+);
+
+CREATE TABLE IF NOT EXISTS categories (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS algorithms (
+    id SERIAL PRIMARY KEY,
+    title VARCHAR(100) NOT NULL,
+    description TEXT,
+    code TEXT,
+    user_id INTEGER REFERENCES users(id),
+    category_id INTEGER REFERENCES categories(id),
+    rating DOUBLE PRECISION DEFAULT 0,
+    approved BOOLEAN DEFAULT false,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    topic VARCHAR(100),
+    programming_language VARCHAR(50)
+);
+
+CREATE TABLE IF NOT EXISTS email_verification_tokens (
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    token VARCHAR(32) PRIMARY KEY,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    email VARCHAR(255) NOT NULL,
+    username VARCHAR(255) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    username VARCHAR(255) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS algorithms_user_id_idx ON algorithms(user_id);
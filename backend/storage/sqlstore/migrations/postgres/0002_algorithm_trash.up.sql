@@ -0,0 +1,4 @@
+ALTER TABLE algorithms ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- Очистка корзины ищет только удалённые строки
+CREATE INDEX IF NOT EXISTS algorithms_deleted_at_idx ON algorithms(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS algorithms_moderation_status_idx;
+ALTER TABLE algorithms
+    DROP COLUMN IF EXISTS moderated_at,
+    DROP COLUMN IF EXISTS moderated_by,
+    DROP COLUMN IF EXISTS moderation_comment,
+    DROP COLUMN IF EXISTS moderation_status;
@@ -0,0 +1,10 @@
+ALTER TABLE algorithms
+    ADD COLUMN IF NOT EXISTS moderation_status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    ADD COLUMN IF NOT EXISTS moderation_comment TEXT,
+    ADD COLUMN IF NOT EXISTS moderated_by INTEGER REFERENCES users(id),
+    ADD COLUMN IF NOT EXISTS moderated_at TIMESTAMPTZ;
+
+-- Алгоритмы, одобренные до появления статусов, остаются опубликованными
+UPDATE algorithms SET moderation_status = 'approved' WHERE approved = true AND moderation_status = 'pending';
+
+CREATE INDEX IF NOT EXISTS algorithms_moderation_status_idx ON algorithms(moderation_status, created_at);
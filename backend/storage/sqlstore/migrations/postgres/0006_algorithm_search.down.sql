@@ -0,0 +1,4 @@
+-- Расширение pg_trgm не удаляется: его могут использовать другие объекты базы
+DROP INDEX IF EXISTS algorithms_title_trgm_idx;
+DROP INDEX IF EXISTS algorithms_search_idx;
+ALTER TABLE algorithms DROP COLUMN IF EXISTS search_vector;
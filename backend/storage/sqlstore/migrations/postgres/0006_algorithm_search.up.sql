@@ -0,0 +1,15 @@
+-- pg_trgm нужен для поиска по названию с опечатками
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Название и описание индексируются английским и русским словарями, чтобы работал стемминг на обоих языках.
+-- Код разбирается словарём simple: идентификаторы не стеммируются.
+ALTER TABLE algorithms ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector('russian', COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'B') ||
+    setweight(to_tsvector('russian', COALESCE(description, '')), 'B') ||
+    setweight(to_tsvector('simple', COALESCE(code, '')), 'C')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS algorithms_search_idx ON algorithms USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS algorithms_title_trgm_idx ON algorithms USING GIN (title gin_trgm_ops);
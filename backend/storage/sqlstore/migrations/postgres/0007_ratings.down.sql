@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS algorithms_rating_idx;
+ALTER TABLE algorithms
+    DROP COLUMN IF EXISTS rating_count,
+    DROP COLUMN IF EXISTS rating_average;
+DROP TABLE IF EXISTS algorithm_ratings;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS algorithm_ratings (
+    algorithm_id INTEGER NOT NULL REFERENCES algorithms(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    score SMALLINT NOT NULL CHECK (score BETWEEN 1 AND 5),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (algorithm_id, user_id)
+);
+
+-- rating — оценка для сортировки most_popular, rating_average и rating_count — агрегаты для показа.
+-- Все три пересчитываются в транзакции, изменяющей algorithm_ratings.
+ALTER TABLE algorithms
+    ADD COLUMN IF NOT EXISTS rating_average DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rating_count INTEGER NOT NULL DEFAULT 0;
+
+-- Раньше rating никто не записывал, оценок ещё нет
+UPDATE algorithms SET rating = 0 WHERE rating IS NULL OR rating <> 0;
+
+CREATE INDEX IF NOT EXISTS algorithms_rating_idx ON algorithms ((COALESCE(rating, 0)) DESC, id);
@@ -0,0 +1,22 @@
+-- parent_id — комментарий, на который отвечают; root_id — корень ветки (NULL у корневых),
+-- по нему ответы страницы выбираются одним запросом. revision, line_start и line_end
+-- привязывают комментарий к строкам кода конкретной ревизии.
+CREATE TABLE IF NOT EXISTS algorithm_comments (
+    id SERIAL PRIMARY KEY,
+    algorithm_id INTEGER NOT NULL REFERENCES algorithms(id) ON DELETE CASCADE,
+    parent_id INTEGER REFERENCES algorithm_comments(id) ON DELETE CASCADE,
+    root_id INTEGER REFERENCES algorithm_comments(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    revision INTEGER,
+    line_start INTEGER,
+    line_end INTEGER,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    edited_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    deleted_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    CHECK (line_start IS NULL OR (revision IS NOT NULL AND line_start >= 1 AND line_end >= line_start))
+);
+
+CREATE INDEX IF NOT EXISTS algorithm_comments_algorithm_idx ON algorithm_comments (algorithm_id, id) WHERE parent_id IS NULL;
+CREATE INDEX IF NOT EXISTS algorithm_comments_root_idx ON algorithm_comments (root_id, id);
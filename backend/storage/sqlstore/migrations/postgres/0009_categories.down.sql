@@ -0,0 +1,8 @@
+-- Созданные категории остаются, алгоритмы от них отвязываются; topic при этом не меняется
+DROP INDEX IF EXISTS algorithms_category_idx;
+DROP INDEX IF EXISTS categories_parent_slug_idx;
+UPDATE algorithms SET category_id = NULL;
+ALTER TABLE algorithm_revisions DROP COLUMN IF EXISTS category_id;
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS slug,
+    DROP COLUMN IF EXISTS parent_id;
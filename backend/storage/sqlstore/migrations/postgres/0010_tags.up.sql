@@ -0,0 +1,19 @@
+-- name — нормализованное имя тега, см. storage.NormalizeTag. Синоним ссылается на основной тег
+-- через canonical_id; к алгоритмам привязываются только основные теги.
+CREATE TABLE IF NOT EXISTS tags (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    canonical_id INTEGER REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS algorithm_tags (
+    algorithm_id INTEGER NOT NULL REFERENCES algorithms(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (algorithm_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS algorithm_tags_tag_idx ON algorithm_tags (tag_id);
+CREATE INDEX IF NOT EXISTS tags_canonical_idx ON tags (canonical_id);
+-- Автодополнение ищет по префиксу имени
+CREATE INDEX IF NOT EXISTS tags_name_prefix_idx ON tags (name varchar_pattern_ops);
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS algorithm_prerequisites;
+DROP INDEX IF EXISTS algorithms_difficulty_idx;
+DROP INDEX IF EXISTS algorithms_time_complexity_idx;
+DROP INDEX IF EXISTS algorithms_space_complexity_idx;
+ALTER TABLE algorithms
+    DROP COLUMN IF EXISTS space_complexity_rank,
+    DROP COLUMN IF EXISTS space_complexity,
+    DROP COLUMN IF EXISTS time_complexity_rank,
+    DROP COLUMN IF EXISTS time_complexity,
+    DROP COLUMN IF EXISTS difficulty;
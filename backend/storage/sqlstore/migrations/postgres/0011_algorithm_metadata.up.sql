@@ -0,0 +1,20 @@
+-- difficulty — от 1 до 10, см. storage.Difficulty. Оценки сложности хранятся нормализованными
+-- (complexity.Parse) вместе с рангом роста, по которому они сравниваются и сортируются.
+ALTER TABLE algorithms
+    ADD COLUMN IF NOT EXISTS difficulty SMALLINT CHECK (difficulty BETWEEN 1 AND 10),
+    ADD COLUMN IF NOT EXISTS time_complexity VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS time_complexity_rank DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS space_complexity VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS space_complexity_rank DOUBLE PRECISION;
+
+CREATE TABLE IF NOT EXISTS algorithm_prerequisites (
+    algorithm_id INTEGER NOT NULL REFERENCES algorithms(id) ON DELETE CASCADE,
+    prerequisite_id INTEGER NOT NULL REFERENCES algorithms(id) ON DELETE CASCADE,
+    PRIMARY KEY (algorithm_id, prerequisite_id),
+    CHECK (algorithm_id <> prerequisite_id)
+);
+
+CREATE INDEX IF NOT EXISTS algorithm_prerequisites_prerequisite_idx ON algorithm_prerequisites (prerequisite_id);
+CREATE INDEX IF NOT EXISTS algorithms_difficulty_idx ON algorithms (difficulty);
+CREATE INDEX IF NOT EXISTS algorithms_time_complexity_idx ON algorithms (time_complexity_rank);
+CREATE INDEX IF NOT EXISTS algorithms_space_complexity_idx ON algorithms (space_complexity_rank);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS algorithms_verification_status_idx;
+ALTER TABLE algorithms DROP COLUMN IF EXISTS verification_status;
+DROP TABLE IF EXISTS algorithm_verifications;
+DROP TABLE IF EXISTS algorithm_test_cases;
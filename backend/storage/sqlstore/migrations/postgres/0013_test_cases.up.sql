@@ -0,0 +1,28 @@
+-- Тесты алгоритмов: вход, ожидаемый вывод и способ сравнения, см. storage.TestCase
+CREATE TABLE IF NOT EXISTS algorithm_test_cases (
+    id SERIAL PRIMARY KEY,
+    algorithm_id INTEGER NOT NULL REFERENCES algorithms(id) ON DELETE CASCADE,
+    input TEXT NOT NULL,
+    expected_output TEXT NOT NULL,
+    hidden BOOLEAN NOT NULL DEFAULT FALSE,
+    comparison VARCHAR(20) NOT NULL DEFAULT 'exact' CHECK (comparison IN ('exact', 'whitespace', 'float')),
+    tolerance DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS algorithm_test_cases_algorithm_idx ON algorithm_test_cases (algorithm_id, id);
+
+-- Результат прогона тестов по ревизиям. verification_status в algorithms — копия результата
+-- последней ревизии, чтобы фильтровать списки без соединения с историей.
+CREATE TABLE IF NOT EXISTS algorithm_verifications (
+    algorithm_id INTEGER NOT NULL REFERENCES algorithms(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    status VARCHAR(10) NOT NULL CHECK (status IN ('passed', 'failed')),
+    passed INTEGER NOT NULL,
+    total INTEGER NOT NULL,
+    verified_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (algorithm_id, revision)
+);
+
+ALTER TABLE algorithms ADD COLUMN IF NOT EXISTS verification_status VARCHAR(10);
+CREATE INDEX IF NOT EXISTS algorithms_verification_status_idx ON algorithms (verification_status);
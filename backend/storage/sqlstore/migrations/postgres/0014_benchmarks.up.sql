@@ -0,0 +1,48 @@
+-- Замеры производительности, см. storage.Benchmark. Размеры хранятся списком через запятую.
+CREATE TABLE IF NOT EXISTS benchmarks (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    generator VARCHAR(50) NOT NULL,
+    sizes TEXT NOT NULL,
+    repetitions INTEGER NOT NULL,
+    seed BIGINT NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'done', 'failed')),
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS benchmarks_user_idx ON benchmarks (user_id, id);
+CREATE INDEX IF NOT EXISTS benchmarks_status_idx ON benchmarks (status);
+
+-- Алгоритмы замера. Ссылки на algorithms нет: результаты остаются и после удаления алгоритма.
+CREATE TABLE IF NOT EXISTS benchmark_algorithms (
+    benchmark_id INTEGER NOT NULL REFERENCES benchmarks(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    algorithm_id INTEGER NOT NULL,
+    title VARCHAR(100) NOT NULL,
+    programming_language VARCHAR(50) NOT NULL,
+    revision INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    error TEXT NOT NULL DEFAULT '',
+    time_complexity VARCHAR(100) NOT NULL DEFAULT '',
+    space_complexity VARCHAR(100) NOT NULL DEFAULT '',
+    fitted_time_complexity VARCHAR(100) NOT NULL DEFAULT '',
+    time_exponent DOUBLE PRECISION NOT NULL DEFAULT 0,
+    fitted_space_complexity VARCHAR(100) NOT NULL DEFAULT '',
+    space_exponent DOUBLE PRECISION NOT NULL DEFAULT 0,
+    PRIMARY KEY (benchmark_id, algorithm_id)
+);
+
+CREATE TABLE IF NOT EXISTS benchmark_measurements (
+    benchmark_id INTEGER NOT NULL,
+    algorithm_id INTEGER NOT NULL,
+    size INTEGER NOT NULL,
+    status VARCHAR(30) NOT NULL,
+    wall_time_ms DOUBLE PRECISION NOT NULL,
+    cpu_time_ms DOUBLE PRECISION NOT NULL,
+    memory_kb BIGINT NOT NULL,
+    PRIMARY KEY (benchmark_id, algorithm_id, size),
+    FOREIGN KEY (benchmark_id, algorithm_id) REFERENCES benchmark_algorithms (benchmark_id, algorithm_id) ON DELETE CASCADE
+);
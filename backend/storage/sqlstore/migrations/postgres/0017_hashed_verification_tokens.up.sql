@@ -0,0 +1,16 @@
+-- Токены подтверждения email хранятся как SHA-256, со сроком действия и отметкой использования.
+-- Хешей выданных ранее токенов посчитать нельзя, поэтому старые ссылки перестают работать:
+-- новую можно запросить через POST /resend-verification.
+DROP TABLE IF EXISTS email_verification_tokens;
+
+CREATE TABLE IF NOT EXISTS email_verification_tokens (
+    token_hash VARCHAR(64) PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    username VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS email_verification_tokens_user_id_idx ON email_verification_tokens(user_id);
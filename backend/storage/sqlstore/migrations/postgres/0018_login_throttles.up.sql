@@ -0,0 +1,13 @@
+-- Неудачные попытки входа по имени пользователя и по IP-адресу, см. storage.LoginThrottle.
+-- Хранится только хеш токена разблокировки из письма.
+CREATE TABLE IF NOT EXISTS login_throttles (
+    scope VARCHAR(10) NOT NULL CHECK (scope IN ('account', 'ip')),
+    subject VARCHAR(255) NOT NULL,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMPTZ NOT NULL,
+    locked_until TIMESTAMPTZ,
+    unlock_token_hash VARCHAR(64),
+    PRIMARY KEY (scope, subject)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS login_throttles_unlock_token_hash_idx ON login_throttles(unlock_token_hash);
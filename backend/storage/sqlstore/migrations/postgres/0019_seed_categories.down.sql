@@ -0,0 +1,8 @@
+-- Удаляем только стандартные категории, которыми так и не воспользовались
+DELETE FROM categories
+WHERE parent_id IS NULL
+  AND slug IN ('sorting', 'searching', 'graph', 'tree', 'dynamic programming',
+               'greedy algorithm', 'string', 'mathematic', 'geometry', 'data structure')
+  AND NOT EXISTS (SELECT 1 FROM categories child WHERE child.parent_id = categories.id)
+  AND NOT EXISTS (SELECT 1 FROM algorithms a WHERE a.category_id = categories.id)
+  AND NOT EXISTS (SELECT 1 FROM algorithm_revisions r WHERE r.category_id = categories.id);
@@ -0,0 +1,19 @@
+-- Стандартные корневые категории, чтобы на новой базе было из чего выбирать.
+-- Тот же список заполняет memstore, см. storage.DefaultCategories; slug посчитан storage.CategorySlug.
+-- Уже существующие одноимённые категории не трогаем.
+WITH defaults(name, slug) AS (
+    VALUES
+        ('Sorting', 'sorting'),
+        ('Searching', 'searching'),
+        ('Graphs', 'graph'),
+        ('Trees', 'tree'),
+        ('Dynamic programming', 'dynamic programming'),
+        ('Greedy algorithms', 'greedy algorithm'),
+        ('Strings', 'string'),
+        ('Mathematics', 'mathematic'),
+        ('Geometry', 'geometry'),
+        ('Data structures', 'data structure')
+)
+INSERT INTO categories(name, slug)
+SELECT name, slug FROM defaults
+WHERE NOT EXISTS (SELECT 1 FROM categories c WHERE c.parent_id IS NULL AND c.slug = defaults.slug);
@@ -0,0 +1,2 @@
+-- Стёртые тела писем не восстановить
+SELECT 1;
@@ -0,0 +1,48 @@
+-- Та же базовая схема, что и для PostgreSQL. Метки времени объявлены как TIMESTAMP, а флаги как BOOLEAN,
+-- чтобы драйвер SQLite отдавал их как time.Time и bool.
+
+CREATE TABLE users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username VARCHAR(50) NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    email VARCHAR(100) NOT NULL,
+    role VARCHAR(10) DEFAULT 'user',
+    confirmed BOOLEAN DEFAULT false
+);
+
+CREATE TABLE categories (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(50) NOT NULL
+);
+
+CREATE TABLE algorithms (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title VARCHAR(100) NOT NULL,
+    description TEXT,
+    code TEXT,
+    user_id INTEGER REFERENCES users(id),
+    category_id INTEGER REFERENCES categories(id),
+    rating DOUBLE PRECISION DEFAULT 0,
+    approved BOOLEAN DEFAULT false,
+    created_at TIMESTAMP NOT NULL,
+    topic VARCHAR(100),
+    programming_language VARCHAR(50)
+);
+
+CREATE TABLE email_verification_tokens (
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    token VARCHAR(32) PRIMARY KEY,
+    created_at TIMESTAMP,
+    email VARCHAR(255) NOT NULL,
+    username VARCHAR(255) NOT NULL
+);
+
+CREATE TABLE password_reset_tokens (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    username VARCHAR(255) NOT NULL
+);
+
+CREATE INDEX algorithms_user_id_idx ON algorithms(user_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS algorithms_deleted_at_idx;
+ALTER TABLE algorithms DROP COLUMN deleted_at;
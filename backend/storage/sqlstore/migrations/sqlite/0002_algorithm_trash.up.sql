@@ -0,0 +1,3 @@
+ALTER TABLE algorithms ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX algorithms_deleted_at_idx ON algorithms(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,13 @@
+CREATE TABLE algorithm_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    algorithm_id INTEGER NOT NULL REFERENCES algorithms(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    title VARCHAR(100) NOT NULL,
+    code TEXT,
+    topic VARCHAR(100),
+    programming_language VARCHAR(50),
+    user_id INTEGER REFERENCES users(id),
+    message TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (algorithm_id, revision)
+);
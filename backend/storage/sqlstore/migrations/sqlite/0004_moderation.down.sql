@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS algorithms_moderation_status_idx;
+ALTER TABLE algorithms DROP COLUMN moderated_at;
+ALTER TABLE algorithms DROP COLUMN moderated_by;
+ALTER TABLE algorithms DROP COLUMN moderation_comment;
+ALTER TABLE algorithms DROP COLUMN moderation_status;
@@ -0,0 +1,10 @@
+-- SQLite не удаляет столбцы, на которые ссылается внешний ключ, поэтому moderated_by объявлен без REFERENCES,
+-- иначе миграцию нельзя было бы откатить
+ALTER TABLE algorithms ADD COLUMN moderation_status VARCHAR(20) NOT NULL DEFAULT 'pending';
+ALTER TABLE algorithms ADD COLUMN moderation_comment TEXT;
+ALTER TABLE algorithms ADD COLUMN moderated_by INTEGER;
+ALTER TABLE algorithms ADD COLUMN moderated_at TIMESTAMP;
+
+UPDATE algorithms SET moderation_status = 'approved' WHERE approved = true;
+
+CREATE INDEX algorithms_moderation_status_idx ON algorithms(moderation_status, created_at);
@@ -0,0 +1,3 @@
+-- В SQLite полнотекстового индекса нет: запрос q ищет слова подстрокой, а подсветку строит сервер.
+-- Миграция оставлена пустой, чтобы номера версий совпадали с PostgreSQL.
+SELECT 1;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS algorithms_rating_idx;
+ALTER TABLE algorithms DROP COLUMN rating_count;
+ALTER TABLE algorithms DROP COLUMN rating_average;
+DROP TABLE IF EXISTS algorithm_ratings;
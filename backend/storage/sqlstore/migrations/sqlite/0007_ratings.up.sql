@@ -0,0 +1,15 @@
+CREATE TABLE algorithm_ratings (
+    algorithm_id INTEGER NOT NULL REFERENCES algorithms(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    score SMALLINT NOT NULL CHECK (score BETWEEN 1 AND 5),
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (algorithm_id, user_id)
+);
+
+ALTER TABLE algorithms ADD COLUMN rating_average DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE algorithms ADD COLUMN rating_count INTEGER NOT NULL DEFAULT 0;
+
+UPDATE algorithms SET rating = 0 WHERE rating IS NULL OR rating <> 0;
+
+CREATE INDEX algorithms_rating_idx ON algorithms ((COALESCE(rating, 0)) DESC, id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS algorithms_category_idx;
+DROP INDEX IF EXISTS categories_parent_slug_idx;
+UPDATE algorithms SET category_id = NULL;
+ALTER TABLE algorithm_revisions DROP COLUMN category_id;
+ALTER TABLE categories DROP COLUMN slug;
+ALTER TABLE categories DROP COLUMN parent_id;
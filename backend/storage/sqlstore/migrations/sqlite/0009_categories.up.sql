@@ -0,0 +1,38 @@
+ALTER TABLE categories ADD COLUMN parent_id INTEGER REFERENCES categories(id);
+ALTER TABLE categories ADD COLUMN slug VARCHAR(100);
+
+ALTER TABLE algorithm_revisions ADD COLUMN category_id INTEGER REFERENCES categories(id) ON DELETE SET NULL;
+
+-- LOWER в SQLite меняет регистр только у латиницы, поэтому кириллические темы
+-- с разным регистром останутся разными категориями; их можно объединить вручную
+CREATE TEMP TABLE topic_slugs AS
+SELECT topic, CASE WHEN LENGTH(k) > 3 AND k LIKE '%s' AND k NOT LIKE '%ss' THEN SUBSTR(k, 1, LENGTH(k) - 1) ELSE k END AS slug
+FROM (
+    SELECT DISTINCT topic, LOWER(TRIM(topic)) AS k
+    FROM (SELECT topic FROM algorithms UNION SELECT topic FROM algorithm_revisions UNION SELECT name FROM categories) t
+) topics
+WHERE k <> '';
+
+UPDATE categories SET slug = COALESCE((SELECT slug FROM topic_slugs WHERE topic = categories.name), '') WHERE slug IS NULL;
+
+INSERT INTO categories(name, slug)
+SELECT MIN(SUBSTR(TRIM(topic), 1, 50)), slug FROM topic_slugs
+WHERE topic IN (SELECT topic FROM algorithms)
+  AND slug NOT IN (SELECT slug FROM categories WHERE parent_id IS NULL)
+GROUP BY slug;
+
+UPDATE algorithms SET category_id = (
+    SELECT c.id FROM categories c JOIN topic_slugs t ON t.slug = c.slug WHERE c.parent_id IS NULL AND t.topic = algorithms.topic
+) WHERE category_id IS NULL;
+
+UPDATE algorithm_revisions SET category_id = (
+    SELECT c.id FROM categories c JOIN topic_slugs t ON t.slug = c.slug WHERE c.parent_id IS NULL AND t.topic = algorithm_revisions.topic
+) WHERE category_id IS NULL;
+
+-- topic остаётся копией имени категории для старых клиентов и фильтра topic
+UPDATE algorithms SET topic = (SELECT name FROM categories WHERE categories.id = algorithms.category_id) WHERE category_id IS NOT NULL;
+
+DROP TABLE topic_slugs;
+
+CREATE UNIQUE INDEX categories_parent_slug_idx ON categories ((COALESCE(parent_id, 0)), slug);
+CREATE INDEX algorithms_category_idx ON algorithms (category_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS algorithm_tags;
+DROP TABLE IF EXISTS tags;
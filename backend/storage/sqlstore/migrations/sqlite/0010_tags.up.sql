@@ -0,0 +1,15 @@
+CREATE TABLE tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    canonical_id INTEGER REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE algorithm_tags (
+    algorithm_id INTEGER NOT NULL REFERENCES algorithms(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (algorithm_id, tag_id)
+);
+
+CREATE INDEX algorithm_tags_tag_idx ON algorithm_tags (tag_id);
+CREATE INDEX tags_canonical_idx ON tags (canonical_id);
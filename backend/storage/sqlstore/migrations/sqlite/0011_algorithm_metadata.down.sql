@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS algorithm_prerequisites;
+DROP INDEX IF EXISTS algorithms_difficulty_idx;
+DROP INDEX IF EXISTS algorithms_time_complexity_idx;
+DROP INDEX IF EXISTS algorithms_space_complexity_idx;
+ALTER TABLE algorithms DROP COLUMN space_complexity_rank;
+ALTER TABLE algorithms DROP COLUMN space_complexity;
+ALTER TABLE algorithms DROP COLUMN time_complexity_rank;
+ALTER TABLE algorithms DROP COLUMN time_complexity;
+ALTER TABLE algorithms DROP COLUMN difficulty;
@@ -0,0 +1,17 @@
+ALTER TABLE algorithms ADD COLUMN difficulty SMALLINT CHECK (difficulty BETWEEN 1 AND 10);
+ALTER TABLE algorithms ADD COLUMN time_complexity VARCHAR(100);
+ALTER TABLE algorithms ADD COLUMN time_complexity_rank DOUBLE PRECISION;
+ALTER TABLE algorithms ADD COLUMN space_complexity VARCHAR(100);
+ALTER TABLE algorithms ADD COLUMN space_complexity_rank DOUBLE PRECISION;
+
+CREATE TABLE algorithm_prerequisites (
+    algorithm_id INTEGER NOT NULL REFERENCES algorithms(id) ON DELETE CASCADE,
+    prerequisite_id INTEGER NOT NULL REFERENCES algorithms(id) ON DELETE CASCADE,
+    PRIMARY KEY (algorithm_id, prerequisite_id),
+    CHECK (algorithm_id <> prerequisite_id)
+);
+
+CREATE INDEX algorithm_prerequisites_prerequisite_idx ON algorithm_prerequisites (prerequisite_id);
+CREATE INDEX algorithms_difficulty_idx ON algorithms (difficulty);
+CREATE INDEX algorithms_time_complexity_idx ON algorithms (time_complexity_rank);
+CREATE INDEX algorithms_space_complexity_idx ON algorithms (space_complexity_rank);
@@ -0,0 +1,13 @@
+CREATE TABLE algorithm_analysis (
+    algorithm_id INTEGER PRIMARY KEY REFERENCES algorithms(id) ON DELETE CASCADE,
+    analyzer VARCHAR(50) NOT NULL,
+    lines INTEGER NOT NULL,
+    loop_depth INTEGER NOT NULL,
+    recursive BOOLEAN NOT NULL,
+    cyclomatic INTEGER NOT NULL,
+    data_structures TEXT NOT NULL DEFAULT '',
+    difficulty SMALLINT NOT NULL CHECK (difficulty BETWEEN 1 AND 10),
+    time_complexity VARCHAR(100) NOT NULL,
+    space_complexity VARCHAR(100) NOT NULL,
+    analyzed_at TIMESTAMP NOT NULL
+);
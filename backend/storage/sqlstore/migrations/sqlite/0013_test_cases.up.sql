@@ -0,0 +1,25 @@
+CREATE TABLE algorithm_test_cases (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    algorithm_id INTEGER NOT NULL REFERENCES algorithms(id) ON DELETE CASCADE,
+    input TEXT NOT NULL,
+    expected_output TEXT NOT NULL,
+    hidden BOOLEAN NOT NULL DEFAULT FALSE,
+    comparison VARCHAR(20) NOT NULL DEFAULT 'exact' CHECK (comparison IN ('exact', 'whitespace', 'float')),
+    tolerance DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX algorithm_test_cases_algorithm_idx ON algorithm_test_cases (algorithm_id, id);
+
+CREATE TABLE algorithm_verifications (
+    algorithm_id INTEGER NOT NULL REFERENCES algorithms(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    status VARCHAR(10) NOT NULL CHECK (status IN ('passed', 'failed')),
+    passed INTEGER NOT NULL,
+    total INTEGER NOT NULL,
+    verified_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (algorithm_id, revision)
+);
+
+ALTER TABLE algorithms ADD COLUMN verification_status VARCHAR(10);
+CREATE INDEX algorithms_verification_status_idx ON algorithms (verification_status);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS benchmark_measurements;
+DROP TABLE IF EXISTS benchmark_algorithms;
+DROP TABLE IF EXISTS benchmarks;
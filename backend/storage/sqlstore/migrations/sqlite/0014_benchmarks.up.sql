@@ -0,0 +1,46 @@
+CREATE TABLE benchmarks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    generator VARCHAR(50) NOT NULL,
+    sizes TEXT NOT NULL,
+    repetitions INTEGER NOT NULL,
+    seed INTEGER NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'done', 'failed')),
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX benchmarks_user_idx ON benchmarks (user_id, id);
+CREATE INDEX benchmarks_status_idx ON benchmarks (status);
+
+CREATE TABLE benchmark_algorithms (
+    benchmark_id INTEGER NOT NULL REFERENCES benchmarks(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    algorithm_id INTEGER NOT NULL,
+    title VARCHAR(100) NOT NULL,
+    programming_language VARCHAR(50) NOT NULL,
+    revision INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    error TEXT NOT NULL DEFAULT '',
+    time_complexity VARCHAR(100) NOT NULL DEFAULT '',
+    space_complexity VARCHAR(100) NOT NULL DEFAULT '',
+    fitted_time_complexity VARCHAR(100) NOT NULL DEFAULT '',
+    time_exponent DOUBLE PRECISION NOT NULL DEFAULT 0,
+    fitted_space_complexity VARCHAR(100) NOT NULL DEFAULT '',
+    space_exponent DOUBLE PRECISION NOT NULL DEFAULT 0,
+    PRIMARY KEY (benchmark_id, algorithm_id)
+);
+
+CREATE TABLE benchmark_measurements (
+    benchmark_id INTEGER NOT NULL,
+    algorithm_id INTEGER NOT NULL,
+    size INTEGER NOT NULL,
+    status VARCHAR(30) NOT NULL,
+    wall_time_ms DOUBLE PRECISION NOT NULL,
+    cpu_time_ms DOUBLE PRECISION NOT NULL,
+    memory_kb INTEGER NOT NULL,
+    PRIMARY KEY (benchmark_id, algorithm_id, size),
+    FOREIGN KEY (benchmark_id, algorithm_id) REFERENCES benchmark_algorithms (benchmark_id, algorithm_id) ON DELETE CASCADE
+);
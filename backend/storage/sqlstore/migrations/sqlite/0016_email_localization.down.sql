@@ -0,0 +1,2 @@
+ALTER TABLE email_outbox DROP COLUMN html_body;
+ALTER TABLE users DROP COLUMN locale;
@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN locale VARCHAR(10) NOT NULL DEFAULT 'en';
+ALTER TABLE email_outbox ADD COLUMN html_body TEXT NOT NULL DEFAULT '';
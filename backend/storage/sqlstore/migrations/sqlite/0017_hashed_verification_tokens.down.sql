@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS email_verification_tokens;
+
+CREATE TABLE email_verification_tokens (
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    token VARCHAR(32) PRIMARY KEY,
+    created_at TIMESTAMP,
+    email VARCHAR(255) NOT NULL,
+    username VARCHAR(255) NOT NULL
+);
@@ -0,0 +1,11 @@
+CREATE TABLE login_throttles (
+    scope VARCHAR(10) NOT NULL CHECK (scope IN ('account', 'ip')),
+    subject VARCHAR(255) NOT NULL,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP,
+    unlock_token_hash VARCHAR(64),
+    PRIMARY KEY (scope, subject)
+);
+
+CREATE UNIQUE INDEX login_throttles_unlock_token_hash_idx ON login_throttles(unlock_token_hash);
@@ -0,0 +1,3 @@
+-- Тела отправленных писем больше не хранятся: в них одноразовые ссылки подтверждения,
+-- сброса пароля и разблокировки. Стираем их и у писем, отправленных до этой версии.
+UPDATE email_outbox SET body = '', html_body = '' WHERE status = 'sent';
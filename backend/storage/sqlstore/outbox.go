@@ -0,0 +1,112 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+const outboxColumns = "id, kind, recipient, subject, body, html_body, status, attempts, last_error, next_attempt_at, created_at, sent_at"
+
+func scanOutboxEmail(row interface{ Scan(...interface{}) error }) (storage.OutboxEmail, error) {
+	var email storage.OutboxEmail
+	var sentAt sql.NullTime
+	err := row.Scan(&email.ID, &email.Kind, &email.Recipient, &email.Subject, &email.Body, &email.HTMLBody, &email.Status, &email.Attempts,
+		&email.LastError, &email.NextAttemptAt, &email.CreatedAt, &sentAt)
+	email.SentAt = timePtr(sentAt)
+	return email, err
+}
+
+// enqueueEmail ставит письмо в очередь в транзакции вызывающего
+func (s *Store) enqueueEmail(ctx context.Context, q querier, email *storage.OutboxEmail) error {
+	email.Status = storage.EmailPending
+	email.Attempts = 0
+	email.CreatedAt = now()
+	email.NextAttemptAt = email.CreatedAt
+	return s.queryRow(ctx, q, "INSERT INTO email_outbox(kind, recipient, subject, body, html_body, status, next_attempt_at, created_at) "+
+		"VALUES($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id", email.Kind, email.Recipient, email.Subject, email.Body, email.HTMLBody,
+		email.Status, email.NextAttemptAt, email.CreatedAt).Scan(&email.ID)
+}
+
+func (s *Store) EnqueueEmail(ctx context.Context, email *storage.OutboxEmail) error {
+	return s.enqueueEmail(ctx, s.db, email)
+}
+
+func (s *Store) ClaimEmails(ctx context.Context, now, leaseUntil time.Time, limit int) ([]storage.OutboxEmail, error) {
+	// Условие повторяется во внешнем UPDATE: если два сервера выбрали одно письмо, второй после
+	// ожидания блокировки перепроверит строку и пропустит уже взятое первым
+	rows, err := s.query(ctx, s.db, "UPDATE email_outbox SET attempts = attempts + 1, next_attempt_at = $1 "+
+		"WHERE status = $2 AND next_attempt_at <= $3 AND id IN (SELECT id FROM email_outbox WHERE status = $2 AND next_attempt_at <= $3 "+
+		"ORDER BY next_attempt_at, id LIMIT $4) RETURNING "+outboxColumns, leaseUntil, storage.EmailPending, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var emails []storage.OutboxEmail
+	for rows.Next() {
+		email, err := scanOutboxEmail(rows)
+		if err != nil {
+			return nil, err
+		}
+		emails = append(emails, email)
+	}
+	return emails, rows.Err()
+}
+
+func (s *Store) MarkEmailSent(ctx context.Context, id int, at time.Time) error {
+	return s.execOne(ctx, s.db, "UPDATE email_outbox SET status = $1, sent_at = $2, last_error = '', body = '', html_body = '' "+
+		"WHERE id = $3", storage.EmailSent, at, id)
+}
+
+func (s *Store) MarkEmailFailed(ctx context.Context, id int, errorMessage string, retryAt *time.Time) error {
+	if retryAt == nil {
+		return s.execOne(ctx, s.db, "UPDATE email_outbox SET status = $1, last_error = $2 WHERE id = $3", storage.EmailDead, errorMessage, id)
+	}
+	return s.execOne(ctx, s.db, "UPDATE email_outbox SET last_error = $1, next_attempt_at = $2 WHERE id = $3", errorMessage, *retryAt, id)
+}
+
+func (s *Store) GetEmail(ctx context.Context, id int) (storage.OutboxEmail, error) {
+	email, err := scanOutboxEmail(s.queryRow(ctx, s.db, "SELECT "+outboxColumns+" FROM email_outbox WHERE id = $1", id))
+	return email, mapErr(err)
+}
+
+func (s *Store) ListEmails(ctx context.Context, status string, limit int) ([]storage.OutboxEmail, error) {
+	query, args := "SELECT "+outboxColumns+" FROM email_outbox", []interface{}{}
+	if status != "" {
+		query, args = query+" WHERE status = $1", append(args, status)
+	}
+	args = append(args, limit)
+	rows, err := s.query(ctx, s.db, query+" ORDER BY id DESC LIMIT $"+strconv.Itoa(len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	emails := []storage.OutboxEmail{}
+	for rows.Next() {
+		email, err := scanOutboxEmail(rows)
+		if err != nil {
+			return nil, err
+		}
+		emails = append(emails, email)
+	}
+	return emails, rows.Err()
+}
+
+func (s *Store) ResendEmail(ctx context.Context, id int, at time.Time) (storage.OutboxEmail, error) {
+	email, err := scanOutboxEmail(s.queryRow(ctx, s.db, "UPDATE email_outbox SET status = $1, attempts = 0, next_attempt_at = $2, "+
+		"sent_at = NULL WHERE id = $3 AND status = $4 RETURNING "+outboxColumns, storage.EmailPending, at, id, storage.EmailDead))
+	return email, mapErr(err)
+}
+
+func (s *Store) PurgeSentEmails(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.exec(ctx, s.db, "DELETE FROM email_outbox WHERE status = $1 AND sent_at < $2", storage.EmailSent, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,79 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// updateRatingSummary пересчитывает агрегаты оценок алгоритма. Строка алгоритма уже заблокирована,
+// поэтому параллельные оценки не перезапишут агрегаты друг друга.
+func (s *Store) updateRatingSummary(ctx context.Context, tx *sql.Tx, algorithmID int) (storage.RatingSummary, error) {
+	summary := storage.RatingSummary{AlgorithmID: algorithmID}
+	var sum int
+	err := s.queryRow(ctx, tx, "SELECT COUNT(*), COALESCE(SUM(score), 0) FROM algorithm_ratings WHERE algorithm_id = $1", algorithmID).
+		Scan(&summary.Count, &sum)
+	if err != nil {
+		return summary, err
+	}
+	if summary.Count > 0 {
+		summary.Average = float64(sum) / float64(summary.Count)
+	}
+	summary.Rating = storage.RatingScore(sum, summary.Count)
+
+	_, err = s.exec(ctx, tx, "UPDATE algorithms SET rating = $1, rating_average = $2, rating_count = $3 WHERE id = $4",
+		summary.Rating, summary.Average, summary.Count, algorithmID)
+	return summary, err
+}
+
+// lockRatedAlgorithm блокирует строку алгоритма на время изменения оценок
+func (s *Store) lockRatedAlgorithm(ctx context.Context, tx *sql.Tx, algorithmID int) error {
+	var id int
+	err := s.queryRow(ctx, tx, "SELECT id FROM algorithms WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", algorithmID).Scan(&id)
+	return mapErr(err)
+}
+
+func (s *Store) RateAlgorithm(ctx context.Context, algorithmID, userID, score int, at time.Time) (storage.RatingSummary, error) {
+	var summary storage.RatingSummary
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := s.lockRatedAlgorithm(ctx, tx, algorithmID); err != nil {
+			return err
+		}
+
+		_, err := s.exec(ctx, tx, "INSERT INTO algorithm_ratings(algorithm_id, user_id, score, created_at, updated_at) VALUES($1, $2, $3, $4, $4) "+
+			"ON CONFLICT (algorithm_id, user_id) DO UPDATE SET score = excluded.score, updated_at = excluded.updated_at",
+			algorithmID, userID, score, at)
+		if err != nil {
+			return err
+		}
+
+		summary, err = s.updateRatingSummary(ctx, tx, algorithmID)
+		return err
+	})
+	return summary, err
+}
+
+func (s *Store) DeleteRating(ctx context.Context, algorithmID, userID int) (storage.RatingSummary, error) {
+	var summary storage.RatingSummary
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := s.lockRatedAlgorithm(ctx, tx, algorithmID); err != nil {
+			return err
+		}
+		if err := s.execOne(ctx, tx, "DELETE FROM algorithm_ratings WHERE algorithm_id = $1 AND user_id = $2", algorithmID, userID); err != nil {
+			return err
+		}
+
+		var err error
+		summary, err = s.updateRatingSummary(ctx, tx, algorithmID)
+		return err
+	})
+	return summary, err
+}
+
+func (s *Store) GetRating(ctx context.Context, algorithmID, userID int) (int, error) {
+	var score int
+	err := s.queryRow(ctx, s.db, "SELECT score FROM algorithm_ratings WHERE algorithm_id = $1 AND user_id = $2", algorithmID, userID).Scan(&score)
+	return score, mapErr(err)
+}
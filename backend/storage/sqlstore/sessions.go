@@ -0,0 +1,123 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+const sessionColumns = "id, user_id, COALESCE(user_agent, ''), COALESCE(ip, ''), created_at, last_used_at, expires_at, revoked_at"
+
+func scanSession(row interface{ Scan(...interface{}) error }) (storage.Session, error) {
+	var session storage.Session
+	var revokedAt sql.NullTime
+	err := row.Scan(&session.ID, &session.UserID, &session.UserAgent, &session.IP, &session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt, &revokedAt)
+	session.RevokedAt = timePtr(revokedAt)
+	return session, err
+}
+
+func (s *Store) CreateSession(ctx context.Context, session *storage.Session, refreshTokenHash string) error {
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = now()
+	}
+	session.LastUsedAt = session.CreatedAt
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		err := s.queryRow(ctx, tx, "INSERT INTO sessions(user_id, user_agent, ip, created_at, last_used_at, expires_at) VALUES($1, $2, $3, $4, $5, $6) RETURNING id",
+			session.UserID, session.UserAgent, session.IP, session.CreatedAt, session.LastUsedAt, session.ExpiresAt).Scan(&session.ID)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.exec(ctx, tx, "INSERT INTO refresh_tokens(token_hash, session_id, created_at, expires_at) VALUES($1, $2, $3, $4)",
+			refreshTokenHash, session.ID, session.CreatedAt, session.ExpiresAt)
+		return err
+	})
+}
+
+func (s *Store) GetSession(ctx context.Context, id, userID int) (storage.Session, error) {
+	session, err := scanSession(s.queryRow(ctx, s.db, "SELECT "+sessionColumns+" FROM sessions WHERE id = $1 AND user_id = $2", id, userID))
+	return session, mapErr(err)
+}
+
+func (s *Store) TouchSession(ctx context.Context, id int, now, staleBefore time.Time) error {
+	_, err := s.exec(ctx, s.db, "UPDATE sessions SET last_used_at = $1 WHERE id = $2 AND last_used_at < $3", now, id, staleBefore)
+	return err
+}
+
+func (s *Store) RotateRefreshToken(ctx context.Context, oldHash, newHash string, expiresAt time.Time, ip, userAgent string) (storage.Session, storage.User, error) {
+	var session storage.Session
+	var user storage.User
+	var reused bool
+
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var usedAt, revokedAt sql.NullTime
+		var tokenExpiresAt time.Time
+		err := s.queryRow(ctx, tx, "SELECT t.session_id, t.used_at, t.expires_at, s.revoked_at, u.id, u.username, COALESCE(u.role, 'user') "+
+			"FROM refresh_tokens t JOIN sessions s ON s.id = t.session_id JOIN users u ON u.id = s.user_id "+
+			"WHERE t.token_hash = $1 FOR UPDATE OF t, s", oldHash).
+			Scan(&session.ID, &usedAt, &tokenExpiresAt, &revokedAt, &user.ID, &user.Username, &user.Role)
+		if err != nil {
+			return mapErr(err)
+		}
+
+		current := now()
+
+		// Отзыв сессии при повторном предъявлении токена нужно сохранить, поэтому транзакция фиксируется
+		if usedAt.Valid {
+			reused = true
+			_, err = s.exec(ctx, tx, "UPDATE sessions SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL", current, session.ID)
+			return err
+		}
+		if revokedAt.Valid || !tokenExpiresAt.After(current) {
+			return storage.ErrTokenExpired
+		}
+
+		if _, err := s.exec(ctx, tx, "UPDATE refresh_tokens SET used_at = $1 WHERE token_hash = $2", current, oldHash); err != nil {
+			return err
+		}
+		_, err = s.exec(ctx, tx, "INSERT INTO refresh_tokens(token_hash, session_id, created_at, expires_at) VALUES($1, $2, $3, $4)",
+			newHash, session.ID, current, expiresAt)
+		if err != nil {
+			return err
+		}
+
+		session, err = scanSession(s.queryRow(ctx, tx, "UPDATE sessions SET expires_at = $1, last_used_at = $2, ip = $3, user_agent = $4 WHERE id = $5 RETURNING "+sessionColumns,
+			expiresAt, current, ip, userAgent, session.ID))
+		return err
+	})
+	if err == nil && reused {
+		err = storage.ErrTokenReused
+	}
+	return session, user, err
+}
+
+func (s *Store) ListActiveSessions(ctx context.Context, userID int, now time.Time) ([]storage.Session, error) {
+	rows, err := s.query(ctx, s.db, "SELECT "+sessionColumns+" FROM sessions "+
+		"WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2 ORDER BY last_used_at DESC", userID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []storage.Session = []storage.Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+func (s *Store) RevokeSession(ctx context.Context, id, userID int, at time.Time) error {
+	return s.execOne(ctx, s.db, "UPDATE sessions SET revoked_at = $1 WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL", at, id, userID)
+}
+
+func (s *Store) RevokeUserSessions(ctx context.Context, userID int, at time.Time) error {
+	_, err := s.exec(ctx, s.db, "UPDATE sessions SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL", at, userID)
+	return err
+}
@@ -0,0 +1,80 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"AlgorithmsOnlineLibrary/storage"
+	"AlgorithmsOnlineLibrary/storage/storagetest"
+)
+
+// openSQLite открывает новую базу SQLite во временном каталоге без миграций
+func openSQLite(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(SQLite, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// openPostgres открывает пустую схему в базе из TEST_DATABASE_URL (URL вида postgres://...)
+// и удаляет её после теста. Без переменной тест пропускается: PostgreSQL есть не везде.
+func openPostgres(t *testing.T) *Store {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	admin, err := Open(Postgres, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Close()
+	schema := fmt.Sprintf("storage_test_%d", rand.Int63())
+	if _, err := admin.db.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if admin, err := Open(Postgres, dsn); err == nil {
+			admin.db.Exec("DROP SCHEMA " + schema + " CASCADE")
+			admin.Close()
+		}
+	})
+
+	// Расширения вроде pg_trgm остаются в public, поэтому она тоже в пути поиска
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	s, err := Open(Postgres, dsn+separator+"search_path="+schema+",public")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func openMigrated(t *testing.T, open func(t *testing.T) *Store) storage.Store {
+	t.Helper()
+	s := open(t)
+	if _, err := s.MigrateUp(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSQLiteStore(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Store { return openMigrated(t, openSQLite) })
+}
+
+func TestPostgresStore(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Store { return openMigrated(t, openPostgres) })
+}
@@ -0,0 +1,168 @@
+// Package sqlstore реализует storage.Store поверх database/sql для PostgreSQL и SQLite.
+// Запросы пишутся на диалекте PostgreSQL и переводятся для SQLite в rebind.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"           // драйвер PostgreSQL
+	_ "github.com/mattn/go-sqlite3" // драйвер SQLite
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+const (
+	Postgres = "postgres"
+	SQLite   = "sqlite"
+)
+
+type Store struct {
+	db      *sql.DB
+	dialect string
+}
+
+var _ storage.Store = (*Store)(nil)
+
+// Open подключается к базе. Схему создают и обновляют миграции, см. MigrateUp.
+func Open(dialect, dsn string) (*Store, error) {
+	driverName := dialect
+	if dialect == SQLite {
+		driverName = "sqlite3"
+	} else if dialect != Postgres {
+		return nil, fmt.Errorf("unsupported SQL dialect %q", dialect)
+	}
+
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	s := &Store{db: db, dialect: dialect}
+
+	if dialect == SQLite {
+		// SQLite не умеет конкурентную запись, а для ":memory:" каждое соединение — отдельная база.
+		// С одним соединением транзакции сериализуются так же, как FOR UPDATE в PostgreSQL.
+		db.SetMaxOpenConns(1)
+		if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+var (
+	placeholderRe = regexp.MustCompile(`\$(\d+)`)
+	forUpdateRe   = regexp.MustCompile(`\s+FOR UPDATE.*$`)
+)
+
+// rebind переводит запрос с диалекта PostgreSQL на диалект хранилища
+func (s *Store) rebind(query string) string {
+	if s.dialect != SQLite {
+		return query
+	}
+	query = placeholderRe.ReplaceAllString(query, "?$1")
+	query = forUpdateRe.ReplaceAllString(query, "")
+	// LIKE в SQLite и так не учитывает регистр
+	return strings.ReplaceAll(query, " ILIKE ", " LIKE ")
+}
+
+// SQLite хранит время строкой и сравнивает его как строку, поэтому все метки времени пишутся в UTC
+func normalize(args []interface{}) []interface{} {
+	for i, arg := range args {
+		if t, ok := arg.(time.Time); ok {
+			args[i] = t.UTC()
+		}
+	}
+	return args
+}
+
+func (s *Store) exec(ctx context.Context, q querier, query string, args ...interface{}) (sql.Result, error) {
+	return q.ExecContext(ctx, s.rebind(query), normalize(args)...)
+}
+
+func (s *Store) query(ctx context.Context, q querier, query string, args ...interface{}) (*sql.Rows, error) {
+	return q.QueryContext(ctx, s.rebind(query), normalize(args)...)
+}
+
+func (s *Store) queryRow(ctx context.Context, q querier, query string, args ...interface{}) *sql.Row {
+	return q.QueryRowContext(ctx, s.rebind(query), normalize(args)...)
+}
+
+// execOne выполняет изменение одной строки и возвращает storage.ErrNotFound, если строка не нашлась
+func (s *Store) execOne(ctx context.Context, q querier, query string, args ...interface{}) error {
+	result, err := s.exec(ctx, q, query, args...)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// inTx выполняет fn в транзакции и откатывает её, если fn вернула ошибку
+func (s *Store) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// mapErr переводит sql.ErrNoRows в storage.ErrNotFound
+func mapErr(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.ErrNotFound
+	}
+	return err
+}
+
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func intPtr(n sql.NullInt64) *int {
+	if !n.Valid {
+		return nil
+	}
+	value := int(n.Int64)
+	return &value
+}
@@ -0,0 +1,212 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// placeholders возвращает список «$first, $first+1, ...» из n параметров для IN (...)
+func placeholders(first, n int) string {
+	list := make([]string, n)
+	for i := range list {
+		list[i] = "$" + strconv.Itoa(first+i)
+	}
+	return strings.Join(list, ", ")
+}
+
+func stringArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	return args
+}
+
+// canonicalTag возвращает id основного тега для name или создаёт его
+func (s *Store) canonicalTag(ctx context.Context, tx *sql.Tx, name string) (int, error) {
+	var id int
+	err := s.queryRow(ctx, tx, "SELECT COALESCE(canonical_id, id) FROM tags WHERE name = $1", name).Scan(&id)
+	if err == sql.ErrNoRows {
+		err = s.queryRow(ctx, tx, "INSERT INTO tags(name, created_at) VALUES($1, $2) RETURNING id", name, now()).Scan(&id)
+	}
+	return id, err
+}
+
+// saveTags заменяет теги алгоритма и записывает в algorithm.Tags их основные имена
+func (s *Store) saveTags(ctx context.Context, tx *sql.Tx, algorithm *storage.Algorithm) error {
+	if _, err := s.exec(ctx, tx, "DELETE FROM algorithm_tags WHERE algorithm_id = $1", algorithm.ID); err != nil {
+		return err
+	}
+
+	linked := map[int]bool{}
+	for _, name := range algorithm.Tags {
+		id, err := s.canonicalTag(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+		if linked[id] {
+			continue
+		}
+		linked[id] = true
+		if _, err := s.exec(ctx, tx, "INSERT INTO algorithm_tags(algorithm_id, tag_id) VALUES($1, $2)", algorithm.ID, id); err != nil {
+			return err
+		}
+	}
+
+	loaded := []storage.Algorithm{*algorithm}
+	err := s.loadTags(ctx, tx, loaded)
+	algorithm.Tags = loaded[0].Tags
+	return err
+}
+
+// loadTags заполняет Tags у алгоритмов одним запросом
+func (s *Store) loadTags(ctx context.Context, db querier, algorithms []storage.Algorithm) error {
+	if len(algorithms) == 0 {
+		return nil
+	}
+
+	ids := make([]interface{}, len(algorithms))
+	index := map[int]int{}
+	for i := range algorithms {
+		algorithms[i].Tags = []string{}
+		ids[i] = algorithms[i].ID
+		index[algorithms[i].ID] = i
+	}
+
+	rows, err := s.query(ctx, db, "SELECT link.algorithm_id, t.name FROM algorithm_tags link JOIN tags t ON t.id = link.tag_id "+
+		"WHERE link.algorithm_id IN ("+placeholders(1, len(ids))+") ORDER BY t.name", ids...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var algorithmID int
+		var name string
+		if err := rows.Scan(&algorithmID, &name); err != nil {
+			return err
+		}
+		i := index[algorithmID]
+		algorithms[i].Tags = append(algorithms[i].Tags, name)
+	}
+	return rows.Err()
+}
+
+func (s *Store) ResolveTags(ctx context.Context, names []string) ([]string, error) {
+	resolved := []string{}
+	if len(names) == 0 {
+		return resolved, nil
+	}
+
+	rows, err := s.query(ctx, s.db, "SELECT t.name, COALESCE(c.name, t.name) FROM tags t LEFT JOIN tags c ON c.id = t.canonical_id "+
+		"WHERE t.name IN ("+placeholders(1, len(names))+")", stringArgs(names)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	canonical := map[string]string{}
+	for rows.Next() {
+		var name, target string
+		if err := rows.Scan(&name, &target); err != nil {
+			return nil, err
+		}
+		canonical[name] = target
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	for _, name := range names {
+		if target, ok := canonical[name]; ok {
+			name = target
+		}
+		if !seen[name] {
+			seen[name] = true
+			resolved = append(resolved, name)
+		}
+	}
+	return resolved, nil
+}
+
+func (s *Store) TagCounts(ctx context.Context, prefix string, limit int) ([]storage.TagCount, error) {
+	query := "SELECT t.name, COUNT(a.id) FROM tags t " +
+		"JOIN algorithm_tags link ON link.tag_id = t.id " +
+		"JOIN algorithms a ON a.id = link.algorithm_id AND a.deleted_at IS NULL AND a.approved = true " +
+		"WHERE t.canonical_id IS NULL"
+	var args []interface{}
+	if prefix != "" {
+		// Имена тегов не содержат % и _, экранировать префикс для LIKE не нужно
+		args = append(args, prefix+"%")
+		query += " AND (t.name LIKE $1 OR EXISTS (SELECT 1 FROM tags synonym WHERE synonym.canonical_id = t.id AND synonym.name LIKE $1))"
+	}
+	query += " GROUP BY t.id, t.name ORDER BY COUNT(a.id) DESC, t.name"
+	if limit > 0 {
+		query += " LIMIT " + strconv.Itoa(limit)
+	}
+
+	rows, err := s.query(ctx, s.db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []storage.TagCount = []storage.TagCount{}
+	for rows.Next() {
+		var count storage.TagCount
+		if err := rows.Scan(&count.Name, &count.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+	return counts, rows.Err()
+}
+
+func (s *Store) AddTagSynonym(ctx context.Context, tag, synonym string) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		target, err := s.canonicalTag(ctx, tx, tag)
+		if err != nil {
+			return err
+		}
+
+		var id int
+		var canonicalID sql.NullInt64
+		err = s.queryRow(ctx, tx, "SELECT id, canonical_id FROM tags WHERE name = $1", synonym).Scan(&id, &canonicalID)
+		if err == sql.ErrNoRows {
+			_, err = s.exec(ctx, tx, "INSERT INTO tags(name, canonical_id, created_at) VALUES($1, $2, $3)", synonym, target, now())
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if id == target {
+			return storage.ErrDuplicate
+		}
+
+		// Основной тег сливается с target: алгоритмы и синонимы переходят к нему
+		if !canonicalID.Valid {
+			_, err = s.exec(ctx, tx, "INSERT INTO algorithm_tags(algorithm_id, tag_id) SELECT algorithm_id, $1 FROM algorithm_tags "+
+				"WHERE tag_id = $2 AND algorithm_id NOT IN (SELECT algorithm_id FROM algorithm_tags WHERE tag_id = $1)", target, id)
+			if err != nil {
+				return err
+			}
+			if _, err := s.exec(ctx, tx, "DELETE FROM algorithm_tags WHERE tag_id = $1", id); err != nil {
+				return err
+			}
+			if _, err := s.exec(ctx, tx, "UPDATE tags SET canonical_id = $1 WHERE canonical_id = $2", target, id); err != nil {
+				return err
+			}
+		}
+		_, err = s.exec(ctx, tx, "UPDATE tags SET canonical_id = $1 WHERE id = $2", target, id)
+		return err
+	})
+}
+
+func (s *Store) RemoveTagSynonym(ctx context.Context, synonym string) error {
+	return s.execOne(ctx, s.db, "DELETE FROM tags WHERE name = $1 AND canonical_id IS NOT NULL", synonym)
+}
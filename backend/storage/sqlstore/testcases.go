@@ -0,0 +1,79 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+const testCaseColumns = "id, algorithm_id, input, expected_output, hidden, comparison, tolerance, created_at"
+
+func (s *Store) ListTestCases(ctx context.Context, algorithmID int, includeHidden bool) ([]storage.TestCase, error) {
+	query := "SELECT " + testCaseColumns + " FROM algorithm_test_cases WHERE algorithm_id = $1"
+	if !includeHidden {
+		query += " AND NOT hidden"
+	}
+	rows, err := s.query(ctx, s.db, query+" ORDER BY id", algorithmID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var testCases []storage.TestCase = []storage.TestCase{}
+	for rows.Next() {
+		var testCase storage.TestCase
+		err := rows.Scan(&testCase.ID, &testCase.AlgorithmID, &testCase.Input, &testCase.ExpectedOutput, &testCase.Hidden,
+			&testCase.Comparison, &testCase.Tolerance, &testCase.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		testCases = append(testCases, testCase)
+	}
+	return testCases, rows.Err()
+}
+
+func (s *Store) CreateTestCase(ctx context.Context, testCase *storage.TestCase) error {
+	testCase.CreatedAt = now()
+	return s.queryRow(ctx, s.db, "INSERT INTO algorithm_test_cases(algorithm_id, input, expected_output, hidden, comparison, tolerance, created_at) "+
+		"VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING id", testCase.AlgorithmID, testCase.Input, testCase.ExpectedOutput, testCase.Hidden,
+		testCase.Comparison, testCase.Tolerance, testCase.CreatedAt).Scan(&testCase.ID)
+}
+
+func (s *Store) UpdateTestCase(ctx context.Context, testCase *storage.TestCase) error {
+	err := s.queryRow(ctx, s.db, "UPDATE algorithm_test_cases SET input = $1, expected_output = $2, hidden = $3, comparison = $4, tolerance = $5 "+
+		"WHERE id = $6 AND algorithm_id = $7 RETURNING created_at", testCase.Input, testCase.ExpectedOutput, testCase.Hidden, testCase.Comparison,
+		testCase.Tolerance, testCase.ID, testCase.AlgorithmID).Scan(&testCase.CreatedAt)
+	return mapErr(err)
+}
+
+func (s *Store) DeleteTestCase(ctx context.Context, algorithmID, id int) error {
+	return s.execOne(ctx, s.db, "DELETE FROM algorithm_test_cases WHERE id = $1 AND algorithm_id = $2", id, algorithmID)
+}
+
+func (s *Store) SaveVerification(ctx context.Context, verification storage.Verification) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		// Статус ревизии, которая уже не последняя, в algorithms не попадает
+		var status interface{}
+		if verification.Total == 0 {
+			_, err := s.exec(ctx, tx, "DELETE FROM algorithm_verifications WHERE algorithm_id = $1 AND revision = $2",
+				verification.AlgorithmID, verification.Revision)
+			if err != nil {
+				return err
+			}
+		} else {
+			_, err := s.exec(ctx, tx, "INSERT INTO algorithm_verifications(algorithm_id, revision, status, passed, total, verified_at) "+
+				"VALUES($1, $2, $3, $4, $5, $6) ON CONFLICT (algorithm_id, revision) DO UPDATE SET "+
+				"status = excluded.status, passed = excluded.passed, total = excluded.total, verified_at = excluded.verified_at",
+				verification.AlgorithmID, verification.Revision, verification.Status, verification.Passed, verification.Total, verification.VerifiedAt)
+			if err != nil {
+				return err
+			}
+			status = verification.Status
+		}
+		_, err := s.exec(ctx, tx, "UPDATE algorithms SET verification_status = $1 WHERE id = $2 AND "+
+			"(SELECT COALESCE(MAX(revision), 0) FROM algorithm_revisions WHERE algorithm_id = $2) = $3",
+			status, verification.AlgorithmID, verification.Revision)
+		return err
+	})
+}
@@ -0,0 +1,60 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+func (s *Store) SaveVerificationToken(ctx context.Context, user storage.User, tokenHash string, expiresAt time.Time, message *storage.OutboxEmail) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := s.saveVerificationToken(ctx, tx, user, tokenHash, expiresAt); err != nil {
+			return err
+		}
+		return s.enqueueEmail(ctx, tx, message)
+	})
+}
+
+func (s *Store) saveVerificationToken(ctx context.Context, tx *sql.Tx, user storage.User, tokenHash string, expiresAt time.Time) error {
+	if _, err := s.exec(ctx, tx, "DELETE FROM email_verification_tokens WHERE user_id = $1 AND used_at IS NULL", user.ID); err != nil {
+		return err
+	}
+	_, err := s.exec(ctx, tx, "INSERT INTO email_verification_tokens(token_hash, user_id, email, username, created_at, expires_at) VALUES($1, $2, $3, $4, $5, $6)",
+		tokenHash, user.ID, user.Email, user.Username, now(), expiresAt)
+	return err
+}
+
+func (s *Store) LastVerificationTokenAt(ctx context.Context, userID int) (time.Time, error) {
+	var createdAt time.Time
+	err := s.queryRow(ctx, s.db, "SELECT created_at FROM email_verification_tokens WHERE user_id = $1 ORDER BY created_at DESC LIMIT 1", userID).
+		Scan(&createdAt)
+	return createdAt, mapErr(err)
+}
+
+func (s *Store) SaveResetToken(ctx context.Context, user storage.User, token string, createdAt time.Time, message *storage.OutboxEmail) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := s.exec(ctx, tx, "DELETE FROM password_reset_tokens WHERE user_id = $1 AND email = $2", user.ID, user.Email); err != nil {
+			return err
+		}
+		_, err := s.exec(ctx, tx, "INSERT INTO password_reset_tokens(user_id, token, email, username, created_at) VALUES($1, $2, $3, $4, $5)",
+			user.ID, token, user.Email, user.Username, createdAt)
+		if err != nil {
+			return err
+		}
+		return s.enqueueEmail(ctx, tx, message)
+	})
+}
+
+func (s *Store) FindResetToken(ctx context.Context, token, username, email string, notBefore time.Time) (int, error) {
+	var userID int
+	err := s.queryRow(ctx, s.db, "SELECT user_id FROM password_reset_tokens WHERE token = $1 AND username = $2 AND email = $3 AND created_at > $4",
+		token, username, email, notBefore).Scan(&userID)
+	return userID, mapErr(err)
+}
+
+func (s *Store) DeleteResetToken(ctx context.Context, token string) error {
+	_, err := s.exec(ctx, s.db, "DELETE FROM password_reset_tokens WHERE token = $1", token)
+	return err
+}
@@ -0,0 +1,133 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+const userColumns = "id, username, email, COALESCE(role, 'user'), locale, password_hash, COALESCE(confirmed, false), password_changed_at"
+
+func scanUser(row interface{ Scan(...interface{}) error }) (storage.UserRecord, error) {
+	var user storage.UserRecord
+	var passwordChangedAt sql.NullTime
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Role, &user.Locale, &user.PasswordHash, &user.Confirmed, &passwordChangedAt)
+	user.PasswordChangedAt = timePtr(passwordChangedAt)
+	return user, err
+}
+
+func (s *Store) UsernameExists(ctx context.Context, username string) (bool, error) {
+	var exists bool
+	err := s.queryRow(ctx, s.db, "SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)", username).Scan(&exists)
+	return exists, err
+}
+
+func (s *Store) ConfirmedEmailExists(ctx context.Context, email string) (bool, error) {
+	var exists bool
+	err := s.queryRow(ctx, s.db, "SELECT EXISTS(SELECT 1 FROM users WHERE email = $1 AND confirmed = true)", email).Scan(&exists)
+	return exists, err
+}
+
+func (s *Store) CreateUser(ctx context.Context, user *storage.User, passwordHash string) error {
+	return s.createUser(ctx, s.db, user, passwordHash)
+}
+
+func (s *Store) createUser(ctx context.Context, q querier, user *storage.User, passwordHash string) error {
+	if user.Role == "" {
+		user.Role = storage.DefaultRole
+	}
+	if user.Locale == "" {
+		user.Locale = storage.DefaultLocale
+	}
+	return s.queryRow(ctx, q, "INSERT INTO users(username, password_hash, email, role, locale) VALUES($1, $2, $3, $4, $5) RETURNING id",
+		user.Username, passwordHash, user.Email, user.Role, user.Locale).Scan(&user.ID)
+}
+
+func (s *Store) RegisterUser(ctx context.Context, user *storage.User, passwordHash, verificationTokenHash string, tokenExpiresAt time.Time, message *storage.OutboxEmail) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := s.createUser(ctx, tx, user, passwordHash); err != nil {
+			return err
+		}
+		if err := s.saveVerificationToken(ctx, tx, *user, verificationTokenHash, tokenExpiresAt); err != nil {
+			return err
+		}
+		return s.enqueueEmail(ctx, tx, message)
+	})
+}
+
+func (s *Store) GetUser(ctx context.Context, id int) (storage.UserRecord, error) {
+	user, err := scanUser(s.queryRow(ctx, s.db, "SELECT "+userColumns+" FROM users WHERE id = $1", id))
+	return user, mapErr(err)
+}
+
+func (s *Store) GetUserByUsername(ctx context.Context, username string) (storage.UserRecord, error) {
+	user, err := scanUser(s.queryRow(ctx, s.db, "SELECT "+userColumns+" FROM users WHERE username = $1", username))
+	return user, mapErr(err)
+}
+
+func (s *Store) ListUsers(ctx context.Context) ([]storage.UserRecord, error) {
+	rows, err := s.query(ctx, s.db, "SELECT "+userColumns+" FROM users ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []storage.UserRecord = []storage.UserRecord{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+func (s *Store) ConfirmUser(ctx context.Context, tokenHash string, at time.Time) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		var userID int
+		var email string
+		var expiresAt time.Time
+		var usedAt sql.NullTime
+		err := s.queryRow(ctx, tx, "SELECT user_id, email, expires_at, used_at FROM email_verification_tokens WHERE token_hash = $1 FOR UPDATE", tokenHash).
+			Scan(&userID, &email, &expiresAt, &usedAt)
+		if err != nil {
+			return mapErr(err)
+		}
+		if usedAt.Valid {
+			return storage.ErrTokenReused
+		}
+		if !expiresAt.After(at) {
+			return storage.ErrTokenExpired
+		}
+
+		if _, err := s.exec(ctx, tx, "UPDATE email_verification_tokens SET used_at = $1 WHERE token_hash = $2", at, tokenHash); err != nil {
+			return err
+		}
+		if err := s.execOne(ctx, tx, "UPDATE users SET confirmed = true WHERE id = $1", userID); err != nil {
+			return err
+		}
+		if _, err := s.exec(ctx, tx, "DELETE FROM email_verification_tokens WHERE email = $1 AND used_at IS NULL", email); err != nil {
+			return err
+		}
+		_, err = s.exec(ctx, tx, "DELETE FROM users WHERE email = $1 AND confirmed = false", email)
+		return err
+	})
+}
+
+func (s *Store) UpdatePassword(ctx context.Context, userID int, passwordHash string, changedAt time.Time) error {
+	return s.execOne(ctx, s.db, "UPDATE users SET password_hash = $1, password_changed_at = $2 WHERE id = $3", passwordHash, changedAt, userID)
+}
+
+func (s *Store) SetRole(ctx context.Context, userID int, role string) (storage.User, error) {
+	var user storage.User
+	err := s.queryRow(ctx, s.db, "UPDATE users SET role = $1 WHERE id = $2 RETURNING id, username, email, role, locale", role, userID).
+		Scan(&user.ID, &user.Username, &user.Email, &user.Role, &user.Locale)
+	return user, mapErr(err)
+}
+
+func (s *Store) SetLocale(ctx context.Context, userID int, locale string) error {
+	return s.execOne(ctx, s.db, "UPDATE users SET locale = $1 WHERE id = $2", locale, userID)
+}
@@ -0,0 +1,262 @@
+// Package storage описывает доступ к данным библиотеки алгоритмов. Обработчики HTTP работают
+// только с интерфейсами отсюда, а конкретное хранилище (PostgreSQL, SQLite или память)
+// выбирается в конфигурации.
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotFound — запись не найдена или не видна вызывающему
+	ErrNotFound = errors.New("not found")
+	// ErrTokenReused — одноразовый токен уже был использован. Для refresh-токена это значит,
+	// что сессия к этому моменту отозвана.
+	ErrTokenReused = errors.New("token has already been used")
+	// ErrTokenExpired — токен просрочен; refresh-токен — также если его сессия отозвана
+	ErrTokenExpired = errors.New("token expired or revoked")
+	// ErrInUse — запись нельзя удалить, пока на неё ссылаются другие
+	ErrInUse = errors.New("record is still referenced")
+	// ErrDuplicate — запись с таким уникальным ключом уже есть
+	ErrDuplicate = errors.New("duplicate record")
+)
+
+type UserRepository interface {
+	UsernameExists(ctx context.Context, username string) (bool, error)
+	ConfirmedEmailExists(ctx context.Context, email string) (bool, error)
+	// CreateUser сохраняет пользователя и записывает присвоенный id в user.ID
+	CreateUser(ctx context.Context, user *User, passwordHash string) error
+	// RegisterUser в одной транзакции сохраняет пользователя, хеш его токена подтверждения и письмо
+	// с этим токеном; письмо не уходит, если регистрация не сохранилась, и наоборот
+	RegisterUser(ctx context.Context, user *User, passwordHash, verificationTokenHash string, tokenExpiresAt time.Time, message *OutboxEmail) error
+	GetUser(ctx context.Context, id int) (UserRecord, error)
+	GetUserByUsername(ctx context.Context, username string) (UserRecord, error)
+	ListUsers(ctx context.Context) ([]UserRecord, error)
+	// ConfirmUser по хешу токена подтверждает email пользователя, помечает токен использованным,
+	// удаляет остальные токены подтверждения этого адреса и неподтверждённые регистрации на него.
+	// ErrNotFound — токена нет, ErrTokenReused — он уже использован, ErrTokenExpired — истёк.
+	ConfirmUser(ctx context.Context, tokenHash string, at time.Time) error
+	// UpdatePassword меняет хеш пароля; токены, выпущенные до changedAt, перестают приниматься
+	UpdatePassword(ctx context.Context, userID int, passwordHash string, changedAt time.Time) error
+	SetRole(ctx context.Context, userID int, role string) (User, error)
+	SetLocale(ctx context.Context, userID int, locale string) error
+}
+
+type AlgorithmRepository interface {
+	// CreateAlgorithm сохраняет алгоритм вместе с первой ревизией
+	CreateAlgorithm(ctx context.Context, algorithm *Algorithm, message string) error
+	// GetAlgorithm возвращает алгоритм из каталога или, при trashed, из корзины
+	GetAlgorithm(ctx context.Context, id int, trashed bool) (Algorithm, error)
+	ListAlgorithms(ctx context.Context, filter AlgorithmFilter) ([]Algorithm, error)
+	// CountAlgorithms считает алгоритмы, подходящие под фильтр, без учёта Limit и After
+	CountAlgorithms(ctx context.Context, filter AlgorithmFilter) (int, error)
+	// ReviseAlgorithm атомарно изменяет алгоритм и записывает новую ревизию. update получает
+	// текущее состояние и может прервать изменение, вернув ошибку. Изменённый алгоритм снова
+	// уходит на модерацию.
+	ReviseAlgorithm(ctx context.Context, id, authorID int, message string, update func(*Algorithm) error) (Algorithm, int, error)
+	// ModerateAlgorithm записывает решение модератора и возвращает алгоритм и его автора
+	ModerateAlgorithm(ctx context.Context, id, moderatorID int, status, comment string) (Algorithm, User, error)
+
+	TrashAlgorithm(ctx context.Context, id int, at time.Time) error
+	RestoreAlgorithm(ctx context.Context, id int) (Algorithm, error)
+	// ListTrash возвращает корзину пользователя, а при userID == 0 — корзину всех пользователей
+	ListTrash(ctx context.Context, userID int) ([]Algorithm, error)
+	// PurgeTrash окончательно удаляет алгоритмы, попавшие в корзину раньше before
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+
+	// ListRevisions возвращает историю без кода, от новых ревизий к старым
+	ListRevisions(ctx context.Context, algorithmID int) ([]Revision, error)
+	GetRevision(ctx context.Context, algorithmID, revision int) (Revision, error)
+}
+
+// CategoryRepository хранит дерево категорий. Переименование категории обновляет topic
+// её алгоритмов в той же транзакции.
+type CategoryRepository interface {
+	// ListCategories возвращает все категории списком, упорядоченным по имени
+	ListCategories(ctx context.Context) ([]Category, error)
+	GetCategory(ctx context.Context, id int) (Category, error)
+	// CreateCategory записывает id в category.ID; ErrDuplicate, если у родителя уже есть категория с тем же Slug
+	CreateCategory(ctx context.Context, category *Category) error
+	// UpdateCategory меняет имя и родителя; отсутствие циклов проверяет вызывающий
+	UpdateCategory(ctx context.Context, category Category) error
+	// DeleteCategory возвращает ErrInUse, если у категории есть подкатегории или алгоритмы, в том числе в корзине
+	DeleteCategory(ctx context.Context, id int) error
+}
+
+// TagRepository хранит теги и их синонимы. Теги алгоритма сохраняются вместе с ним
+// в CreateAlgorithm и ReviseAlgorithm; неизвестные имена при этом создаются.
+type TagRepository interface {
+	// ResolveTags заменяет синонимы основными тегами и убирает повторы; неизвестные имена остаются как есть
+	ResolveTags(ctx context.Context, names []string) ([]string, error)
+	// TagCounts возвращает основные теги с опубликованными алгоритмами, у которых имя или синоним
+	// начинается с prefix, от самых частых к редким; limit 0 не ограничивает выборку
+	TagCounts(ctx context.Context, prefix string, limit int) ([]TagCount, error)
+	// AddTagSynonym делает synonym синонимом tag. Если synonym уже основной тег, он сливается
+	// с tag: его алгоритмы и синонимы переходят к tag.
+	AddTagSynonym(ctx context.Context, tag, synonym string) error
+	// RemoveTagSynonym возвращает ErrNotFound, если synonym не синоним
+	RemoveTagSynonym(ctx context.Context, synonym string) error
+}
+
+// RatingRepository хранит оценки алгоритмов. Агрегаты в algorithms пересчитываются
+// в той же транзакции, что и изменение оценки.
+type RatingRepository interface {
+	// RateAlgorithm ставит или заменяет оценку пользователя
+	RateAlgorithm(ctx context.Context, algorithmID, userID, score int, at time.Time) (RatingSummary, error)
+	// DeleteRating возвращает ErrNotFound, если пользователь не оценивал алгоритм
+	DeleteRating(ctx context.Context, algorithmID, userID int) (RatingSummary, error)
+	// GetRating возвращает оценку пользователя или ErrNotFound
+	GetRating(ctx context.Context, algorithmID, userID int) (int, error)
+}
+
+// CommentRepository хранит комментарии к алгоритмам
+type CommentRepository interface {
+	// CreateComment записывает комментарий и заполняет его ID и CreatedAt. Ответ должен
+	// относиться к тому же алгоритму, что и родитель; это проверяет вызывающий.
+	CreateComment(ctx context.Context, comment *Comment) error
+	// GetComment возвращает комментарий, в том числе удалённый, без ответов
+	GetComment(ctx context.Context, id int) (Comment, error)
+	// ListComments возвращает страницу корневых комментариев в порядке создания, каждый с деревом ответов
+	ListComments(ctx context.Context, filter CommentFilter) ([]Comment, error)
+	// UpdateComment заменяет текст; удалённый комментарий изменить нельзя (ErrNotFound)
+	UpdateComment(ctx context.Context, id int, body string, editedAt time.Time) (Comment, error)
+	// DeleteComment помечает комментарий удалённым; повторное удаление возвращает ErrNotFound
+	DeleteComment(ctx context.Context, id, deletedBy int, at time.Time) error
+}
+
+// TestCaseRepository хранит тесты алгоритмов и результаты их прогона по ревизиям
+type TestCaseRepository interface {
+	// ListTestCases возвращает тесты в порядке создания; скрытые — только при includeHidden
+	ListTestCases(ctx context.Context, algorithmID int, includeHidden bool) ([]TestCase, error)
+	// CreateTestCase записывает ID и CreatedAt теста; число тестов проверяет вызывающий
+	CreateTestCase(ctx context.Context, testCase *TestCase) error
+	// UpdateTestCase меняет вход, вывод и способ сравнения и записывает CreatedAt; ErrNotFound,
+	// если у алгоритма нет такого теста
+	UpdateTestCase(ctx context.Context, testCase *TestCase) error
+	DeleteTestCase(ctx context.Context, algorithmID, id int) error
+	// SaveVerification записывает результат прогона ревизии, заменяя прежний. VerificationStatus
+	// алгоритма меняется, только если ревизия всё ещё последняя. При Total == 0 результат
+	// ревизии удаляется: проверять больше нечем.
+	SaveVerification(ctx context.Context, verification Verification) error
+}
+
+// BenchmarkRepository хранит замеры производительности и их результаты
+type BenchmarkRepository interface {
+	// CreateBenchmark записывает замер со статусом pending и алгоритмами без результатов;
+	// заполняет ID и CreatedAt
+	CreateBenchmark(ctx context.Context, benchmark *Benchmark) error
+	// GetBenchmark возвращает замер с алгоритмами в порядке запроса и их результатами
+	GetBenchmark(ctx context.Context, id int) (Benchmark, error)
+	// ListBenchmarks возвращает замеры пользователя, начиная с последнего, без результатов по размерам
+	ListBenchmarks(ctx context.Context, userID int) ([]Benchmark, error)
+	// ListUnfinishedBenchmarks возвращает id замеров в статусах pending и running по порядку создания
+	ListUnfinishedBenchmarks(ctx context.Context) ([]int, error)
+	StartBenchmark(ctx context.Context, id int, at time.Time) error
+	// SaveBenchmarkAlgorithm заменяет итог и результаты алгоритма в замере
+	SaveBenchmarkAlgorithm(ctx context.Context, benchmarkID int, algorithm BenchmarkAlgorithm) error
+	FinishBenchmark(ctx context.Context, id int, status, errorMessage string, at time.Time) error
+}
+
+// TokenRepository хранит одноразовые токены подтверждения email и сброса пароля. Токены
+// подтверждения хранятся только в виде хешей.
+type TokenRepository interface {
+	// SaveVerificationToken заменяет прежний неиспользованный токен подтверждения пользователя
+	// и ставит в очередь письмо с новым
+	SaveVerificationToken(ctx context.Context, user User, tokenHash string, expiresAt time.Time, message *OutboxEmail) error
+	// LastVerificationTokenAt возвращает время выпуска последнего токена подтверждения пользователя;
+	// ErrNotFound, если токенов нет
+	LastVerificationTokenAt(ctx context.Context, userID int) (time.Time, error)
+
+	// SaveResetToken заменяет прежний токен сброса пароля пользователя и ставит в очередь письмо с новым
+	SaveResetToken(ctx context.Context, user User, token string, createdAt time.Time, message *OutboxEmail) error
+	// FindResetToken ищет токен, выпущенный не раньше notBefore
+	FindResetToken(ctx context.Context, token, username, email string, notBefore time.Time) (int, error)
+	DeleteResetToken(ctx context.Context, token string) error
+}
+
+// OutboxRepository — очередь исходящих писем. Письмо, взятое в отправку, откладывается до
+// конца аренды, поэтому после падения сервера посреди отправки его возьмут снова.
+type OutboxRepository interface {
+	// EnqueueEmail ставит письмо в очередь и записывает присвоенный id в email.ID
+	EnqueueEmail(ctx context.Context, email *OutboxEmail) error
+	// ClaimEmails берёт в отправку до limit писем, срок которых наступил к now: увеличивает
+	// число попыток и откладывает следующую до leaseUntil
+	ClaimEmails(ctx context.Context, now, leaseUntil time.Time, limit int) ([]OutboxEmail, error)
+	// MarkEmailSent отмечает письмо отправленным и стирает его тело: в нём бывают одноразовые
+	// ссылки, которым нечего делать в базе до истечения mail.retention
+	MarkEmailSent(ctx context.Context, id int, at time.Time) error
+	// MarkEmailFailed записывает ошибку и откладывает письмо до retryAt; при nil retryAt письмо
+	// больше не отправляется
+	MarkEmailFailed(ctx context.Context, id int, errorMessage string, retryAt *time.Time) error
+	GetEmail(ctx context.Context, id int) (OutboxEmail, error)
+	// ListEmails возвращает до limit последних писем, при непустом status — только в этом состоянии
+	ListEmails(ctx context.Context, status string, limit int) ([]OutboxEmail, error)
+	// ResendEmail возвращает брошенное письмо в очередь со сброшенными попытками; ErrNotFound,
+	// если брошенного письма с таким id нет. Отправленное не переотправить: его тело стёрто.
+	ResendEmail(ctx context.Context, id int, at time.Time) (OutboxEmail, error)
+	// PurgeSentEmails удаляет письма, отправленные раньше before
+	PurgeSentEmails(ctx context.Context, before time.Time) (int64, error)
+}
+
+// SessionRepository хранит сессии и их refresh-токены. Сами токены не сохраняются, только их хеши.
+type SessionRepository interface {
+	// CreateSession открывает сессию с первым refresh-токеном и записывает её id в session.ID
+	CreateSession(ctx context.Context, session *Session, refreshTokenHash string) error
+	// GetSession возвращает сессию пользователя, в том числе отозванную или истёкшую
+	GetSession(ctx context.Context, id, userID int) (Session, error)
+	// TouchSession обновляет last_used_at, если оно старше staleBefore
+	TouchSession(ctx context.Context, id int, now, staleBefore time.Time) error
+	// RotateRefreshToken помечает токен использованным и выпускает вместо него новый, продлевая
+	// сессию до expiresAt. Повторное предъявление токена отзывает сессию и возвращает ErrTokenReused.
+	RotateRefreshToken(ctx context.Context, oldHash, newHash string, expiresAt time.Time, ip, userAgent string) (Session, User, error)
+	// ListActiveSessions возвращает неотозванные и неистёкшие сессии, начиная с последней использованной
+	ListActiveSessions(ctx context.Context, userID int, now time.Time) ([]Session, error)
+	// RevokeSession возвращает ErrNotFound, если у пользователя нет такой активной сессии
+	RevokeSession(ctx context.Context, id, userID int, at time.Time) error
+	RevokeUserSessions(ctx context.Context, userID int, at time.Time) error
+}
+
+// LoginThrottleRepository считает неудачные попытки входа под одним именем и с одного IP-адреса
+// и хранит блокировки входа
+type LoginThrottleRepository interface {
+	// ClaimLoginAttempt атомарно проверяет и засчитывает попытку входа. Если блокировка или пауза
+	// delay после прошлых неудач не кончились, попытка не засчитывается и возвращается, сколько ещё
+	// ждать. Иначе счётчик увеличивается сразу, до проверки пароля, чтобы одновременные попытки
+	// не проскочили паузу; неудачи раньше resetBefore при этом забываются.
+	ClaimLoginAttempt(ctx context.Context, scope, subject string, at, resetBefore time.Time, delay func(failures int) time.Duration) (LoginThrottle, time.Duration, error)
+	// RefundLoginAttempt возвращает попытку, засчитанную ClaimLoginAttempt, когда вход удался или
+	// пароль так и не проверялся; счётчик без неудач и блокировки удаляется
+	RefundLoginAttempt(ctx context.Context, scope, subject string) error
+	// LockLogin блокирует вход до until и в той же транзакции ставит в очередь письмо message, если
+	// оно есть. Снять блокировку раньше можно токеном с хешем unlockTokenHash; пустой — нельзя.
+	LockLogin(ctx context.Context, scope, subject string, until time.Time, unlockTokenHash string, message *OutboxEmail) error
+	// UnlockLogin по хешу токена из письма снимает блокировку и обнуляет счётчик; ErrNotFound,
+	// если токен неизвестен или блокировка к at уже кончилась
+	UnlockLogin(ctx context.Context, unlockTokenHash string, at time.Time) (LoginThrottle, error)
+	// ClearLoginThrottle обнуляет счётчик и снимает блокировку; ErrNotFound, если их нет
+	ClearLoginThrottle(ctx context.Context, scope, subject string) error
+	// ListLoginThrottles возвращает не больше limit счётчиков с неудачами после resetBefore
+	// и действующими на now блокировками, начиная с последней неудачи
+	ListLoginThrottles(ctx context.Context, now, resetBefore time.Time, limit int) ([]LoginThrottle, error)
+	// PurgeLoginThrottles удаляет счётчики без неудач после before, блокировка которых к now кончилась
+	PurgeLoginThrottles(ctx context.Context, before, now time.Time) (int64, error)
+}
+
+// Store объединяет все репозитории одного хранилища
+type Store interface {
+	UserRepository
+	AlgorithmRepository
+	CategoryRepository
+	TagRepository
+	RatingRepository
+	CommentRepository
+	TestCaseRepository
+	BenchmarkRepository
+	TokenRepository
+	OutboxRepository
+	SessionRepository
+	LoginThrottleRepository
+	Close() error
+}
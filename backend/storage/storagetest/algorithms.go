@@ -0,0 +1,267 @@
+package storagetest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// createAlgorithm сохраняет одобренный алгоритм пользователя в категории categoryID
+func createAlgorithm(t *testing.T, s storage.Store, userID, categoryID int, title string) storage.Algorithm {
+	t.Helper()
+	algorithm := storage.Algorithm{
+		Title:               title,
+		Code:                "print('" + title + "')",
+		UserID:              userID,
+		CategoryID:          &categoryID,
+		ProgrammingLanguage: "Python",
+		Approved:            true,
+		ModerationStatus:    storage.ModerationApproved,
+	}
+	if err := s.CreateAlgorithm(ctx, &algorithm, "Initial version"); err != nil {
+		t.Fatalf("CreateAlgorithm(%s): %v", title, err)
+	}
+	return algorithm
+}
+
+// createCategory заводит категорию; parentID 0 — корневую
+func createCategory(t *testing.T, s storage.Store, name string, parentID int) storage.Category {
+	t.Helper()
+	category := storage.Category{Name: name}
+	if parentID != 0 {
+		category.ParentID = &parentID
+	}
+	if err := s.CreateCategory(ctx, &category); err != nil {
+		t.Fatalf("CreateCategory(%s): %v", name, err)
+	}
+	return category
+}
+
+func testCategories(t *testing.T, s storage.Store) {
+	// Новое хранилище начинается со стандартных категорий
+	categories, err := s.ListCategories(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(categories) != len(storage.DefaultCategories) {
+		t.Errorf("new store has %d categories, want %d", len(categories), len(storage.DefaultCategories))
+	}
+
+	graphs := createCategory(t, s, "Graph algorithms", 0)
+	paths := createCategory(t, s, "Shortest paths", graphs.ID)
+	if paths.Slug != "shortest path" {
+		t.Errorf("slug = %q, want %q", paths.Slug, "shortest path")
+	}
+
+	duplicates := []struct {
+		name     string
+		parentID int
+	}{
+		{"graph  ALGORITHM", 0},
+		{"Shortest Path", graphs.ID},
+		// Стандартная категория тоже занимает своё имя
+		{"sorting", 0},
+	}
+	for _, tt := range duplicates {
+		category := storage.Category{Name: tt.name}
+		if tt.parentID != 0 {
+			category.ParentID = &tt.parentID
+		}
+		wantErr(t, "CreateCategory("+tt.name+")", s.CreateCategory(ctx, &category), storage.ErrDuplicate)
+	}
+	// Под другим родителем то же имя разрешено
+	createCategory(t, s, "Shortest paths", 0)
+
+	alice := createUser(t, s, "alice")
+	algorithm := createAlgorithm(t, s, alice.ID, paths.ID, "Dijkstra")
+
+	if err := s.UpdateCategory(ctx, storage.Category{ID: paths.ID, Name: "Paths", ParentID: &graphs.ID}); err != nil {
+		t.Fatal(err)
+	}
+	renamed, err := s.GetAlgorithm(ctx, algorithm.ID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renamed.Topic != "Paths" {
+		t.Errorf("topic after renaming the category = %q, want Paths", renamed.Topic)
+	}
+	wantErr(t, "UpdateCategory(missing)", s.UpdateCategory(ctx, storage.Category{ID: 1000, Name: "X"}), storage.ErrNotFound)
+
+	wantErr(t, "DeleteCategory with children", s.DeleteCategory(ctx, graphs.ID), storage.ErrInUse)
+	wantErr(t, "DeleteCategory with algorithms", s.DeleteCategory(ctx, paths.ID), storage.ErrInUse)
+	if err := s.TrashAlgorithm(ctx, algorithm.ID, now()); err != nil {
+		t.Fatal(err)
+	}
+	wantErr(t, "DeleteCategory with trashed algorithms", s.DeleteCategory(ctx, paths.ID), storage.ErrInUse)
+	if _, err := s.PurgeTrash(ctx, now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteCategory(ctx, paths.ID); err != nil {
+		t.Errorf("DeleteCategory after purging: %v", err)
+	}
+	_, err = s.GetCategory(ctx, paths.ID)
+	wantErr(t, "GetCategory(deleted)", err, storage.ErrNotFound)
+}
+
+func testAlgorithms(t *testing.T, s storage.Store) {
+	alice := createUser(t, s, "alice")
+	bob := createUser(t, s, "bob")
+	sorting := createCategory(t, s, "Sorting networks", 0)
+	merge := createCategory(t, s, "Merge sorts", sorting.ID)
+
+	quick := createAlgorithm(t, s, alice.ID, sorting.ID, "Quicksort")
+	mergesort := createAlgorithm(t, s, bob.ID, merge.ID, "Mergesort")
+	pending := storage.Algorithm{Title: "Bogosort", Code: "x", UserID: bob.ID, CategoryID: &sorting.ID, ProgrammingLanguage: "Go",
+		ModerationStatus: storage.ModerationPending}
+	if err := s.CreateAlgorithm(ctx, &pending, "Initial version"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.GetAlgorithm(ctx, quick.ID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "Quicksort" || got.CategoryID == nil || *got.CategoryID != sorting.ID || got.UserID != alice.ID || got.CreatedAt.IsZero() {
+		t.Errorf("GetAlgorithm = %+v", got)
+	}
+	_, err = s.GetAlgorithm(ctx, quick.ID, true)
+	wantErr(t, "GetAlgorithm from the trash", err, storage.ErrNotFound)
+
+	filters := []struct {
+		name   string
+		filter storage.AlgorithmFilter
+		want   []int
+	}{
+		{"everything", storage.AlgorithmFilter{}, []int{quick.ID, mergesort.ID, pending.ID}},
+		{"by author", storage.AlgorithmFilter{UserID: bob.ID}, []int{mergesort.ID, pending.ID}},
+		{"title substring", storage.AlgorithmFilter{Title: "SORT"}, []int{quick.ID, mergesort.ID, pending.ID}},
+		{"language", storage.AlgorithmFilter{ProgrammingLanguage: "Go"}, []int{pending.ID}},
+		// Категория ищется вместе с подкатегориями
+		{"category subtree", storage.AlgorithmFilter{CategoryID: sorting.ID}, []int{quick.ID, mergesort.ID, pending.ID}},
+		{"subcategory", storage.AlgorithmFilter{CategoryID: merge.ID}, []int{mergesort.ID}},
+		// Неодобренный алгоритм виден только автору
+		{"viewer alice", storage.AlgorithmFilter{ViewerID: alice.ID}, []int{quick.ID, mergesort.ID}},
+		{"viewer bob", storage.AlgorithmFilter{ViewerID: bob.ID}, []int{quick.ID, mergesort.ID, pending.ID}},
+	}
+	for _, tt := range filters {
+		algorithms, err := s.ListAlgorithms(ctx, tt.filter)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !sameIDs(algorithms, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, ids(algorithms), tt.want)
+		}
+		count, err := s.CountAlgorithms(ctx, tt.filter)
+		if err != nil || count != len(tt.want) {
+			t.Errorf("%s: CountAlgorithms = %d, %v, want %d", tt.name, count, err, len(tt.want))
+		}
+	}
+
+	revised, revision, err := s.ReviseAlgorithm(ctx, quick.ID, alice.ID, "Faster partition", func(algorithm *storage.Algorithm) error {
+		algorithm.Code = "print('faster')"
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revision != 2 || revised.Code != "print('faster')" {
+		t.Errorf("ReviseAlgorithm = revision %d, %+v", revision, revised)
+	}
+	refused := errors.New("refused")
+	_, _, err = s.ReviseAlgorithm(ctx, quick.ID, alice.ID, "Nothing", func(*storage.Algorithm) error { return refused })
+	wantErr(t, "ReviseAlgorithm refused by update", err, refused)
+	_, _, err = s.ReviseAlgorithm(ctx, 1000, alice.ID, "Nothing", func(*storage.Algorithm) error { return nil })
+	wantErr(t, "ReviseAlgorithm(missing)", err, storage.ErrNotFound)
+
+	revisions, err := s.ListRevisions(ctx, quick.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 2 || revisions[0].Revision != 2 || revisions[0].Message != "Faster partition" || revisions[0].Code != "" ||
+		revisions[1].Revision != 1 || revisions[1].AuthorUsername != "alice" {
+		t.Errorf("ListRevisions = %+v", revisions)
+	}
+	first, err := s.GetRevision(ctx, quick.ID, 1)
+	if err != nil || first.Code != "print('Quicksort')" {
+		t.Errorf("GetRevision(1) = %+v, %v", first, err)
+	}
+	_, err = s.GetRevision(ctx, quick.ID, 3)
+	wantErr(t, "GetRevision(missing)", err, storage.ErrNotFound)
+}
+
+func testTrash(t *testing.T, s storage.Store) {
+	alice := createUser(t, s, "alice")
+	bob := createUser(t, s, "bob")
+	category := createCategory(t, s, "Heaps", 0)
+	old := createAlgorithm(t, s, alice.ID, category.ID, "Binary heap")
+	recent := createAlgorithm(t, s, bob.ID, category.ID, "Fibonacci heap")
+
+	start := now()
+	if err := s.TrashAlgorithm(ctx, old.ID, start.Add(-48*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.TrashAlgorithm(ctx, recent.ID, start); err != nil {
+		t.Fatal(err)
+	}
+	wantErr(t, "TrashAlgorithm twice", s.TrashAlgorithm(ctx, recent.ID, start), storage.ErrNotFound)
+
+	if _, err := s.GetAlgorithm(ctx, recent.ID, false); err != storage.ErrNotFound {
+		t.Errorf("trashed algorithm still in the catalog: %v", err)
+	}
+	algorithms, err := s.ListAlgorithms(ctx, storage.AlgorithmFilter{})
+	if err != nil || len(algorithms) != 0 {
+		t.Errorf("ListAlgorithms with everything trashed = %v, %v", ids(algorithms), err)
+	}
+
+	trash, err := s.ListTrash(ctx, alice.ID)
+	if err != nil || !sameIDs(trash, []int{old.ID}) {
+		t.Errorf("ListTrash(alice) = %v, %v", ids(trash), err)
+	}
+	trash, err = s.ListTrash(ctx, 0)
+	if err != nil || !sameIDs(trash, []int{old.ID, recent.ID}) {
+		t.Errorf("ListTrash(all) = %v, %v", ids(trash), err)
+	}
+
+	purged, err := s.PurgeTrash(ctx, start.Add(-24*time.Hour))
+	if err != nil || purged != 1 {
+		t.Errorf("PurgeTrash = %d, %v, want 1", purged, err)
+	}
+	_, err = s.RestoreAlgorithm(ctx, old.ID)
+	wantErr(t, "RestoreAlgorithm(purged)", err, storage.ErrNotFound)
+
+	restored, err := s.RestoreAlgorithm(ctx, recent.ID)
+	if err != nil || restored.DeletedAt != nil {
+		t.Fatalf("RestoreAlgorithm = %+v, %v", restored, err)
+	}
+	if _, err := s.GetAlgorithm(ctx, recent.ID, false); err != nil {
+		t.Errorf("restored algorithm: %v", err)
+	}
+}
+
+func ids(algorithms []storage.Algorithm) []int {
+	result := []int{}
+	for _, algorithm := range algorithms {
+		result = append(result, algorithm.ID)
+	}
+	return result
+}
+
+// sameIDs сравнивает множества id без учёта порядка
+func sameIDs(algorithms []storage.Algorithm, want []int) bool {
+	if len(algorithms) != len(want) {
+		return false
+	}
+	seen := map[int]bool{}
+	for _, id := range want {
+		seen[id] = true
+	}
+	for _, algorithm := range algorithms {
+		if !seen[algorithm.ID] {
+			return false
+		}
+	}
+	return true
+}
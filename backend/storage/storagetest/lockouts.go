@@ -0,0 +1,129 @@
+package storagetest
+
+import (
+	"testing"
+	"time"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// loginDelay — пауза после двух неудач подряд
+func loginDelay(failures int) time.Duration {
+	if failures >= 2 {
+		return time.Minute
+	}
+	return 0
+}
+
+func testLoginThrottles(t *testing.T, s storage.Store) {
+	base := now()
+	resetBefore := base.Add(-time.Hour)
+	const account, ip = storage.LoginScopeAccount, storage.LoginScopeIP
+
+	claims := []struct {
+		name         string
+		scope        string
+		at           time.Time
+		resetBefore  time.Time
+		wantFailures int
+		wantWait     time.Duration
+	}{
+		{"first failure", account, base, resetBefore, 1, 0},
+		{"second failure", account, base.Add(time.Second), resetBefore, 2, 0},
+		{"during the pause", account, base.Add(2 * time.Second), resetBefore, 2, 59 * time.Second},
+		{"other scope", ip, base, resetBefore, 1, 0},
+		{"after the pause", account, base.Add(time.Minute + time.Second), resetBefore, 3, 0},
+		// Неудачи раньше resetBefore забыты вместе с паузой после них
+		{"after the reset", account, base.Add(2 * time.Hour), base.Add(time.Hour), 1, 0},
+	}
+	for _, tt := range claims {
+		throttle, wait, err := s.ClaimLoginAttempt(ctx, tt.scope, "alice", tt.at, tt.resetBefore, loginDelay)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if throttle.Failures != tt.wantFailures || wait != tt.wantWait {
+			t.Errorf("%s: %d failures, wait %v; want %d, %v", tt.name, throttle.Failures, wait, tt.wantFailures, tt.wantWait)
+		}
+	}
+
+	// Возврат попытки уменьшает счётчик, а последний — удаляет его
+	if _, _, err := s.ClaimLoginAttempt(ctx, account, "alice", base.Add(2*time.Hour+time.Second), resetBefore, loginDelay); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := s.RefundLoginAttempt(ctx, account, "alice"); err != nil {
+			t.Fatalf("refund %d: %v", i+1, err)
+		}
+	}
+	wantErr(t, "RefundLoginAttempt(no counter)", s.RefundLoginAttempt(ctx, account, "alice"), storage.ErrNotFound)
+	wantErr(t, "ClearLoginThrottle(no counter)", s.ClearLoginThrottle(ctx, account, "alice"), storage.ErrNotFound)
+
+	// Блокировка с токеном разблокировки
+	at := base.Add(2 * time.Hour)
+	until := at.Add(time.Hour)
+	if _, _, err := s.ClaimLoginAttempt(ctx, account, "alice", at, resetBefore, loginDelay); err != nil {
+		t.Fatal(err)
+	}
+	message := storage.OutboxEmail{Kind: storage.EmailAccountLocked, Recipient: "alice@example.com", Subject: "Locked", Body: "unlock link"}
+	if err := s.LockLogin(ctx, account, "alice", until, "unlock-hash", &message); err != nil {
+		t.Fatal(err)
+	}
+	if message.ID == 0 {
+		t.Error("LockLogin did not queue the email")
+	}
+	wantErr(t, "LockLogin(no counter)", s.LockLogin(ctx, account, "bob", until, "", nil), storage.ErrNotFound)
+	if _, wait, err := s.ClaimLoginAttempt(ctx, account, "alice", at.Add(time.Minute), resetBefore, loginDelay); err != nil ||
+		wait != until.Sub(at.Add(time.Minute)) {
+		t.Errorf("claim while locked: wait %v, %v; want %v", wait, err, until.Sub(at.Add(time.Minute)))
+	}
+
+	unlocks := []struct {
+		name      string
+		tokenHash string
+		at        time.Time
+		want      error
+	}{
+		{"wrong token", "other-hash", at, storage.ErrNotFound},
+		{"empty token", "", at, storage.ErrNotFound},
+		{"after the lock", "unlock-hash", until, storage.ErrNotFound},
+		{"valid token", "unlock-hash", at, nil},
+		{"token used", "unlock-hash", at, storage.ErrNotFound},
+	}
+	for _, tt := range unlocks {
+		throttle, err := s.UnlockLogin(ctx, tt.tokenHash, tt.at)
+		wantErr(t, "UnlockLogin("+tt.name+")", err, tt.want)
+		if err == nil && (throttle.Scope != account || throttle.Subject != "alice") {
+			t.Errorf("UnlockLogin(%s) = %+v", tt.name, throttle)
+		}
+	}
+
+	// Блокировка без токена держится до конца, даже если вернуть попытку
+	if err := s.LockLogin(ctx, ip, "alice", until, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RefundLoginAttempt(ctx, ip, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	throttles, err := s.ListLoginThrottles(ctx, at, at.Add(-time.Minute), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(throttles) != 1 || throttles[0].Scope != ip || throttles[0].Failures != 0 || throttles[0].LockedUntil == nil ||
+		!throttles[0].LockedUntil.Equal(until) {
+		t.Errorf("ListLoginThrottles = %+v, want only the locked ip counter", throttles)
+	}
+
+	purges := []struct {
+		now  time.Time
+		want int64
+	}{
+		{at, 0},
+		{until.Add(time.Second), 1},
+	}
+	for _, tt := range purges {
+		if purged, err := s.PurgeLoginThrottles(ctx, at, tt.now); err != nil || purged != tt.want {
+			t.Errorf("PurgeLoginThrottles(now %v) = %d, %v, want %d", tt.now, purged, err, tt.want)
+		}
+	}
+	wantErr(t, "ClearLoginThrottle(purged)", s.ClearLoginThrottle(ctx, ip, "alice"), storage.ErrNotFound)
+}
@@ -0,0 +1,179 @@
+package storagetest
+
+import (
+	"testing"
+	"time"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// enqueueEmail ставит в очередь письмо с телом, по которому его видно в ошибках
+func enqueueEmail(t *testing.T, s storage.Store, recipient string) storage.OutboxEmail {
+	t.Helper()
+	email := storage.OutboxEmail{Kind: storage.EmailVerification, Recipient: recipient, Subject: "Confirm",
+		Body: "body for " + recipient, HTMLBody: "<p>body for " + recipient + "</p>"}
+	if err := s.EnqueueEmail(ctx, &email); err != nil {
+		t.Fatalf("EnqueueEmail(%s): %v", recipient, err)
+	}
+	if email.ID == 0 || email.Status != storage.EmailPending {
+		t.Fatalf("EnqueueEmail(%s) = %+v", recipient, email)
+	}
+	return email
+}
+
+func claim(t *testing.T, s storage.Store, at, leaseUntil time.Time, limit int) []storage.OutboxEmail {
+	t.Helper()
+	emails, err := s.ClaimEmails(ctx, at, leaseUntil, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return emails
+}
+
+func emailIDs(emails []storage.OutboxEmail) []int {
+	ids := make([]int, len(emails))
+	for i, email := range emails {
+		ids[i] = email.ID
+	}
+	return ids
+}
+
+// equalIDs сравнивает id с учётом порядка
+func equalIDs(got, want []int) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func testOutbox(t *testing.T, s storage.Store) {
+	first := enqueueEmail(t, s, "first@example.com")
+	second := enqueueEmail(t, s, "second@example.com")
+	// Письма ставятся в очередь с текущим временем, поэтому забираются чуть позже
+	at := now().Add(time.Second)
+	lease := at.Add(time.Minute)
+
+	// Взятое письмо получает попытку и откладывается до конца аренды
+	claimed := claim(t, s, at, lease, 1)
+	if !equalIDs(emailIDs(claimed), []int{first.ID}) {
+		t.Fatalf("first claim took %v, want [%d]", emailIDs(claimed), first.ID)
+	}
+	if claimed[0].Attempts != 1 || !claimed[0].NextAttemptAt.Equal(lease) || claimed[0].Body != first.Body {
+		t.Errorf("claimed %+v", claimed[0])
+	}
+	if claimed := claim(t, s, at, lease, 10); !equalIDs(emailIDs(claimed), []int{second.ID}) {
+		t.Errorf("second claim took %v, want only [%d]", emailIDs(claimed), second.ID)
+	}
+	if claimed := claim(t, s, at, lease, 10); len(claimed) != 0 {
+		t.Errorf("leased emails claimed again: %v", emailIDs(claimed))
+	}
+
+	// Неудача с повтором оставляет письмо в очереди до retryAt
+	retryAt := at.Add(10 * time.Second)
+	if err := s.MarkEmailFailed(ctx, first.ID, "connection refused", &retryAt); err != nil {
+		t.Fatal(err)
+	}
+	email, err := s.GetEmail(ctx, first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if email.Status != storage.EmailPending || email.LastError != "connection refused" || !email.NextAttemptAt.Equal(retryAt) {
+		t.Errorf("after a retryable failure: %+v", email)
+	}
+	if claimed := claim(t, s, retryAt.Add(-time.Second), lease, 10); len(claimed) != 0 {
+		t.Errorf("claimed before the retry: %v", emailIDs(claimed))
+	}
+	claimed = claim(t, s, retryAt, lease, 10)
+	if !equalIDs(emailIDs(claimed), []int{first.ID}) || claimed[0].Attempts != 2 {
+		t.Fatalf("retry claimed %+v", claimed)
+	}
+
+	// Без retryAt письмо брошено и больше не берётся
+	if err := s.MarkEmailFailed(ctx, first.ID, "mailbox unavailable", nil); err != nil {
+		t.Fatal(err)
+	}
+	if claimed := claim(t, s, lease.Add(time.Hour), lease.Add(2*time.Hour), 10); !equalIDs(emailIDs(claimed), []int{second.ID}) {
+		t.Errorf("claim after the lease took %v, want only [%d]", emailIDs(claimed), second.ID)
+	}
+
+	// Отправленное письмо теряет тело
+	sentAt := now()
+	if err := s.MarkEmailSent(ctx, second.ID, sentAt); err != nil {
+		t.Fatal(err)
+	}
+	email, err = s.GetEmail(ctx, second.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if email.Status != storage.EmailSent || email.Body != "" || email.HTMLBody != "" || email.SentAt == nil || !email.SentAt.Equal(sentAt) {
+		t.Errorf("after sending: %+v", email)
+	}
+	wantErr(t, "MarkEmailSent(missing)", s.MarkEmailSent(ctx, second.ID+100, sentAt), storage.ErrNotFound)
+	wantErr(t, "MarkEmailFailed(missing)", s.MarkEmailFailed(ctx, second.ID+100, "error", nil), storage.ErrNotFound)
+	_, err = s.GetEmail(ctx, second.ID+100)
+	wantErr(t, "GetEmail(missing)", err, storage.ErrNotFound)
+
+	lists := []struct {
+		status string
+		want   []int
+	}{
+		{"", []int{second.ID, first.ID}},
+		{storage.EmailDead, []int{first.ID}},
+		{storage.EmailSent, []int{second.ID}},
+		{storage.EmailPending, []int{}},
+	}
+	for _, tt := range lists {
+		emails, err := s.ListEmails(ctx, tt.status, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := emailIDs(emails); !equalIDs(got, tt.want) {
+			t.Errorf("ListEmails(%q) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+	if emails, err := s.ListEmails(ctx, "", 1); err != nil || !equalIDs(emailIDs(emails), []int{second.ID}) {
+		t.Errorf("ListEmails with limit 1 = %v, %v", emailIDs(emails), err)
+	}
+
+	// Переотправить можно только брошенное письмо
+	_, err = s.ResendEmail(ctx, second.ID, at)
+	wantErr(t, "ResendEmail(sent)", err, storage.ErrNotFound)
+	_, err = s.ResendEmail(ctx, second.ID+100, at)
+	wantErr(t, "ResendEmail(missing)", err, storage.ErrNotFound)
+	resendAt := now()
+	email, err = s.ResendEmail(ctx, first.ID, resendAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if email.Status != storage.EmailPending || email.Attempts != 0 || !email.NextAttemptAt.Equal(resendAt) || email.Body != first.Body {
+		t.Errorf("ResendEmail = %+v", email)
+	}
+	if claimed := claim(t, s, resendAt, lease, 10); !equalIDs(emailIDs(claimed), []int{first.ID}) || claimed[0].Attempts != 1 {
+		t.Errorf("claim after resend took %+v", claimed)
+	}
+
+	// Удаляются только отправленные раньше before
+	purges := []struct {
+		before time.Time
+		want   int64
+	}{
+		{sentAt, 0},
+		{sentAt.Add(time.Second), 1},
+		{sentAt.Add(time.Hour), 0},
+	}
+	for _, tt := range purges {
+		if purged, err := s.PurgeSentEmails(ctx, tt.before); err != nil || purged != tt.want {
+			t.Errorf("PurgeSentEmails(%v) = %d, %v, want %d", tt.before, purged, err, tt.want)
+		}
+	}
+	_, err = s.GetEmail(ctx, second.ID)
+	wantErr(t, "GetEmail(purged)", err, storage.ErrNotFound)
+	if _, err := s.GetEmail(ctx, first.ID); err != nil {
+		t.Errorf("pending email purged: %v", err)
+	}
+}
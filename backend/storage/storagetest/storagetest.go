@@ -0,0 +1,58 @@
+// Package storagetest проверяет, что все реализации storage.Store ведут себя одинаково.
+// Тесты каждого хранилища вызывают Run, передавая способ открыть новое пустое хранилище.
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// Run прогоняет общие тесты; каждый получает своё хранилище от open
+func Run(t *testing.T, open func(t *testing.T) storage.Store) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, s storage.Store)
+	}{
+		{"Users", testUsers},
+		{"Categories", testCategories},
+		{"Algorithms", testAlgorithms},
+		{"Trash", testTrash},
+		{"Sessions", testSessions},
+		{"VerificationTokens", testVerificationTokens},
+		{"Outbox", testOutbox},
+		{"LoginThrottles", testLoginThrottles},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, open(t))
+		})
+	}
+}
+
+var ctx = context.Background()
+
+// Время в тестах округлено до микросекунд: точнее PostgreSQL его не хранит
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// createUser заводит пользователя с адресом <username>@example.com
+func createUser(t *testing.T, s storage.Store, username string) storage.User {
+	t.Helper()
+	user := storage.User{Username: username, Email: username + "@example.com"}
+	if err := s.CreateUser(ctx, &user, "hash-"+username); err != nil {
+		t.Fatalf("CreateUser(%s): %v", username, err)
+	}
+	return user
+}
+
+// wantErr проверяет, что err — ожидаемая ошибка хранилища
+func wantErr(t *testing.T, what string, err, want error) {
+	t.Helper()
+	if err != want {
+		t.Errorf("%s: error %v, want %v", what, err, want)
+	}
+}
@@ -0,0 +1,91 @@
+package storagetest
+
+import (
+	"testing"
+	"time"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// register регистрирует неподтверждённого пользователя с токеном tokenHash и письмом о нём
+func register(t *testing.T, s storage.Store, username, email, tokenHash string, expiresAt time.Time) storage.User {
+	t.Helper()
+	user := storage.User{Username: username, Email: email}
+	message := storage.OutboxEmail{Kind: storage.EmailVerification, Recipient: email, Subject: "Confirm", Body: "link"}
+	if err := s.RegisterUser(ctx, &user, "hash-"+username, tokenHash, expiresAt, &message); err != nil {
+		t.Fatalf("RegisterUser(%s): %v", username, err)
+	}
+	if user.ID == 0 || message.ID == 0 {
+		t.Fatalf("RegisterUser(%s) did not set ids: user %d, email %d", username, user.ID, message.ID)
+	}
+	return user
+}
+
+func testVerificationTokens(t *testing.T, s storage.Store) {
+	before := now().Add(-time.Second)
+	expiresAt := now().Add(time.Hour)
+
+	// Два неподтверждённых пользователя претендуют на один адрес
+	alice := register(t, s, "alice", "shared@example.com", "hash-1", expiresAt)
+	mallory := register(t, s, "mallory", "shared@example.com", "hash-mallory", expiresAt)
+	emails, err := s.ListEmails(ctx, storage.EmailPending, 10)
+	if err != nil || len(emails) != 2 {
+		t.Fatalf("pending emails after two registrations: %d, %v", len(emails), err)
+	}
+
+	issuedAt, err := s.LastVerificationTokenAt(ctx, alice.ID)
+	if err != nil || issuedAt.Before(before) || issuedAt.After(now().Add(time.Second)) {
+		t.Errorf("LastVerificationTokenAt = %v, %v, want about now", issuedAt, err)
+	}
+	bob := createUser(t, s, "bob")
+	_, err = s.LastVerificationTokenAt(ctx, bob.ID)
+	wantErr(t, "LastVerificationTokenAt(no tokens)", err, storage.ErrNotFound)
+
+	// Новый токен заменяет прежний неиспользованный
+	message := storage.OutboxEmail{Kind: storage.EmailVerification, Recipient: alice.Email, Subject: "Confirm", Body: "new link"}
+	if err := s.SaveVerificationToken(ctx, alice, "hash-2", expiresAt, &message); err != nil {
+		t.Fatal(err)
+	}
+	if message.ID == 0 {
+		t.Error("SaveVerificationToken did not queue the email")
+	}
+
+	at := now()
+	confirms := []struct {
+		name      string
+		tokenHash string
+		want      error
+	}{
+		{"replaced token", "hash-1", storage.ErrNotFound},
+		{"unknown token", "hash-unknown", storage.ErrNotFound},
+		{"current token", "hash-2", nil},
+		{"used token", "hash-2", storage.ErrTokenReused},
+		// Подтверждение адреса удаляет остальные регистрации на него вместе с их токенами
+		{"token of a dropped registration", "hash-mallory", storage.ErrNotFound},
+	}
+	for _, tt := range confirms {
+		wantErr(t, "ConfirmUser("+tt.name+")", s.ConfirmUser(ctx, tt.tokenHash, at), tt.want)
+	}
+
+	record, err := s.GetUser(ctx, alice.ID)
+	if err != nil || !record.Confirmed {
+		t.Errorf("alice after confirmation: %+v, %v", record, err)
+	}
+	_, err = s.GetUser(ctx, mallory.ID)
+	wantErr(t, "GetUser(dropped registration)", err, storage.ErrNotFound)
+	if exists, err := s.ConfirmedEmailExists(ctx, "shared@example.com"); err != nil || !exists {
+		t.Errorf("ConfirmedEmailExists after confirmation = %v, %v", exists, err)
+	}
+	if _, err := s.GetUser(ctx, bob.ID); err != nil {
+		t.Errorf("user with another email dropped: %v", err)
+	}
+
+	// Истёкший токен не подтверждает адрес и остаётся истёкшим
+	carol := register(t, s, "carol", "carol@example.com", "hash-carol", at.Add(time.Minute))
+	for i := 0; i < 2; i++ {
+		wantErr(t, "ConfirmUser(expired)", s.ConfirmUser(ctx, "hash-carol", at.Add(time.Minute)), storage.ErrTokenExpired)
+	}
+	if record, err := s.GetUser(ctx, carol.ID); err != nil || record.Confirmed {
+		t.Errorf("carol after an expired token: %+v, %v", record, err)
+	}
+}
@@ -0,0 +1,162 @@
+package storagetest
+
+import (
+	"testing"
+	"time"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+func testUsers(t *testing.T, s storage.Store) {
+	alice := createUser(t, s, "alice")
+	if alice.ID == 0 {
+		t.Fatal("CreateUser did not set the id")
+	}
+
+	record, err := s.GetUserByUsername(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.ID != alice.ID || record.PasswordHash != "hash-alice" || record.Role != storage.DefaultRole ||
+		record.Locale != storage.DefaultLocale || record.Confirmed || record.PasswordChangedAt != nil {
+		t.Errorf("GetUserByUsername = %+v", record)
+	}
+	if record.Password != "" {
+		t.Error("plain password stored")
+	}
+
+	_, err = s.GetUserByUsername(ctx, "nobody")
+	wantErr(t, "GetUserByUsername(nobody)", err, storage.ErrNotFound)
+	_, err = s.GetUser(ctx, alice.ID+100)
+	wantErr(t, "GetUser(missing)", err, storage.ErrNotFound)
+
+	exists := []struct {
+		name  string
+		check func() (bool, error)
+		want  bool
+	}{
+		{"username exists", func() (bool, error) { return s.UsernameExists(ctx, "alice") }, true},
+		{"unknown username", func() (bool, error) { return s.UsernameExists(ctx, "bob") }, false},
+		// Адрес неподтверждённого пользователя ещё свободен
+		{"unconfirmed email", func() (bool, error) { return s.ConfirmedEmailExists(ctx, "alice@example.com") }, false},
+	}
+	for _, tt := range exists {
+		got, err := tt.check()
+		if err != nil || got != tt.want {
+			t.Errorf("%s: %v, %v, want %v", tt.name, got, err, tt.want)
+		}
+	}
+
+	changedAt := now()
+	if err := s.UpdatePassword(ctx, alice.ID, "new-hash", changedAt); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.SetRole(ctx, alice.ID, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetLocale(ctx, alice.ID, "ru"); err != nil {
+		t.Fatal(err)
+	}
+	record, err = s.GetUser(ctx, alice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.PasswordHash != "new-hash" || record.PasswordChangedAt == nil || !record.PasswordChangedAt.Equal(changedAt) ||
+		record.Role != "admin" || record.Locale != "ru" {
+		t.Errorf("after updates GetUser = %+v", record)
+	}
+
+	wantErr(t, "UpdatePassword(missing)", s.UpdatePassword(ctx, alice.ID+100, "x", changedAt), storage.ErrNotFound)
+	_, err = s.SetRole(ctx, alice.ID+100, "admin")
+	wantErr(t, "SetRole(missing)", err, storage.ErrNotFound)
+
+	createUser(t, s, "bob")
+	users, err := s.ListUsers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users[0].Username != "alice" || users[1].Username != "bob" {
+		t.Errorf("ListUsers = %+v", users)
+	}
+}
+
+func testSessions(t *testing.T, s storage.Store) {
+	alice := createUser(t, s, "alice")
+	start := now()
+
+	session := storage.Session{UserID: alice.ID, UserAgent: "laptop", IP: "192.0.2.1", ExpiresAt: start.Add(time.Hour)}
+	if err := s.CreateSession(ctx, &session, "refresh-1"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.GetSession(ctx, session.ID, alice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.UserAgent != "laptop" || got.IP != "192.0.2.1" || got.RevokedAt != nil || !got.ExpiresAt.Equal(start.Add(time.Hour)) {
+		t.Errorf("GetSession = %+v", got)
+	}
+	_, err = s.GetSession(ctx, session.ID, alice.ID+1)
+	wantErr(t, "GetSession of another user", err, storage.ErrNotFound)
+
+	rotated, user, err := s.RotateRefreshToken(ctx, "refresh-1", "refresh-2", start.Add(2*time.Hour), "192.0.2.2", "phone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated.ID != session.ID || rotated.IP != "192.0.2.2" || rotated.UserAgent != "phone" || user.Username != "alice" {
+		t.Errorf("RotateRefreshToken = %+v, %+v", rotated, user)
+	}
+
+	steps := []struct {
+		name     string
+		old, new string
+		want     error
+	}{
+		{"unknown token", "refresh-x", "refresh-y", storage.ErrNotFound},
+		{"reuse", "refresh-1", "refresh-3", storage.ErrTokenReused},
+		// Повторное предъявление отозвало сессию, и её последний токен больше не действует
+		{"token of a revoked session", "refresh-2", "refresh-4", storage.ErrTokenExpired},
+	}
+	for _, step := range steps {
+		_, _, err := s.RotateRefreshToken(ctx, step.old, step.new, start.Add(2*time.Hour), "", "")
+		wantErr(t, step.name, err, step.want)
+	}
+
+	got, err = s.GetSession(ctx, session.ID, alice.ID)
+	if err != nil || got.RevokedAt == nil {
+		t.Errorf("session after reuse = %+v, %v, want revoked", got, err)
+	}
+
+	expired := storage.Session{UserID: alice.ID, ExpiresAt: start.Add(-time.Minute)}
+	if err := s.CreateSession(ctx, &expired, "refresh-expired"); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = s.RotateRefreshToken(ctx, "refresh-expired", "refresh-5", start.Add(time.Hour), "", "")
+	wantErr(t, "expired token", err, storage.ErrTokenExpired)
+
+	var active []int
+	for i := 0; i < 3; i++ {
+		session := storage.Session{UserID: alice.ID, ExpiresAt: start.Add(time.Hour)}
+		if err := s.CreateSession(ctx, &session, "refresh-active-"+string(rune('a'+i))); err != nil {
+			t.Fatal(err)
+		}
+		active = append(active, session.ID)
+	}
+	sessions, err := s.ListActiveSessions(ctx, alice.ID, start)
+	if err != nil || len(sessions) != 3 {
+		t.Fatalf("ListActiveSessions = %+v, %v, want 3 sessions", sessions, err)
+	}
+
+	if err := s.RevokeSession(ctx, active[0], alice.ID, start); err != nil {
+		t.Fatal(err)
+	}
+	wantErr(t, "RevokeSession twice", s.RevokeSession(ctx, active[0], alice.ID, start), storage.ErrNotFound)
+	wantErr(t, "RevokeSession of another user", s.RevokeSession(ctx, active[1], alice.ID+1, start), storage.ErrNotFound)
+
+	if err := s.RevokeUserSessions(ctx, alice.ID, start); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err = s.ListActiveSessions(ctx, alice.ID, start)
+	if err != nil || len(sessions) != 0 {
+		t.Errorf("ListActiveSessions after RevokeUserSessions = %+v, %v", sessions, err)
+	}
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Ограничения тегов: длина имени (tags.name VARCHAR(50)) и число тегов у алгоритма
+const (
+	MaxTagLength     = 50
+	MaxAlgorithmTags = 10
+)
+
+// Режим фильтра AlgorithmFilter.Tags: все теги сразу или хотя бы один
+const (
+	TagsAll = "all"
+	TagsAny = "any"
+)
+
+// NormalizeTag приводит тег к каноническому написанию: нижний регистр, без ведущей «#»,
+// пробелы и подчёркивания заменены дефисами. Допустимы буквы, цифры и «-+#.», чтобы
+// писались теги вроде c++, c# и node.js. ok ложно, если после нормализации ничего не осталось,
+// встретился другой символ или имя длиннее MaxTagLength.
+func NormalizeTag(tag string) (string, bool) {
+	tag = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(tag)), "#")
+
+	var b strings.Builder
+	for _, r := range tag {
+		switch {
+		case unicode.IsSpace(r) || r == '_' || r == '-':
+			// Подряд идущие разделители схлопываются в один дефис
+			if !strings.HasSuffix(b.String(), "-") {
+				b.WriteRune('-')
+			}
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("+#.", r):
+			b.WriteRune(r)
+		default:
+			return "", false
+		}
+	}
+
+	tag = strings.Trim(b.String(), "-")
+	if tag == "" || utf8.RuneCountInString(tag) > MaxTagLength {
+		return "", false
+	}
+	return tag, true
+}
@@ -0,0 +1,48 @@
+package storage
+
+import "time"
+
+// Способ сравнения вывода программы с ожидаемым: точное совпадение (без учёта \r и переводов
+// строк в конце), совпадение слов без учёта пробелов или сравнение чисел с допуском Tolerance
+const (
+	CompareExact      = "exact"
+	CompareWhitespace = "whitespace"
+	CompareFloat      = "float"
+)
+
+// Ограничения тестов: число тестов у алгоритма и длина входа и ожидаемого вывода
+const (
+	MaxTestCases      = 50
+	MaxTestCaseLength = 64 << 10
+	// Допуск сравнения чисел по умолчанию, абсолютный и относительный одновременно
+	DefaultTolerance = 1e-6
+)
+
+// Итог прогона тестов ревизии
+const (
+	VerificationPassed = "passed"
+	VerificationFailed = "failed"
+)
+
+// Таблица algorithm_test_cases: вход и ожидаемый вывод программы. Скрытые тесты видят
+// только автор и модераторы, чтобы код нельзя было подогнать под ответы.
+type TestCase struct {
+	ID             int       `json:"id"`
+	AlgorithmID    int       `json:"algorithm_id"`
+	Input          string    `json:"input"`
+	ExpectedOutput string    `json:"expected_output"`
+	Hidden         bool      `json:"hidden"`
+	Comparison     string    `json:"comparison"`
+	Tolerance      float64   `json:"tolerance,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Таблица algorithm_verifications: результат прогона всех тестов на коде ревизии
+type Verification struct {
+	AlgorithmID int       `json:"algorithm_id"`
+	Revision    int       `json:"revision"`
+	Status      string    `json:"status"`
+	Passed      int       `json:"passed"`
+	Total       int       `json:"total"`
+	VerifiedAt  time.Time `json:"verified_at"`
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// Размер выдачи автодополнения и облака тегов по умолчанию и наибольший
+const (
+	defaultAutocompleteLimit = 10
+	maxAutocompleteLimit     = 50
+	defaultTagCloudLimit     = 50
+	maxTagCloudLimit         = 200
+)
+
+// normalizeTags приводит теги к каноническому написанию и убирает повторы
+func normalizeTags(tags []string) ([]string, error) {
+	seen := map[string]bool{}
+	var normalized []string = []string{}
+	for _, tag := range tags {
+		name, ok := storage.NormalizeTag(tag)
+		if !ok {
+			return nil, fmt.Errorf("Invalid tag %q: use up to %d letters, digits and -+#.", tag, storage.MaxTagLength)
+		}
+		if !seen[name] {
+			seen[name] = true
+			normalized = append(normalized, name)
+		}
+	}
+	return normalized, nil
+}
+
+// algorithmTags проверяет теги алгоритма из запроса. nil остаётся nil: при изменении
+// алгоритма это значит «теги не менять».
+func algorithmTags(tags []string) ([]string, error) {
+	if tags == nil {
+		return nil, nil
+	}
+	normalized, err := normalizeTags(tags)
+	if err == nil && len(normalized) > storage.MaxAlgorithmTags {
+		err = fmt.Errorf("An algorithm can have at most %d tags", storage.MaxAlgorithmTags)
+	}
+	return normalized, err
+}
+
+// parseLimit читает параметр limit с значением по умолчанию и верхней границей
+func parseLimit(r *http.Request, def, max int) (int, error) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return def, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > max {
+		return 0, fmt.Errorf("limit must be between 1 and %d", max)
+	}
+	return limit, nil
+}
+
+// GetTagAutocomplete подсказывает теги по началу имени или синонима, самые частые первыми
+func GetTagAutocomplete(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseLimit(r, defaultAutocompleteLimit, maxAutocompleteLimit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	prefix := r.URL.Query().Get("prefix")
+	if strings.TrimSpace(prefix) != "" {
+		normalized, ok := storage.NormalizeTag(prefix)
+		if !ok {
+			// Такой тег не мог быть создан, подсказывать нечего
+			json.NewEncoder(w).Encode([]storage.TagCount{})
+			return
+		}
+		prefix = normalized
+	}
+
+	tags, err := store.TagCounts(r.Context(), prefix, limit)
+	if err != nil {
+		http.Error(w, "Error fetching tags", http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(tags)
+}
+
+// GetTagCloud возвращает самые частые теги по алфавиту с весом от 1 до 5.
+// Вес растёт логарифмически, чтобы один популярный тег не делал остальные одинаково мелкими.
+func GetTagCloud(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseLimit(r, defaultTagCloudLimit, maxTagCloudLimit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tags, err := store.TagCounts(r.Context(), "", limit)
+	if err != nil {
+		http.Error(w, "Error fetching tags", http.StatusInternalServerError)
+		return
+	}
+
+	if len(tags) > 0 {
+		// TagCounts отдаёт теги от частых к редким
+		high, low := math.Log(float64(tags[0].Count)), math.Log(float64(tags[len(tags)-1].Count))
+		for i := range tags {
+			tags[i].Weight = 3
+			if high > low {
+				tags[i].Weight = 1 + int(math.Round(4*(math.Log(float64(tags[i].Count))-low)/(high-low)))
+			}
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	json.NewEncoder(w).Encode(tags)
+}
+
+// AddTagSynonym делает тег из тела запроса синонимом {tag}. Если синоним уже был самостоятельным
+// тегом, его алгоритмы переходят к {tag}.
+func AddTagSynonym(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Synonym string `json:"synonym"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	names, err := normalizeTags([]string{mux.Vars(r)["tag"], request.Synonym})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(names) < 2 {
+		http.Error(w, "A tag cannot be a synonym of itself", http.StatusBadRequest)
+		return
+	}
+
+	err = store.AddTagSynonym(r.Context(), names[0], names[1])
+	if err == storage.ErrDuplicate {
+		http.Error(w, "A tag cannot be a synonym of itself", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveTagSynonym удаляет синоним; алгоритмы, отмеченные основным тегом, не меняются
+func RemoveTagSynonym(w http.ResponseWriter, r *http.Request) {
+	name, ok := storage.NormalizeTag(mux.Vars(r)["synonym"])
+	if !ok {
+		http.Error(w, "Synonym not found", http.StatusNotFound)
+		return
+	}
+
+	err := store.RemoveTagSynonym(r.Context(), name)
+	if err == storage.ErrNotFound {
+		http.Error(w, "Synonym not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// testCaseRequest — тело POST и PUT теста. Без comparison сравнение точное, а для float
+// без tolerance берётся storage.DefaultTolerance.
+type testCaseRequest struct {
+	Input          string  `json:"input"`
+	ExpectedOutput string  `json:"expected_output"`
+	Hidden         bool    `json:"hidden"`
+	Comparison     string  `json:"comparison"`
+	Tolerance      float64 `json:"tolerance"`
+}
+
+func (request testCaseRequest) testCase(algorithmID int) (storage.TestCase, error) {
+	testCase := storage.TestCase{
+		AlgorithmID:    algorithmID,
+		Input:          request.Input,
+		ExpectedOutput: request.ExpectedOutput,
+		Hidden:         request.Hidden,
+		Comparison:     request.Comparison,
+		Tolerance:      request.Tolerance,
+	}
+	if len(testCase.Input) > storage.MaxTestCaseLength || len(testCase.ExpectedOutput) > storage.MaxTestCaseLength {
+		return testCase, fmt.Errorf("Input and expected output must be at most %d bytes", storage.MaxTestCaseLength)
+	}
+	switch testCase.Comparison {
+	case "":
+		testCase.Comparison = storage.CompareExact
+		fallthrough
+	case storage.CompareExact, storage.CompareWhitespace:
+		if testCase.Tolerance != 0 {
+			return testCase, fmt.Errorf("tolerance is only allowed with float comparison")
+		}
+	case storage.CompareFloat:
+		if testCase.Tolerance < 0 || math.IsInf(testCase.Tolerance, 0) || math.IsNaN(testCase.Tolerance) {
+			return testCase, fmt.Errorf("tolerance must be a non-negative number")
+		}
+		if testCase.Tolerance == 0 {
+			testCase.Tolerance = storage.DefaultTolerance
+		}
+	default:
+		return testCase, fmt.Errorf("comparison must be exact, whitespace or float")
+	}
+	return testCase, nil
+}
+
+// testCaseAlgorithm разбирает {id} и проверяет, что пользователь может менять тесты алгоритма:
+// это автор и те, кому можно управлять любыми алгоритмами. При отказе ответ уже записан в w.
+func testCaseAlgorithm(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid ID parameter", http.StatusBadRequest)
+		return 0, false
+	}
+
+	userID := r.Context().Value("userID").(int)
+	allowed, found, err := canManageAlgorithm(r.Context(), userID, roleFromContext(r), id, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return 0, false
+	}
+	if !found {
+		http.Error(w, "Algorithm not found", http.StatusNotFound)
+		return 0, false
+	}
+	if !allowed {
+		forbidden(w)
+		return 0, false
+	}
+	return id, true
+}
+
+// canSeeHiddenTests — скрытые тесты видят автор и модераторы
+func canSeeHiddenTests(r *http.Request, algorithm storage.Algorithm) bool {
+	return algorithm.UserID == r.Context().Value("userID").(int) || hasPermission(roleFromContext(r), permModerateAlgorithms)
+}
+
+// GetTestCases возвращает тесты алгоритма. Остальным пользователям скрытые тесты не показываются,
+// только их число.
+func GetTestCases(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid ID parameter", http.StatusBadRequest)
+		return
+	}
+
+	userID := r.Context().Value("userID").(int)
+	visible, err := canViewAlgorithm(r.Context(), userID, roleFromContext(r), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !visible {
+		http.Error(w, "Algorithm not found", http.StatusNotFound)
+		return
+	}
+	algorithm, err := store.GetAlgorithm(r.Context(), id, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	testCases, err := store.ListTestCases(r.Context(), id, true)
+	if err != nil {
+		http.Error(w, "Error fetching test cases", http.StatusInternalServerError)
+		return
+	}
+	showHidden := canSeeHiddenTests(r, algorithm)
+	response := struct {
+		TestCases   []storage.TestCase `json:"test_cases"`
+		HiddenCount int                `json:"hidden_count"`
+	}{TestCases: []storage.TestCase{}}
+	for _, testCase := range testCases {
+		if testCase.Hidden {
+			response.HiddenCount++
+			if !showHidden {
+				continue
+			}
+		}
+		response.TestCases = append(response.TestCases, testCase)
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreateTestCase добавляет тест и ставит алгоритм в очередь на проверку
+func CreateTestCase(w http.ResponseWriter, r *http.Request) {
+	var request testCaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	id, ok := testCaseAlgorithm(w, r)
+	if !ok {
+		return
+	}
+	testCase, err := request.testCase(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	existing, err := store.ListTestCases(r.Context(), id, true)
+	if err != nil {
+		http.Error(w, "Error fetching test cases", http.StatusInternalServerError)
+		return
+	}
+	if len(existing) >= storage.MaxTestCases {
+		http.Error(w, fmt.Sprintf("An algorithm can have at most %d test cases", storage.MaxTestCases), http.StatusConflict)
+		return
+	}
+
+	if err := store.CreateTestCase(r.Context(), &testCase); err != nil {
+		http.Error(w, "Error creating test case", http.StatusInternalServerError)
+		return
+	}
+	scheduleVerification(id)
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(testCase)
+}
+
+// UpdateTestCase заменяет тест целиком и ставит алгоритм в очередь на проверку
+func UpdateTestCase(w http.ResponseWriter, r *http.Request) {
+	var request testCaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	testCaseID, err := strconv.Atoi(mux.Vars(r)["testID"])
+	if err != nil {
+		http.Error(w, "Invalid test case ID", http.StatusBadRequest)
+		return
+	}
+
+	id, ok := testCaseAlgorithm(w, r)
+	if !ok {
+		return
+	}
+	testCase, err := request.testCase(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	testCase.ID = testCaseID
+
+	err = store.UpdateTestCase(r.Context(), &testCase)
+	if err == storage.ErrNotFound {
+		http.Error(w, "Test case not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error updating test case", http.StatusInternalServerError)
+		return
+	}
+	scheduleVerification(id)
+
+	json.NewEncoder(w).Encode(testCase)
+}
+
+// DeleteTestCase удаляет тест и ставит алгоритм в очередь на проверку
+func DeleteTestCase(w http.ResponseWriter, r *http.Request) {
+	testCaseID, err := strconv.Atoi(mux.Vars(r)["testID"])
+	if err != nil {
+		http.Error(w, "Invalid test case ID", http.StatusBadRequest)
+		return
+	}
+
+	id, ok := testCaseAlgorithm(w, r)
+	if !ok {
+		return
+	}
+
+	err = store.DeleteTestCase(r.Context(), id, testCaseID)
+	if err == storage.ErrNotFound {
+		http.Error(w, "Test case not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error deleting test case", http.StatusInternalServerError)
+		return
+	}
+	scheduleVerification(id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// Удалённые алгоритмы хранятся в корзине cfg.Algorithms.TrashRetention, после чего удаляются окончательно
+const trashPurgeInterval = time.Hour
+
+// canManageAlgorithm проверяет, может ли пользователь управлять алгоритмом: он его автор или его роль
+// позволяет управлять любыми алгоритмами. found равен false, если такого алгоритма нет вовсе.
+func canManageAlgorithm(ctx context.Context, userID int, role string, algorithmID int, deleted bool) (allowed bool, found bool, err error) {
+	algorithm, err := store.GetAlgorithm(ctx, algorithmID, deleted)
+	if err == storage.ErrNotFound {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+
+	return algorithm.UserID == userID || hasPermission(role, permManageAnyAlgorithm), true, nil
+}
+
+// DeleteAlgorithm перемещает алгоритм в корзину (soft delete)
+func DeleteAlgorithm(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid ID parameter", http.StatusBadRequest)
+		return
+	}
+
+	userID := r.Context().Value("userID").(int)
+
+	allowed, found, err := canManageAlgorithm(r.Context(), userID, roleFromContext(r), id, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Algorithm not found", http.StatusNotFound)
+		return
+	}
+	if !allowed {
+		forbidden(w)
+		return
+	}
+
+	deletedAt := time.Now()
+	err = store.TrashAlgorithm(r.Context(), id, deletedAt)
+	if err == storage.ErrNotFound {
+		http.Error(w, "Algorithm not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":    "Algorithm moved to trash",
+		"deleted_at": deletedAt,
+		"purge_at":   deletedAt.Add(time.Duration(cfg.Algorithms.TrashRetention)),
+	})
+}
+
+// GetTrash возвращает удалённые алгоритмы пользователя; админ видит корзину всех пользователей
+func GetTrash(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+
+	if hasPermission(roleFromContext(r), permManageAnyAlgorithm) {
+		userID = 0
+	}
+
+	algorithms, err := store.ListTrash(r.Context(), userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	type trashedAlgorithm struct {
+		Algorithm
+		PurgeAt time.Time `json:"purge_at"`
+	}
+
+	var trash []trashedAlgorithm = []trashedAlgorithm{}
+	for _, algorithm := range algorithms {
+		trash = append(trash, trashedAlgorithm{algorithm, algorithm.DeletedAt.Add(time.Duration(cfg.Algorithms.TrashRetention))})
+	}
+
+	json.NewEncoder(w).Encode(trash)
+}
+
+// RestoreAlgorithm возвращает алгоритм из корзины
+func RestoreAlgorithm(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid ID parameter", http.StatusBadRequest)
+		return
+	}
+
+	userID := r.Context().Value("userID").(int)
+
+	allowed, found, err := canManageAlgorithm(r.Context(), userID, roleFromContext(r), id, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Algorithm not found in trash", http.StatusNotFound)
+		return
+	}
+	if !allowed {
+		forbidden(w)
+		return
+	}
+
+	algorithm, err := store.RestoreAlgorithm(r.Context(), id)
+	if err == storage.ErrNotFound {
+		http.Error(w, "Algorithm not found in trash", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(algorithm)
+}
+
+// purgeTrash окончательно удаляет алгоритмы, пролежавшие в корзине дольше срока хранения
+func purgeTrash() {
+	purged, err := store.PurgeTrash(context.Background(), time.Now().Add(-time.Duration(cfg.Algorithms.TrashRetention)))
+	if err != nil {
+		log.Println("Failed to purge trash:", err)
+		return
+	}
+
+	if purged > 0 {
+		log.Println("Purged algorithms from trash:", purged)
+	}
+}
+
+func startTrashPurger() {
+	go func() {
+		purgeTrash()
+		ticker := time.NewTicker(trashPurgeInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			purgeTrash()
+		}
+	}()
+}
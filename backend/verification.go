@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"AlgorithmsOnlineLibrary/sandbox"
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// Фоновая проверка: id алгоритмов, у которых изменились код или тесты. Один обработчик
+// очереди занимает не больше одного места в пуле песочницы, запускам из API остаются остальные.
+const (
+	verificationQueueSize = 100
+	verificationRetries   = 5
+)
+
+var verificationQueue = make(chan int, verificationQueueSize)
+
+// errRunnerDisabled — проверять нечем: запуск кода отключён
+var errRunnerDisabled = errors.New("Running code is disabled")
+
+// testResult — итог одного теста. Вывод программы отдаётся только тем, кто видит скрытые тесты,
+// потому что проверку запускают только они.
+type testResult struct {
+	TestCaseID int    `json:"test_case_id"`
+	Hidden     bool   `json:"hidden"`
+	Passed     bool   `json:"passed"`
+	Status     string `json:"status"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr,omitempty"`
+	TimeMS     int64  `json:"time_ms"`
+}
+
+// scheduleVerification ставит алгоритм в очередь фоновой проверки; при переполненной очереди
+// проверка пропускается, её можно запустить вручную
+func scheduleVerification(algorithmID int) {
+	if runner == nil {
+		return
+	}
+	select {
+	case verificationQueue <- algorithmID:
+	default:
+		log.Printf("Verification queue is full, algorithm %d is not verified", algorithmID)
+	}
+}
+
+func startVerifier() {
+	go func() {
+		for algorithmID := range verificationQueue {
+			verifyInBackground(algorithmID)
+		}
+	}()
+}
+
+func verifyInBackground(algorithmID int) {
+	for attempt := 1; ; attempt++ {
+		_, _, err := verifyAlgorithm(context.Background(), algorithmID)
+		switch {
+		case errors.Is(err, sandbox.ErrBusy) && attempt < verificationRetries:
+			time.Sleep(time.Duration(attempt) * 2 * time.Second)
+			continue
+		case err == nil, err == storage.ErrNotFound, errors.Is(err, sandbox.ErrUnsupported), err == errRunnerDisabled:
+		default:
+			log.Printf("Error verifying algorithm %d: %v", algorithmID, err)
+		}
+		return
+	}
+}
+
+// verifyAlgorithm прогоняет все тесты на коде последней ревизии и сохраняет итог. Код берётся
+// из самой ревизии, а не из алгоритма, чтобы итог не приписался ревизии, которую не проверяли.
+func verifyAlgorithm(ctx context.Context, algorithmID int) (storage.Verification, []testResult, error) {
+	if runner == nil {
+		return storage.Verification{}, nil, errRunnerDisabled
+	}
+
+	verification := storage.Verification{AlgorithmID: algorithmID}
+	revision, code, language, err := latestCode(ctx, algorithmID)
+	if err != nil {
+		return verification, nil, err
+	}
+	verification.Revision = revision
+
+	testCases, err := store.ListTestCases(ctx, algorithmID, true)
+	if err != nil {
+		return verification, nil, err
+	}
+	results := []testResult{}
+	if len(testCases) > 0 {
+		stdins := make([]string, len(testCases))
+		for i, testCase := range testCases {
+			stdins[i] = testCase.Input
+		}
+		runs, err := runner.RunBatch(ctx, language, code, stdins)
+		if err != nil {
+			return verification, nil, err
+		}
+		for i, testCase := range testCases {
+			result := testResult{
+				TestCaseID: testCase.ID,
+				Hidden:     testCase.Hidden,
+				Status:     runs[i].Status,
+				Stdout:     runs[i].Stdout,
+				Stderr:     runs[i].Stderr,
+				TimeMS:     runs[i].Usage.WallTimeMS,
+			}
+			if runs[i].Status == sandbox.StatusCompileError {
+				result.Stderr = runs[i].CompileOutput
+			}
+			result.Passed = runs[i].Status == sandbox.StatusOK && !runs[i].StdoutTruncated && outputMatches(testCase, runs[i].Stdout)
+			if result.Passed {
+				verification.Passed++
+			}
+			results = append(results, result)
+		}
+	}
+
+	verification.Total = len(testCases)
+	switch {
+	case verification.Total == 0:
+	case verification.Passed == verification.Total:
+		verification.Status = storage.VerificationPassed
+	default:
+		verification.Status = storage.VerificationFailed
+	}
+	verification.VerifiedAt = time.Now().UTC()
+	if err := store.SaveVerification(ctx, verification); err != nil {
+		return verification, nil, err
+	}
+	return verification, results, nil
+}
+
+// latestCode возвращает код последней ревизии и её номер. Алгоритмы, созданные до истории
+// ревизий, получают ревизию 0 и текущий код.
+func latestCode(ctx context.Context, algorithmID int) (revision int, code, language string, err error) {
+	revisions, err := store.ListRevisions(ctx, algorithmID)
+	if err != nil {
+		return 0, "", "", err
+	}
+	if len(revisions) > 0 {
+		latest, err := store.GetRevision(ctx, algorithmID, revisions[0].Revision)
+		if err != nil {
+			return 0, "", "", err
+		}
+		return latest.Revision, latest.Code, latest.ProgrammingLanguage, nil
+	}
+	algorithm, err := store.GetAlgorithm(ctx, algorithmID, false)
+	if err != nil {
+		return 0, "", "", err
+	}
+	return 0, algorithm.Code, algorithm.ProgrammingLanguage, nil
+}
+
+// outputMatches сравнивает вывод программы с ожидаемым способом, заданным в тесте
+func outputMatches(testCase storage.TestCase, actual string) bool {
+	expected := testCase.ExpectedOutput
+	switch testCase.Comparison {
+	case storage.CompareWhitespace:
+		return strings.Join(strings.Fields(expected), " ") == strings.Join(strings.Fields(actual), " ")
+	case storage.CompareFloat:
+		want, got := strings.Fields(expected), strings.Fields(actual)
+		if len(want) != len(got) {
+			return false
+		}
+		for i := range want {
+			if !tokensMatch(want[i], got[i], testCase.Tolerance) {
+				return false
+			}
+		}
+		return true
+	default:
+		// Переводы строк Windows и лишние переводы строк в конце не считаются отличием
+		normalize := func(s string) string {
+			return strings.TrimRight(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+		}
+		return normalize(expected) == normalize(actual)
+	}
+}
+
+// tokensMatch сравнивает числа с абсолютным или относительным допуском, остальное — как строки
+func tokensMatch(want, got string, tolerance float64) bool {
+	a, errA := strconv.ParseFloat(want, 64)
+	b, errB := strconv.ParseFloat(got, 64)
+	if errA != nil || errB != nil || math.IsNaN(a) || math.IsNaN(b) {
+		return want == got
+	}
+	diff := math.Abs(a - b)
+	return diff <= tolerance || diff <= tolerance*math.Abs(a)
+}
+
+// VerifyAlgorithm сразу прогоняет тесты и возвращает итог по каждому. Доступно тем, кто видит
+// скрытые тесты: автору и модераторам.
+func VerifyAlgorithm(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid ID parameter", http.StatusBadRequest)
+		return
+	}
+
+	algorithm, err := store.GetAlgorithm(r.Context(), id, false)
+	if err == storage.ErrNotFound {
+		http.Error(w, "Algorithm not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	userID := r.Context().Value("userID").(int)
+	if !canSeeHiddenTests(r, algorithm) {
+		if algorithm.Approved {
+			forbidden(w)
+		} else {
+			http.Error(w, "Algorithm not found", http.StatusNotFound)
+		}
+		return
+	}
+
+	verification, results, err := verifyAlgorithm(r.Context(), id)
+	switch {
+	case err == errRunnerDisabled:
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	case errors.Is(err, sandbox.ErrUnsupported):
+		http.Error(w, "Running "+algorithm.ProgrammingLanguage+" code is not supported", http.StatusUnprocessableEntity)
+		return
+	case errors.Is(err, sandbox.ErrBusy):
+		w.Header().Set("Retry-After", "5")
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	case r.Context().Err() != nil:
+		return
+	case err != nil:
+		log.Printf("Error verifying algorithm %d for user %d: %v", id, userID, err)
+		http.Error(w, "Failed to verify the algorithm", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(struct {
+		storage.Verification
+		Results []testResult `json:"results"`
+	}{verification, results})
+}
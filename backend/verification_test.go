@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"AlgorithmsOnlineLibrary/storage"
+)
+
+// verificationLinks отправляет письма из очереди и возвращает токены из всех писем подтверждения
+// на адрес, начиная с последнего
+func verificationLinks(t *testing.T, recipient string) []string {
+	t.Helper()
+	deliverDueEmails()
+	var tokens []string
+	for _, message := range devMailbox.Messages(recipient) {
+		if match := verifyLinkToken.FindStringSubmatch(message.Text); match != nil {
+			tokens = append(tokens, match[1])
+		}
+	}
+	return tokens
+}
+
+func verify(token string) int {
+	rec := httptest.NewRecorder()
+	VerifyEmail(rec, httptest.NewRequest("GET", "/verify-email?token="+url.QueryEscape(token), nil))
+	return rec.Code
+}
+
+func TestVerifyEmail(t *testing.T) {
+	for _, driver := range testDrivers {
+		setupTest(t)
+		useStore(t, driver)
+		ctx := context.Background()
+
+		body := `{"username": "alice", "password": "secret-password", "email": "alice@example.com"}`
+		rec := httptest.NewRecorder()
+		Register(rec, httptest.NewRequest("POST", "/register", strings.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: register: %d %s", driver, rec.Code, rec.Body)
+		}
+		links := verificationLinks(t, "alice@example.com")
+		if len(links) != 1 {
+			t.Fatalf("%s: got %d verification links, want 1", driver, len(links))
+		}
+		token := links[0]
+
+		// В базе только хеш: сам токен из письма ключом не служит
+		if err := store.ConfirmUser(ctx, token, time.Now()); err != storage.ErrNotFound {
+			t.Errorf("%s: ConfirmUser with the raw token: %v, want ErrNotFound", driver, err)
+		}
+
+		expiredHash := hashToken("expired-token")
+		expired := User{Username: "bob", Email: "bob@example.com"}
+		err := store.RegisterUser(ctx, &expired, "hash", expiredHash, time.Now().Add(-time.Minute),
+			&storage.OutboxEmail{Kind: storage.EmailVerification, Recipient: expired.Email})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			name     string
+			token    string
+			wantCode int
+		}{
+			{"unknown", "0123456789abcdef", http.StatusBadRequest},
+			{"hash instead of token", hashToken(token), http.StatusBadRequest},
+			{"valid", token, http.StatusOK},
+			{"reused", token, http.StatusConflict},
+			{"expired", "expired-token", http.StatusGone},
+		}
+		for _, tt := range tests {
+			if code := verify(tt.token); code != tt.wantCode {
+				t.Errorf("%s, %s: got %d, want %d", driver, tt.name, code, tt.wantCode)
+			}
+		}
+
+		user, err := store.GetUserByUsername(ctx, "alice")
+		if err != nil || !user.Confirmed {
+			t.Errorf("%s: alice after verification: %+v, %v", driver, user, err)
+		}
+	}
+}
+
+func TestResendVerificationReplacesLink(t *testing.T) {
+	for _, driver := range testDrivers {
+		setupTest(t)
+		useStore(t, driver)
+		cfg.Auth.VerificationResendInterval = 0
+
+		body := `{"username": "alice", "password": "secret-password", "email": "alice@example.com"}`
+		rec := httptest.NewRecorder()
+		Register(rec, httptest.NewRequest("POST", "/register", strings.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: register: %d %s", driver, rec.Code, rec.Body)
+		}
+		rec = httptest.NewRecorder()
+		ResendVerification(rec, httptest.NewRequest("POST", "/resend-verification", strings.NewReader(`{"username": "alice"}`)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: resend: %d %s", driver, rec.Code, rec.Body)
+		}
+
+		links := verificationLinks(t, "alice@example.com")
+		if len(links) != 2 || links[0] == links[1] {
+			t.Fatalf("%s: got links %v, want two different ones", driver, links)
+		}
+		if code := verify(links[1]); code != http.StatusBadRequest {
+			t.Errorf("%s: replaced link: got %d, want %d", driver, code, http.StatusBadRequest)
+		}
+		if code := verify(links[0]); code != http.StatusOK {
+			t.Errorf("%s: new link: got %d, want %d", driver, code, http.StatusOK)
+		}
+	}
+}
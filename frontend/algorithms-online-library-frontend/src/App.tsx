@@ -10,6 +10,8 @@ import Footer from './components/Footer';
 import MyAlgorithmsPage from './pages/MyAlgorithmsPage';
 import AddAlgorithmPage from "./pages/AddAlgorithmPage";
 import ResetPasswordPage from "./pages/ResetPasswordPage";
+import BenchmarksPage from "./pages/BenchmarksPage";
+import BenchmarkPage from "./pages/BenchmarkPage";
 
 
 const App: React.FC = () => {
@@ -26,6 +28,8 @@ const App: React.FC = () => {
                     <Route path="/my-algorithms" element={<MyAlgorithmsPage />} />
                     <Route path="/add-algorithm" element={<AddAlgorithmPage />} />
                     <Route path="/reset-password" element={<ResetPasswordPage />} />
+                    <Route path="/benchmarks" element={<BenchmarksPage />} />
+                    <Route path="/benchmarks/:id" element={<BenchmarkPage />} />
                 </Routes>
                 <Footer />
             </div>
@@ -23,6 +23,9 @@ const Header: React.FC = () => {
                         <li className="nav-item">
                             <Link className="nav-link" to="/add-algorithm">Add Algorithm</Link>
                         </li>
+                        <li className="nav-item">
+                            <Link className="nav-link" to="/benchmarks">Benchmarks</Link>
+                        </li>
                     </ul>
                 </div>
             </nav>
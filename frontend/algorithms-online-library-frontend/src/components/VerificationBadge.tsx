@@ -0,0 +1,14 @@
+import React from 'react';
+
+interface VerificationBadgeProps {
+    status: 'passed' | 'failed';
+}
+
+// Shows whether the latest revision of an algorithm passes its test cases
+const VerificationBadge: React.FC<VerificationBadgeProps> = ({ status }) => (
+    <span className={`badge ml-2 ${status === 'passed' ? 'badge-success' : 'badge-danger'}`}>
+        {status === 'passed' ? 'Tests passed' : 'Tests failed'}
+    </span>
+);
+
+export default VerificationBadge;
@@ -1,13 +1,22 @@
 import React, { useEffect, useState } from 'react';
 import { useNavigate } from 'react-router-dom';
 import api from '../services/api';
+import { fetchCategories } from '../services/algorithmService';
+import { Category, categoryOptions } from '../types/Category';
 
 const AddAlgorithmPage: React.FC = () => {
     const [title, setTitle] = useState('');
-    const [topic, setTopic] = useState('');
+    const [categoryID, setCategoryID] = useState('');
+    const [categories, setCategories] = useState<Category[]>([]);
     const [programmingLanguage, setProgrammingLanguage] = useState('');
     const [availableProgrammingLanguages, setAvailableProgrammingLanguages] = useState<string[]>([]);
     const [code, setCode] = useState('');
+    const [tags, setTags] = useState('');
+    const [description, setDescription] = useState('');
+    const [difficulty, setDifficulty] = useState('');
+    const [timeComplexity, setTimeComplexity] = useState('');
+    const [spaceComplexity, setSpaceComplexity] = useState('');
+    const [acceptAnalysis, setAcceptAnalysis] = useState(true);
     const [message, setMessage] = useState('');
     const navigate = useNavigate();
     const token = localStorage.getItem('token');
@@ -27,10 +36,13 @@ const AddAlgorithmPage: React.FC = () => {
         };
 
         fetchAvailableProgrammingLanguages();
+        fetchCategories(token)
+            .then(setCategories)
+            .catch(() => setMessage('Error fetching categories'));
     }, [token]);
 
     const handleSubmit = async () => {
-        if (!title || !topic || !programmingLanguage || !code) {
+        if (!title || !categoryID || !programmingLanguage || !code) {
             setMessage('All fields are required');
             return;
         }
@@ -38,9 +50,15 @@ const AddAlgorithmPage: React.FC = () => {
         try {
             const response = await api.post('/api/algorithms', {
                 title: title,
-                topic: topic,
+                category_id: Number(categoryID),
                 programming_language: programmingLanguage,
-                code: code
+                code: code,
+                tags: tags.split(',').map((tag) => tag.trim()).filter((tag) => tag !== ''),
+                description: description,
+                difficulty: difficulty,
+                time_complexity: timeComplexity,
+                space_complexity: spaceComplexity,
+                accept_analysis: acceptAnalysis
             }, {
                 headers: {
                     Authorization: `Bearer ${token}`
@@ -75,13 +93,18 @@ const AddAlgorithmPage: React.FC = () => {
                 />
             </div>
             <div className="form-group">
-                <input
-                    type="text"
+                <select
                     className="form-control"
-                    placeholder="Topic"
-                    value={topic}
-                    onChange={(e) => setTopic(e.target.value)}
-                />
+                    value={categoryID}
+                    onChange={(e) => setCategoryID(e.target.value)}
+                >
+                    <option value="">Select a category</option>
+                    {categoryOptions(categories).map((option) => (
+                        <option key={option.id} value={option.id}>
+                            {option.label}
+                        </option>
+                    ))}
+                </select>
             </div>
             <div className="form-group">
                 <select
@@ -97,6 +120,53 @@ const AddAlgorithmPage: React.FC = () => {
                     ))}
                 </select>
             </div>
+            <div className="form-group">
+                <input
+                    type="text"
+                    className="form-control"
+                    placeholder="Tags, comma-separated (e.g. dp, bitmask)"
+                    value={tags}
+                    onChange={(e) => setTags(e.target.value)}
+                />
+            </div>
+            <div className="form-group">
+                <textarea
+                    className="form-control"
+                    placeholder="Description (Markdown)"
+                    value={description}
+                    onChange={(e) => setDescription(e.target.value)}
+                />
+            </div>
+            <div className="form-group">
+                <select
+                    className="form-control"
+                    value={difficulty}
+                    onChange={(e) => setDifficulty(e.target.value)}
+                >
+                    <option value="">Difficulty</option>
+                    <option value="easy">Easy</option>
+                    <option value="medium">Medium</option>
+                    <option value="hard">Hard</option>
+                </select>
+            </div>
+            <div className="form-group">
+                <input
+                    type="text"
+                    className="form-control"
+                    placeholder="Time complexity, e.g. O(n log n)"
+                    value={timeComplexity}
+                    onChange={(e) => setTimeComplexity(e.target.value)}
+                />
+            </div>
+            <div className="form-group">
+                <input
+                    type="text"
+                    className="form-control"
+                    placeholder="Space complexity, e.g. O(n)"
+                    value={spaceComplexity}
+                    onChange={(e) => setSpaceComplexity(e.target.value)}
+                />
+            </div>
             <div className="form-group">
                 <textarea
                     className="form-control"
@@ -105,6 +175,18 @@ const AddAlgorithmPage: React.FC = () => {
                     onChange={(e) => setCode(e.target.value)}
                 />
             </div>
+            <div className="form-check mb-3">
+                <input
+                    type="checkbox"
+                    className="form-check-input"
+                    id="acceptAnalysis"
+                    checked={acceptAnalysis}
+                    onChange={(e) => setAcceptAnalysis(e.target.checked)}
+                />
+                <label className="form-check-label" htmlFor="acceptAnalysis">
+                    Fill empty difficulty and complexity from code analysis
+                </label>
+            </div>
             <button className="btn btn-primary" onClick={handleSubmit}>Submit</button>
             {message && <div className="alert alert-danger mt-3">{message}</div>}
         </div>
@@ -1,11 +1,16 @@
 import React, { useEffect, useState } from 'react';
 import { useParams } from 'react-router-dom';
 import api from '../services/api';
-import { Algorithm } from '../types/Algorithm';
+import { Algorithm, RunResult } from '../types/Algorithm';
+import VerificationBadge from '../components/VerificationBadge';
 
 const AlgorithmPage: React.FC = () => {
     const { id } = useParams<{ id: string }>();
     const [algorithm, setAlgorithm] = useState<Algorithm | null>(null);
+    const [stdin, setStdin] = useState('');
+    const [running, setRunning] = useState(false);
+    const [runResult, setRunResult] = useState<RunResult | null>(null);
+    const [runError, setRunError] = useState('');
     const token = localStorage.getItem('token');
 
     useEffect(() => {
@@ -25,19 +30,99 @@ const AlgorithmPage: React.FC = () => {
         fetchAlgorithm();
     }, [id, token]);
 
+    const handleRun = async () => {
+        setRunning(true);
+        setRunError('');
+        setRunResult(null);
+        try {
+            const response = await api.post(`/api/algorithms/${id}/run`, { stdin: stdin }, {
+                headers: {
+                    Authorization: `Bearer ${token}`
+                }
+            });
+            setRunResult(response.data);
+        } catch (error) {
+            setRunError('Error running the code, try again later');
+        } finally {
+            setRunning(false);
+        }
+    };
+
     if (!algorithm) {
         return <div>Loading...</div>;
     }
 
     return (
         <div className="container">
-            <h1 className="my-4">{algorithm.title}</h1>
+            <h1 className="my-4">
+                {algorithm.title}
+                {algorithm.verification_status && <VerificationBadge status={algorithm.verification_status} />}
+            </h1>
             <h2>Algorithm Details</h2>
             <h3>ID: {algorithm.id}</h3>
             <h4>Topic: {algorithm.topic}</h4>
+            {algorithm.tags && algorithm.tags.length > 0 && (
+                <p>
+                    {algorithm.tags.map((tag) => (
+                        <span key={tag} className="badge badge-secondary mr-1">#{tag}</span>
+                    ))}
+                </p>
+            )}
+            {algorithm.difficulty && <p>Difficulty: {algorithm.difficulty}/10</p>}
+            {algorithm.time_complexity && <p>Time complexity: {algorithm.time_complexity}</p>}
+            {algorithm.space_complexity && <p>Space complexity: {algorithm.space_complexity}</p>}
+            {algorithm.analysis && (
+                <p className="text-muted">
+                    Analyzer suggests: difficulty {algorithm.analysis.difficulty}/10,
+                    time {algorithm.analysis.time_complexity}, space {algorithm.analysis.space_complexity}
+                    {algorithm.analysis.data_structures.length > 0 && ` (uses ${algorithm.analysis.data_structures.join(', ')})`}
+                </p>
+            )}
+            {algorithm.prerequisites && algorithm.prerequisites.length > 0 && (
+                <p>
+                    Prerequisites:{' '}
+                    {algorithm.prerequisites.map((prerequisite) => (
+                        <a key={prerequisite} href={`/algorithms/${prerequisite}`} className="mr-1">#{prerequisite}</a>
+                    ))}
+                </p>
+            )}
+            {algorithm.description_html && (
+                <div dangerouslySetInnerHTML={{ __html: algorithm.description_html }} />
+            )}
             <p>Created by: {algorithm.user_id}</p>
             <h4>Algorithm Code:</h4>
             <pre>{algorithm.code}</pre>
+            <h4>Run</h4>
+            <div className="form-group">
+                <textarea
+                    className="form-control"
+                    placeholder="Input (stdin)"
+                    value={stdin}
+                    onChange={(e) => setStdin(e.target.value)}
+                />
+            </div>
+            <button className="btn btn-secondary" onClick={handleRun} disabled={running}>
+                {running ? 'Running...' : 'Run'}
+            </button>
+            {runError && <div className="alert alert-danger mt-3">{runError}</div>}
+            {runResult && (
+                <div className="mt-3">
+                    <p>
+                        Status: {runResult.status}, exit code {runResult.exit_code}
+                        {runResult.signal && ` (${runResult.signal})`}; {runResult.usage.wall_time_ms} ms,{' '}
+                        {runResult.usage.memory_kb} KB
+                    </p>
+                    {runResult.compile_output && <pre className="text-danger">{runResult.compile_output}</pre>}
+                    <h5>Output{runResult.stdout_truncated && ' (truncated)'}</h5>
+                    <pre>{runResult.stdout}</pre>
+                    {runResult.stderr && (
+                        <>
+                            <h5>Errors{runResult.stderr_truncated && ' (truncated)'}</h5>
+                            <pre className="text-danger">{runResult.stderr}</pre>
+                        </>
+                    )}
+                </div>
+            )}
         </div>
     );
 };
@@ -0,0 +1,104 @@
+import React, { useEffect, useState } from 'react';
+import { useParams } from 'react-router-dom';
+import { fetchBenchmark } from '../services/benchmarkService';
+import { Benchmark } from '../types/Benchmark';
+
+const BenchmarkPage: React.FC = () => {
+    const { id } = useParams<{ id: string }>();
+    const [benchmark, setBenchmark] = useState<Benchmark | null>(null);
+    const [message, setMessage] = useState('');
+    const token = localStorage.getItem('token');
+
+    useEffect(() => {
+        let timer: ReturnType<typeof setTimeout>;
+        const load = async () => {
+            try {
+                const data = await fetchBenchmark(token, id!);
+                setBenchmark(data);
+                // Benchmarks run in the background, so keep polling until they finish
+                if (data.status === 'pending' || data.status === 'running') {
+                    timer = setTimeout(load, 3000);
+                }
+            } catch (error) {
+                setMessage('Error fetching benchmark');
+            }
+        };
+        load();
+        return () => clearTimeout(timer);
+    }, [id, token]);
+
+    if (message) {
+        return <div className="container alert alert-danger mt-4">{message}</div>;
+    }
+    if (!benchmark) {
+        return <div className="container mt-4">Loading...</div>;
+    }
+
+    return (
+        <div className="container mt-4">
+            <h2>Benchmark #{benchmark.id}</h2>
+            <p>
+                Generator {benchmark.generator}, {benchmark.repetitions} repetitions per size, status {benchmark.status}
+                {benchmark.error && ` (${benchmark.error})`}
+            </p>
+            <table className="table table-sm">
+                <thead>
+                    <tr>
+                        <th>Algorithm</th>
+                        <th>Declared time</th>
+                        <th>Measured time</th>
+                        <th>Declared space</th>
+                        <th>Measured space</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {benchmark.algorithms.map((algorithm) => (
+                        <tr key={algorithm.algorithm_id}>
+                            <td>
+                                {algorithm.title} ({algorithm.programming_language})
+                                {algorithm.error && <div className="text-danger">{algorithm.error}</div>}
+                            </td>
+                            <td>{algorithm.time_complexity || '—'}</td>
+                            <td className={algorithm.time_matches === false ? 'text-danger' : ''}>
+                                {algorithm.fitted_time_complexity
+                                    ? `${algorithm.fitted_time_complexity} (n^${algorithm.time_exponent ?? 0})`
+                                    : algorithm.status}
+                            </td>
+                            <td>{algorithm.space_complexity || '—'}</td>
+                            <td className={algorithm.space_matches === false ? 'text-danger' : ''}>
+                                {algorithm.fitted_space_complexity || algorithm.status}
+                            </td>
+                        </tr>
+                    ))}
+                </tbody>
+            </table>
+            <h4>Wall time, ms</h4>
+            <table className="table table-sm">
+                <thead>
+                    <tr>
+                        <th>Size</th>
+                        {benchmark.algorithms.map((algorithm) => (
+                            <th key={algorithm.algorithm_id}>{algorithm.title}</th>
+                        ))}
+                    </tr>
+                </thead>
+                <tbody>
+                    {(benchmark.table || []).map((row) => (
+                        <tr key={row.size}>
+                            <td>{row.size}</td>
+                            {row.results.map((result, i) => (
+                                <td key={i}>
+                                    {!result ? '' : result.status === 'ok'
+                                        ? `${result.wall_time_ms.toFixed(1)} (${Math.round(result.memory_kb / 1024)} MB)`
+                                        : result.status}
+                                </td>
+                            ))}
+                        </tr>
+                    ))}
+                </tbody>
+            </table>
+        </div>
+    );
+};
+
+export default BenchmarkPage;
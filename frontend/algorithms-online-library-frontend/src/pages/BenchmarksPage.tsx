@@ -0,0 +1,89 @@
+import React, { useEffect, useState } from 'react';
+import { Link, useNavigate } from 'react-router-dom';
+import { createBenchmark, fetchBenchmarks } from '../services/benchmarkService';
+import { Benchmark, benchmarkGenerators } from '../types/Benchmark';
+
+const parseNumbers = (value: string) =>
+    value.split(',').map((part) => part.trim()).filter((part) => part !== '').map(Number);
+
+const BenchmarksPage: React.FC = () => {
+    const [benchmarks, setBenchmarks] = useState<Benchmark[]>([]);
+    const [algorithmIDs, setAlgorithmIDs] = useState('');
+    const [generator, setGenerator] = useState('array');
+    const [sizes, setSizes] = useState('');
+    const [message, setMessage] = useState('');
+    const token = localStorage.getItem('token');
+    const navigate = useNavigate();
+
+    useEffect(() => {
+        fetchBenchmarks(token)
+            .then(setBenchmarks)
+            .catch(() => setMessage('Error fetching benchmarks'));
+    }, [token]);
+
+    const handleSubmit = async (event: React.FormEvent) => {
+        event.preventDefault();
+        try {
+            const benchmark = await createBenchmark(token, {
+                algorithm_ids: parseNumbers(algorithmIDs),
+                generator,
+                // The backend picks sizes from 1000 to 64000 when none are given
+                sizes: sizes ? parseNumbers(sizes) : undefined,
+            });
+            navigate(`/benchmarks/${benchmark.id}`);
+        } catch (error: any) {
+            setMessage(error.response?.data || 'Error creating benchmark');
+        }
+    };
+
+    return (
+        <div className="container mt-4">
+            <h2 className="mb-4">Benchmarks</h2>
+            <form onSubmit={handleSubmit} className="mb-4">
+                <div className="row">
+                    <div className="col-md-4 mb-3">
+                        <input
+                            type="text"
+                            className="form-control"
+                            placeholder="Algorithm IDs, e.g. 1, 2, 3"
+                            value={algorithmIDs}
+                            onChange={(e) => setAlgorithmIDs(e.target.value)}
+                        />
+                    </div>
+                    <div className="col-md-3 mb-3">
+                        <select className="form-select" value={generator} onChange={(e) => setGenerator(e.target.value)}>
+                            {benchmarkGenerators.map((name) => (
+                                <option key={name} value={name}>{name}</option>
+                            ))}
+                        </select>
+                    </div>
+                    <div className="col-md-3 mb-3">
+                        <input
+                            type="text"
+                            className="form-control"
+                            placeholder="Sizes, e.g. 1000, 2000, 4000"
+                            value={sizes}
+                            onChange={(e) => setSizes(e.target.value)}
+                        />
+                    </div>
+                    <div className="col-md-2 mb-3">
+                        <button type="submit" className="btn btn-primary">Run</button>
+                    </div>
+                </div>
+            </form>
+            {message && <div className="alert alert-danger">{message}</div>}
+            <ul className="list-group">
+                {benchmarks.map((benchmark) => (
+                    <li key={benchmark.id} className="list-group-item">
+                        <Link to={`/benchmarks/${benchmark.id}`}>
+                            #{benchmark.id} {benchmark.generator}: {benchmark.algorithms.map((algorithm) => algorithm.title).join(', ')}
+                        </Link>{' '}
+                        <span className="text-muted">{benchmark.status}</span>
+                    </li>
+                ))}
+            </ul>
+        </div>
+    );
+};
+
+export default BenchmarksPage;
@@ -3,9 +3,11 @@ import { Link } from 'react-router-dom';
 import { fetchAlgorithms } from '../services/algorithmService';
 import SearchForm from './SearchForm';
 import { Algorithm } from '../types/Algorithm';
+import VerificationBadge from '../components/VerificationBadge';
 
 const HomePage: React.FC = () => {
     const [algorithms, setAlgorithms] = useState<Algorithm[]>([]);
+    const [nextCursor, setNextCursor] = useState<string | undefined>();
     const token = localStorage.getItem('token');
 
     useEffect(() => {
@@ -13,8 +15,9 @@ const HomePage: React.FC = () => {
             try {
                 if (token) {
                     const data = await fetchAlgorithms(token);
-                    if (Array.isArray(data)) {
-                        setAlgorithms(data);
+                    if (Array.isArray(data.items)) {
+                        setAlgorithms(data.items);
+                        setNextCursor(data.next_cursor);
                     } else {
                         console.error('Unexpected data format:', data);
                     }
@@ -29,19 +32,41 @@ const HomePage: React.FC = () => {
         loadAlgorithms();
     }, [token]);
 
+    const loadMore = async () => {
+        try {
+            const data = await fetchAlgorithms(token, nextCursor);
+            setAlgorithms((current) => [...current, ...data.items]);
+            setNextCursor(data.next_cursor);
+        } catch (error) {
+            console.error('Error fetching algorithms:', error);
+        }
+    };
+
     return (
         <div className="container">
             <h2 className="my-4">Algorithms</h2>
-            <SearchForm setAlgorithms={setAlgorithms} />
+            <SearchForm
+                setAlgorithms={(value) => {
+                    // Search results replace the list, so the home listing cursor no longer applies
+                    setNextCursor(undefined);
+                    setAlgorithms(value);
+                }}
+            />
             <ul className="list-group">
                 {algorithms.map((algorithm) => (
                     <li key={algorithm.id} className="list-group-item">
                         <Link to={`/algorithms/${algorithm.id}`}>
                             {algorithm.title} - {algorithm.user_id} - {algorithm.programming_language}
+                            {algorithm.verification_status && <VerificationBadge status={algorithm.verification_status} />}
                         </Link>
                     </li>
                 ))}
             </ul>
+            {nextCursor && (
+                <button className="btn btn-outline-primary my-3" onClick={loadMore}>
+                    Load more
+                </button>
+            )}
         </div>
     );
 };
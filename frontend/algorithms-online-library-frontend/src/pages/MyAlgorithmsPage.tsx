@@ -2,9 +2,11 @@ import React, { useEffect, useState } from 'react';
 import { Link } from 'react-router-dom';
 import { fetchAlgorithmsByUserID } from '../services/algorithmService';
 import { Algorithm } from '../types/Algorithm';
+import VerificationBadge from '../components/VerificationBadge';
 
 const MyAlgorithmsPage: React.FC = () => {
     const [algorithms, setAlgorithms] = useState<Algorithm[]>([]);
+    const [nextCursor, setNextCursor] = useState<string | undefined>();
     const token = localStorage.getItem('token');
     const userID = localStorage.getItem('userID');
 
@@ -13,8 +15,9 @@ const MyAlgorithmsPage: React.FC = () => {
             try {
                 if (token) {
                     const data = await fetchAlgorithmsByUserID(token, userID);
-                    if (Array.isArray(data)) {
-                        setAlgorithms(data);
+                    if (Array.isArray(data.items)) {
+                        setAlgorithms(data.items);
+                        setNextCursor(data.next_cursor);
                     } else {
                         console.error('Unexpected data format:', data);
                     }
@@ -29,6 +32,16 @@ const MyAlgorithmsPage: React.FC = () => {
         loadAlgorithms();
     }, [token]);
 
+    const loadMore = async () => {
+        try {
+            const data = await fetchAlgorithmsByUserID(token, userID, nextCursor);
+            setAlgorithms((current) => [...current, ...data.items]);
+            setNextCursor(data.next_cursor);
+        } catch (error) {
+            console.error('Error fetching algorithms:', error);
+        }
+    };
+
     return (
         <div className="container mt-5">
             <h2 className="text-center mb-4">My Algorithms</h2>
@@ -37,7 +50,10 @@ const MyAlgorithmsPage: React.FC = () => {
                     <div className="col-md-4" key={algorithm.id}>
                         <div className="card mb-4">
                             <div className="card-body">
-                                <h5 className="card-title">{algorithm.title}</h5>
+                                <h5 className="card-title">
+                                    {algorithm.title}
+                                    {algorithm.verification_status && <VerificationBadge status={algorithm.verification_status} />}
+                                </h5>
                                 <h6 className="card-subtitle mb-2 text-muted">User ID: {algorithm.user_id}</h6>
                                 <p className="card-text">Language: {algorithm.programming_language}</p>
                                 <Link to={`/algorithms/${algorithm.id}`} className="btn btn-primary">
@@ -48,6 +64,11 @@ const MyAlgorithmsPage: React.FC = () => {
                     </div>
                 ))}
             </div>
+            {nextCursor && (
+                <button className="btn btn-outline-primary my-3" onClick={loadMore}>
+                    Load more
+                </button>
+            )}
         </div>
     );
 };
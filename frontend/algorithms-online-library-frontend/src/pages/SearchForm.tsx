@@ -1,5 +1,7 @@
 import React, { useEffect, useState } from 'react';
 import api from '../services/api';
+import { fetchCategories } from '../services/algorithmService';
+import { Category, categoryOptions } from '../types/Category';
 import { Algorithm } from '../types/Algorithm';
 
 interface SearchFormProps {
@@ -8,11 +10,15 @@ interface SearchFormProps {
 
 const SearchForm: React.FC<SearchFormProps> = ({ setAlgorithms }) => {
     const [title, setTitle] = useState('');
-    const [topic, setTopic] = useState('');
+    const [categoryID, setCategoryID] = useState('');
+    const [categories, setCategories] = useState<Category[]>([]);
     const [algorithmID, setAlgorithmID] = useState('');
     const [userID, setUserID] = useState('');
     const [programmingLanguage, setProgrammingLanguage] = useState('');
     const [sortBy, setSortBy] = useState('');
+    const [difficulty, setDifficulty] = useState('');
+    const [timeComplexity, setTimeComplexity] = useState('');
+    const [verified, setVerified] = useState('');
     const [availableProgrammingLanguages, setAvailableProgrammingLanguages] = useState<string[]>([]);
     const [message, setMessage] = useState('');
     const token = localStorage.getItem('token');
@@ -33,20 +39,27 @@ const SearchForm: React.FC<SearchFormProps> = ({ setAlgorithms }) => {
         };
 
         fetchAvailableProgrammingLanguages();
+        fetchCategories(token)
+            .then(setCategories)
+            .catch(() => setMessage('Error fetching categories'));
     }, [token]);
 
     const handleSearch = async () => {
         try {
-            console.log("params: ", title, topic, programmingLanguage, userID, algorithmID, sortBy);
+            console.log("params: ", title, categoryID, programmingLanguage, userID, algorithmID, sortBy);
 
             const response = await api.get('/api/algorithms/search', {
                 params: {
                     title: title,
-                    topic: topic,
+                    category_id: Number(categoryID),
                     programming_language: programmingLanguage,
                     user_id: userID,
                     id: algorithmID,
                     sort_by: sortBy,
+                    difficulty: difficulty || undefined,
+                    time_complexity: timeComplexity || undefined,
+                    verified: verified || undefined,
+                    fields: 'id,title,topic,category_id,programming_language,user_id,created_at,verification_status',
                 },
                 headers: {
                     Authorization: `Bearer ${token}`
@@ -55,7 +68,7 @@ const SearchForm: React.FC<SearchFormProps> = ({ setAlgorithms }) => {
 
             console.log("response.data when fetching algorithms by filter:", response.data);
 
-            setAlgorithms(response.data);
+            setAlgorithms(response.data.items);
         } catch (error) {
             console.error('Error fetching algorithms', error);
         }
@@ -75,13 +88,18 @@ const SearchForm: React.FC<SearchFormProps> = ({ setAlgorithms }) => {
                     />
                 </div>
                 <div className="col-md-6 mb-3">
-                    <input
-                        type="text"
+                    <select
                         className="form-control"
-                        placeholder="Topic"
-                        value={topic}
-                        onChange={(e) => setTopic(e.target.value)}
-                    />
+                        value={categoryID}
+                        onChange={(e) => setCategoryID(e.target.value)}
+                    >
+                        <option value="">Any category</option>
+                        {categoryOptions(categories).map((option) => (
+                            <option key={option.id} value={option.id}>
+                                {option.label}
+                            </option>
+                        ))}
+                    </select>
                 </div>
                 <div className="col-md-6 mb-3">
                     <input
@@ -115,6 +133,34 @@ const SearchForm: React.FC<SearchFormProps> = ({ setAlgorithms }) => {
                         <option value="">Sort By</option>
                         <option value="newest">Newest</option>
                         <option value="most_popular">Most Popular</option>
+                        <option value="easiest">Easiest</option>
+                        <option value="hardest">Hardest</option>
+                        <option value="fastest">Fastest</option>
+                        <option value="least_memory">Least Memory</option>
+                    </select>
+                </div>
+                <div className="col-md-6 mb-3">
+                    <select className="form-select" value={difficulty} onChange={(e) => setDifficulty(e.target.value)}>
+                        <option value="">Any difficulty</option>
+                        <option value="easy">Easy</option>
+                        <option value="medium">Medium</option>
+                        <option value="hard">Hard</option>
+                    </select>
+                </div>
+                <div className="col-md-6 mb-3">
+                    <input
+                        type="text"
+                        className="form-control"
+                        placeholder="Time complexity, e.g. O(n log n)"
+                        value={timeComplexity}
+                        onChange={(e) => setTimeComplexity(e.target.value)}
+                    />
+                </div>
+                <div className="col-md-6 mb-3">
+                    <select className="form-select" value={verified} onChange={(e) => setVerified(e.target.value)}>
+                        <option value="">Any test status</option>
+                        <option value="true">Passes its tests</option>
+                        <option value="false">Fails its tests</option>
                     </select>
                 </div>
                 <div className="col-12">
@@ -1,9 +1,15 @@
 import api from './api';
+import { AlgorithmPage } from '../types/Algorithm';
+import { Category } from '../types/Category';
 
-export const fetchAlgorithms = async (token: any) => {
+// List views are paginated and do not need the code
+const listFields = 'id,title,topic,category_id,programming_language,user_id,created_at,verification_status';
+
+export const fetchAlgorithms = async (token: any, cursor?: string): Promise<AlgorithmPage> => {
     try {
         //console.log("token when fetching algorithms:", token);
         const response = await api.get('/api/algorithms', {
+            params: { cursor, fields: listFields },
             headers: {
                 Authorization: `Bearer ${token}`
             }
@@ -17,9 +23,10 @@ export const fetchAlgorithms = async (token: any) => {
     }
 };
 
-export const fetchAlgorithmsByUserID = async (token: any, userID: any) => {
+export const fetchAlgorithmsByUserID = async (token: any, userID: any, cursor?: string): Promise<AlgorithmPage> => {
     try {
         const response = await api.get(`/api/algorithms-by-user/${userID}`, {
+            params: { cursor, fields: listFields },
             headers: {
                 Authorization: `Bearer ${token}`
             }
@@ -30,6 +37,15 @@ export const fetchAlgorithmsByUserID = async (token: any, userID: any) => {
     }
 }
 
+export const fetchCategories = async (token: any): Promise<Category[]> => {
+    const response = await api.get('/api/categories', {
+        headers: {
+            Authorization: `Bearer ${token}`
+        }
+    });
+    return response.data;
+};
+
 export const addAlgorithm = async (algorithm: any) => {
     try {
         const response = await api.post('/api/algorithms', algorithm);
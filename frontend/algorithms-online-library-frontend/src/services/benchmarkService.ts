@@ -0,0 +1,29 @@
+import api from './api';
+import { Benchmark } from '../types/Benchmark';
+
+export const createBenchmark = async (token: any, request: { algorithm_ids: number[]; generator: string; sizes?: number[]; repetitions?: number }): Promise<Benchmark> => {
+    const response = await api.post('/api/benchmarks', request, {
+        headers: {
+            Authorization: `Bearer ${token}`
+        }
+    });
+    return response.data;
+};
+
+export const fetchBenchmarks = async (token: any): Promise<Benchmark[]> => {
+    const response = await api.get('/api/benchmarks', {
+        headers: {
+            Authorization: `Bearer ${token}`
+        }
+    });
+    return response.data;
+};
+
+export const fetchBenchmark = async (token: any, id: string): Promise<Benchmark> => {
+    const response = await api.get(`/api/benchmarks/${id}`, {
+        headers: {
+            Authorization: `Bearer ${token}`
+        }
+    });
+    return response.data;
+};
@@ -1,8 +1,57 @@
+// Suggestion of the backend code analyzer, see "Code analysis" in the README
+export interface Analysis {
+    analyzer: string;
+    lines: number;
+    loop_depth: number;
+    recursive: boolean;
+    cyclomatic_complexity: number;
+    data_structures: string[];
+    difficulty: number;
+    time_complexity: string;
+    space_complexity: string;
+}
+
 export interface Algorithm {
     id: number;
     code: string;
     programming_language: string;
     title: string;
     topic: string;
+    category_id: number | null;
+    tags?: string[];
+    description?: string;
+    // Rendered and sanitized by the backend, only present on single-algorithm responses
+    description_html?: string;
+    difficulty?: number;
+    time_complexity?: string;
+    space_complexity?: string;
+    prerequisites?: number[];
+    analysis?: Analysis;
+    // Result of the test cases on the latest revision, absent while unverified
+    verification_status?: 'passed' | 'failed';
     user_id: string;
-}
\ No newline at end of file
+}
+
+export interface AlgorithmPage {
+    items: Algorithm[];
+    next_cursor?: string;
+    total?: number;
+}
+
+export interface RunResult {
+    language: string;
+    status: 'ok' | 'compile_error' | 'runtime_error' | 'time_limit_exceeded' | 'memory_limit_exceeded';
+    exit_code: number;
+    signal?: string;
+    stdout: string;
+    stderr: string;
+    stdout_truncated?: boolean;
+    stderr_truncated?: boolean;
+    compile_output?: string;
+    usage: {
+        wall_time_ms: number;
+        cpu_time_ms: number;
+        memory_kb: number;
+        compile_time_ms?: number;
+    };
+}
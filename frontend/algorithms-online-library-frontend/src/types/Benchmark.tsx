@@ -0,0 +1,44 @@
+// Median of the repetitions of one input size, see "Benchmarks" in the README
+export interface BenchmarkMeasurement {
+    size: number;
+    status: string;
+    wall_time_ms: number;
+    cpu_time_ms: number;
+    memory_kb: number;
+}
+
+export interface BenchmarkAlgorithm {
+    algorithm_id: number;
+    title: string;
+    programming_language: string;
+    revision: number;
+    status: 'pending' | 'done' | 'failed';
+    error?: string;
+    time_complexity?: string;
+    space_complexity?: string;
+    fitted_time_complexity?: string;
+    time_exponent?: number;
+    fitted_space_complexity?: string;
+    space_exponent?: number;
+    time_matches?: boolean;
+    space_matches?: boolean;
+    measurements: BenchmarkMeasurement[];
+}
+
+export interface Benchmark {
+    id: number;
+    user_id: number;
+    generator: string;
+    sizes: number[];
+    repetitions: number;
+    status: 'pending' | 'running' | 'done' | 'failed';
+    error?: string;
+    created_at: string;
+    started_at?: string;
+    finished_at?: string;
+    algorithms: BenchmarkAlgorithm[];
+    // Only on single-benchmark responses: one row per size, results in the order of algorithms
+    table?: { size: number; results: (BenchmarkMeasurement | null)[] }[];
+}
+
+export const benchmarkGenerators = ['array', 'sorted_array', 'reversed_array', 'number', 'string', 'graph'];
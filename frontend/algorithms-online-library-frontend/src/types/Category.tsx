@@ -0,0 +1,14 @@
+export interface Category {
+    id: number;
+    name: string;
+    parent_id: number | null;
+    slug: string;
+    children?: Category[];
+}
+
+// Flattens the category tree into select options, indenting subcategories by depth
+export const categoryOptions = (tree: Category[], depth = 0): { id: number; label: string }[] =>
+    tree.flatMap((category) => [
+        { id: category.id, label: '\u00a0\u00a0'.repeat(depth) + category.name },
+        ...categoryOptions(category.children ?? [], depth + 1),
+    ]);